@@ -0,0 +1,260 @@
+// Command headless runs the simulation without a window and records telemetry, trajectories,
+// pictures and SVG snapshots.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"particle-physics-simulator/internal/analytics"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/scene"
+	"particle-physics-simulator/internal/telemetry"
+	"particle-physics-simulator/internal/trajectory"
+	"particle-physics-simulator/internal/world"
+	"strings"
+	"time"
+)
+
+type config struct {
+	steps         int
+	dt            float64
+	width, height int
+	pairForces    bool
+
+	scenePath string
+	initPath  string
+	initFrame int
+
+	telemetryPath string
+	format        string
+	every         int
+	probes        string
+
+	trajectoryPath string
+	stride         int
+
+	pngDir     string
+	gifPath    string
+	imageEvery int
+	imageScale float64
+	overlays   string
+
+	trails     int
+	trailFade  bool
+	trailSpeed bool
+
+	svgDir        string
+	svgAt         string
+	svgColor      string
+	svgVelocities bool
+}
+
+func main() {
+	var c config
+	flag.IntVar(&c.steps, "steps", 1200, "number of steps to simulate")
+	flag.Float64Var(&c.dt, "dt", 1.0/120.0, "time step in seconds")
+	flag.IntVar(&c.width, "width", 1800, "width of the walled area in pixels")
+	flag.IntVar(&c.height, "height", 950, "height of the walled area in pixels")
+	flag.BoolVar(&c.pairForces, "pair-forces", false, "apply the particles' mutual Coulomb and gravitational forces")
+	flag.StringVar(&c.scenePath, "scene", "", "load particles, fields and forces from this JSON scene file instead of the demo scene")
+	flag.StringVar(&c.initPath, "init", "", "load initial conditions from this trajectory file instead of the demo scene")
+	flag.IntVar(&c.initFrame, "init-frame", -1, "frame of the -init file to load; negative values count from the end")
+	flag.StringVar(&c.telemetryPath, "telemetry", "", "write telemetry to this file")
+	flag.StringVar(&c.format, "format", "csv", "telemetry format: csv or jsonl")
+	flag.IntVar(&c.every, "every", 10, "sample telemetry every this many steps")
+	flag.StringVar(&c.probes, "probes", "", "comma-separated telemetry probes (default all)")
+	flag.StringVar(&c.trajectoryPath, "trajectory", "", "write the trajectory to this .xyz, LAMMPS dump (.dump, .lammpstrj) or binary (.ptrj) file")
+	flag.IntVar(&c.stride, "stride", 10, "write a trajectory frame every this many steps")
+	flag.StringVar(&c.pngDir, "png", "", "write PNG frames to this directory")
+	flag.StringVar(&c.gifPath, "gif", "", "write an animated GIF to this file")
+	flag.IntVar(&c.imageEvery, "image-every", 10, "draw a PNG or GIF frame every this many steps")
+	flag.Float64Var(&c.imageScale, "image-scale", 1, "image pixels per simulation pixel")
+	flag.StringVar(&c.overlays, "overlays", "", "comma-separated overlays to draw in images: field, lines, potential")
+	flag.IntVar(&c.trails, "trails", 0, "draw trails of the last this many steps in images and snapshots")
+	flag.BoolVar(&c.trailFade, "trail-fade", true, "fade trails out with age")
+	flag.BoolVar(&c.trailSpeed, "trail-speed", false, "draw faster parts of trails wider")
+	flag.StringVar(&c.svgDir, "svg", "", "write SVG snapshots to this directory (default the working directory)")
+	flag.StringVar(&c.svgAt, "svg-at", "", "comma-separated simulation times in seconds to write SVG snapshots at")
+	flag.StringVar(&c.svgColor, "svg-color", "color", "colour snapshot particles by color, speed, charge, mass or energy")
+	flag.BoolVar(&c.svgVelocities, "svg-velocities", false, "draw velocity arrows in snapshots")
+	flag.Parse()
+
+	if err := run(c); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(c config) error {
+	w, name, err := initialWorld(c)
+	if err != nil {
+		return err
+	}
+	w.PairForces = c.pairForces
+	w.Ledger = analytics.NewLedger(1)
+
+	var rec *telemetry.Recorder
+	if c.telemetryPath != "" {
+		f, err := telemetry.ParseFormat(c.format)
+		if err != nil {
+			return err
+		}
+		var probes []telemetry.Probe
+		if c.probes != "" {
+			if probes, err = telemetry.LookupProbes(strings.Split(c.probes, ",")); err != nil {
+				return err
+			}
+		}
+
+		file, err := os.Create(c.telemetryPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		rec, err = telemetry.NewRecorder(file, telemetry.Options{
+			Format: f,
+			Every:  c.every,
+			Probes: probes,
+			Metadata: telemetry.Metadata{
+				Name:     name,
+				Started:  time.Now(),
+				TimeStep: c.dt,
+				Width:    w.Width,
+				Height:   w.Height,
+				Extra:    map[string]string{"particles": fmt.Sprint(len(w.Particles))},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		rec.Record(w)
+	}
+
+	var traj *trajectory.Recorder
+	if c.trajectoryPath != "" {
+		format, err := trajectory.FormatFromPath(c.trajectoryPath)
+		if err != nil {
+			return err
+		}
+		file, err := os.Create(c.trajectoryPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		writer, err := trajectory.NewWriter(format, file)
+		if err != nil {
+			return err
+		}
+		traj = trajectory.NewRecorder(writer, c.stride)
+		if err := traj.Record(w); err != nil {
+			return err
+		}
+	}
+
+	var trails *renderer.Trails
+	if c.trails > 0 {
+		trails = renderer.NewTrails(c.trails)
+		trails.All = true
+		trails.Fade = c.trailFade
+		trails.WidthBySpeed = c.trailSpeed
+		trails.Record(w.Particles)
+	}
+
+	pictures, err := newPictureWriter(c, w, trails)
+	if err != nil {
+		return err
+	}
+	if err := pictures.observe(w); err != nil {
+		return err
+	}
+	snapshots, err := newSnapshotWriter(c, trails)
+	if err != nil {
+		return err
+	}
+	if err := snapshots.observe(w); err != nil {
+		return err
+	}
+
+	for i := 0; i < c.steps; i++ {
+		w.Step(c.dt)
+		if trails != nil {
+			trails.Record(w.Particles)
+		}
+		if rec != nil {
+			rec.Observe(w)
+		}
+		if traj != nil {
+			if err := traj.Observe(w); err != nil {
+				return err
+			}
+		}
+		if err := pictures.observe(w); err != nil {
+			return err
+		}
+		if err := snapshots.observe(w); err != nil {
+			return err
+		}
+	}
+
+	if err := pictures.close(); err != nil {
+		return err
+	}
+	if err := snapshots.close(); err != nil {
+		return err
+	}
+
+	if traj != nil {
+		if err := traj.Writer.Close(); err != nil {
+			return err
+		}
+	}
+	if rec != nil {
+		return rec.Close()
+	}
+	return nil
+}
+
+// initialWorld builds the world from the -init file, or the -scene file or demo scene without
+// one, and returns it with a name for the run. The fields of a -scene file apply either way.
+func initialWorld(c config) (*world.World, string, error) {
+	var s scene.Scene
+	name := "demo"
+	if c.scenePath != "" {
+		var err error
+		if s, err = scene.Load(c.scenePath); err != nil {
+			return nil, "", err
+		}
+		name = c.scenePath
+	}
+	if c.initPath == "" {
+		if c.scenePath == "" {
+			s.Particles = scene.Demo()
+		}
+		w := world.New(s.Particles, c.width, c.height)
+		w.Fields = s.Fields
+		return w, name, nil
+	}
+
+	frames, err := trajectory.ReadFile(c.initPath)
+	if err != nil {
+		return nil, "", err
+	}
+	i := c.initFrame
+	if i < 0 {
+		i += len(frames)
+	}
+	if i < 0 || i >= len(frames) {
+		return nil, "", fmt.Errorf("%s has %d frames, no frame %d", c.initPath, len(frames), c.initFrame)
+	}
+
+	w := frames[i].World()
+	w.Fields = s.Fields
+	// Files from other tools may not carry the box size
+	if w.Width <= 0 || w.Height <= 0 {
+		w.Width, w.Height = c.width, c.height
+	}
+	return w, c.initPath, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"particle-physics-simulator/internal/raster"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/world"
+	"strings"
+)
+
+// pictureWriter draws the world every few steps into PNG frames and an animated GIF.
+type pictureWriter struct {
+	every    int
+	image    *raster.Renderer
+	overlays renderer.Overlays
+	trails   *renderer.Trails
+	png      *raster.PNGSequence
+	gif      *raster.GIFAnimation
+	path     string
+}
+
+func newPictureWriter(c config, w *world.World, trails *renderer.Trails) (*pictureWriter, error) {
+	pw := &pictureWriter{every: max(c.imageEvery, 1), path: c.gifPath, trails: trails}
+	if c.pngDir == "" && c.gifPath == "" {
+		return pw, nil
+	}
+
+	var err error
+	if pw.overlays, err = parseOverlays(c.overlays); err != nil {
+		return nil, err
+	}
+	pw.image = raster.NewRenderer(w.Width, w.Height, c.imageScale)
+	pw.image.OnFrame = pw.writeFrame
+
+	if c.pngDir != "" {
+		if pw.png, err = raster.NewPNGSequence(c.pngDir, ""); err != nil {
+			return nil, err
+		}
+	}
+	if c.gifPath != "" {
+		// Play back in real time where the GIF frame delay allows it
+		delay := int(math.Round(100 * c.dt * float64(pw.every)))
+		pw.gif = raster.NewGIFAnimation(max(delay, 2))
+	}
+	return pw, nil
+}
+
+func (pw *pictureWriter) observe(w *world.World) error {
+	if pw.image == nil || w.Steps%pw.every != 0 {
+		return nil
+	}
+	pw.image.BeginFrame(renderer.Background)
+	renderer.DrawScene(pw.image, renderer.Scene{
+		Particles: w.Particles,
+		Width:     float64(w.Width),
+		Height:    float64(w.Height),
+		Overlays:  pw.overlays,
+		External: func(x, y float64) (float64, float64) {
+			return w.Fields.ElectricFieldAt(x, y, w.Time)
+		},
+		Walls:  true,
+		Trails: pw.trails,
+	})
+	pw.image.EndFrame()
+	return pw.image.Err()
+}
+
+func (pw *pictureWriter) writeFrame(img *image.RGBA) error {
+	if pw.png != nil {
+		if err := pw.png.WriteFrame(img); err != nil {
+			return err
+		}
+	}
+	if pw.gif != nil {
+		pw.gif.AddFrame(img)
+	}
+	return nil
+}
+
+func (pw *pictureWriter) close() error {
+	if pw.gif == nil {
+		return nil
+	}
+	return pw.gif.WriteFile(pw.path)
+}
+
+func parseOverlays(s string) (renderer.Overlays, error) {
+	var overlays renderer.Overlays
+	if s == "" {
+		return overlays, nil
+	}
+	for _, name := range strings.Split(s, ",") {
+		switch strings.TrimSpace(name) {
+		case "field":
+			overlays.ElectricField = true
+		case "lines":
+			overlays.FieldLines = true
+		case "potential":
+			overlays.Potential = true
+		default:
+			return overlays, fmt.Errorf("unknown overlay %q", name)
+		}
+	}
+	return overlays, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/svg"
+	"particle-physics-simulator/internal/world"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// snapshotWriter saves an SVG snapshot when the simulation reaches each requested time.
+type snapshotWriter struct {
+	dir   string
+	times []float64 // Sorted times still to save
+	dt    float64
+	opts  svg.Options
+}
+
+func newSnapshotWriter(c config, trails *renderer.Trails) (*snapshotWriter, error) {
+	sw := &snapshotWriter{dir: c.svgDir, dt: c.dt, opts: svg.DefaultOptions()}
+	if c.svgAt == "" {
+		return sw, nil
+	}
+
+	var err error
+	if sw.times, err = parseTimes(c.svgAt); err != nil {
+		return nil, err
+	}
+	if sw.opts.ColorBy, err = svg.ParseColorBy(c.svgColor); err != nil {
+		return nil, err
+	}
+	overlays, err := parseOverlays(c.overlays)
+	if err != nil {
+		return nil, err
+	}
+	sw.opts.FieldLines = overlays.FieldLines
+	sw.opts.Velocities = c.svgVelocities
+	sw.opts.Trails = trails
+
+	if sw.dir == "" {
+		sw.dir = "."
+	}
+	if err := os.MkdirAll(sw.dir, 0o755); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+// observe saves a snapshot for every requested time the world has reached, to the
+// nearest step.
+func (sw *snapshotWriter) observe(w *world.World) error {
+	for len(sw.times) > 0 && w.Time >= sw.times[0]-sw.dt/2 {
+		sw.times = sw.times[1:]
+		path := filepath.Join(sw.dir, fmt.Sprintf("snapshot_%06d.svg", w.Steps))
+		if err := svg.SaveSnapshot(path, w, sw.opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close reports requested times the run ended before.
+func (sw *snapshotWriter) close() error {
+	if len(sw.times) > 0 {
+		return fmt.Errorf("the run ended before the snapshot times %v", sw.times)
+	}
+	return nil
+}
+
+// parseTimes parses a comma-separated list of times in seconds.
+func parseTimes(s string) ([]float64, error) {
+	var times []float64
+	for _, field := range strings.Split(s, ",") {
+		t, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || t < 0 {
+			return nil, fmt.Errorf("invalid snapshot time %q", field)
+		}
+		times = append(times, t)
+	}
+	slices.Sort(times)
+	return times, nil
+}
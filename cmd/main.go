@@ -1,75 +1,71 @@
 package main
 
 import (
-	"math"
-	"particle-physics-simulator/internal/particle"
+	"flag"
+	"fmt"
+	"os"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/renderer/raylib"
+	"particle-physics-simulator/internal/rewind"
+	"particle-physics-simulator/internal/scene"
 	"particle-physics-simulator/internal/simulation"
+	"particle-physics-simulator/internal/trajectory"
 )
 
 func main() {
-	color1 := particle.Color{R: 1, G: 0, B: 0, A: 1} 
-	color2 := particle.Color{R: 0, G: 1, B: 0, A: 1}
-	color3 := particle.Color{R: 0, G: 0, B: 1, A: 1}
-	color4 := particle.Color{R: 1, G: 1, B: 0, A: 1}
-	color5 := particle.Color{R: 1, G: 0, B: 1, A: 1}
+	play := flag.String("play", "", "play back a recorded trajectory (.xyz, .dump, .lammpstrj or .ptrj) instead of simulating")
+	resizeMode := flag.String("resize", "world", "when the window is resized, move the walls with it (world) or zoom to fit the world (scale)")
+	scenePath := flag.String("scene", "", "load particles, fields and forces from this JSON scene file instead of the demo scene")
+	rewindMB := flag.Int("rewind-mb", rewind.DefaultBudget>>20, "memory in MB kept for rewinding the simulation with R, 0 to turn rewinding off")
+	flag.Parse()
 
-	particles := []*particle.Particle{}
-	for i := 0; i < 100; i++ { 
-		x := float64(i % 500)         
-		y := float64((i * 100) % 500) 
-		velocityX := math.Sin(float64(i)*0.1) * 100
-		velocityY := math.Cos(float64(i)*0.1) * 100
-		charge := float64(math.Cos(float64(i)*0.1) * 0.100) 
+	resize, err := simulation.ParseResize(*resizeMode)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-		var color particle.Color
-		switch i % 5 {
-		case 0:
-			color = color1
-		case 1:
-			color = color2
-		case 2:
-			color = color3
-		case 3:
-			color = color4
-		default:
-			color = color5
+	var s scene.Scene
+	if *scenePath != "" && *play == "" {
+		if s, err = scene.Load(*scenePath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+	}
 
-		particle := particle.NewCoulombParticle(x, y, velocityX, velocityY, 0.0, 0.0, 5.0, 5, color, charge, true)
-		particles = append(particles, particle)
+	var src trajectory.Source
+	if *play != "" {
+		if src, err = trajectory.Open(*play); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer src.Close()
 	}
 
-	magnetic_particles := []*particle.Particle{}
-	for i := 0; i < 100; i++ { 
-		x := float64(i % 500)
-		y := float64((i * 100) % 500)
-		velocityX := math.Sin(float64(i)*0.1) * 100
-		velocityY := math.Cos(float64(i)*0.1) * 100
-		charge := float64(math.Cos(float64(i)*0.1) * 0.100)
+	width, height := renderer.ScreenSize()
+	window := raylib.New(width, height, renderer.WindowTitle)
+	defer window.Close()
 
-		var color particle.Color
-		switch i % 5 {
-		case 0:
-			color = color1
-		case 1:
-			color = color2
-		case 2:
-			color = color3
-		case 3:
-			color = color4
-		default:
-			color = color5
+	if src != nil {
+		if err := simulation.RunPlayback(window, src); err != nil {
+			window.Close()
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
-
-		particle := particle.NewCoulombParticle(x, y, velocityX, velocityY, 0.0, 0.0, 5.0, 5, color, charge, true)
-		magnetic_particles = append(magnetic_particles, particle)
+		return
 	}
 
-	particles = append(particles, magnetic_particles...)
-
-	obstacle := particle.NewParticle(300, 300, 0.0, 0.0, 0.0, 0.0, 100.0, 50, particle.Color{R: 0.5, G: 0.5, B: 0.5, A: 1}, false) // Obstacle at a fixed location
-	particles = append(particles, obstacle)
+	if *scenePath == "" {
+		s.Particles = scene.Demo()
+	}
 
     // run simulation
-	simulation.RunSimulation(particles)
+	controls := simulation.NewControls()
+	controls.Resize = resize
+	if *rewindMB > 0 {
+		controls.Rewind = rewind.New(*rewindMB << 20)
+	} else {
+		controls.Rewind = nil
+	}
+	simulation.RunWithControls(window, simulation.NewWorld(window, s.Particles, s.Fields), controls)
 }
@@ -0,0 +1,177 @@
+// Package analytics computes conserved quantities and diagnostics for a set of particles.
+package analytics
+
+import (
+	"math"
+	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/force"
+	"particle-physics-simulator/internal/particle"
+)
+
+// EnergyBreakdown splits the energy of a system into its kinetic and potential parts, in joules
+// of simulation units (pixels and seconds).
+type EnergyBreakdown struct {
+	Kinetic        float64
+	GravityUniform float64 // m*g*h above the floor in the constant gravity field
+	GravityNBody   float64 // Mutual gravitational attraction between particles
+	Coulomb        float64 // Mutual electrostatic energy between charged particles
+}
+
+// Potential returns the sum of the potential energy terms.
+func (e EnergyBreakdown) Potential() float64 {
+	return e.GravityUniform + e.GravityNBody + e.Coulomb
+}
+
+// Total returns the total mechanical energy.
+func (e EnergyBreakdown) Total() float64 {
+	return e.Kinetic + e.Potential()
+}
+
+// MeasureEnergy computes every energy term for the particles, with uniform gravity g pulling
+// towards a floor at floorY (y grows downwards, as on screen).
+func MeasureEnergy(particles []*particle.Particle, g, floorY float64) EnergyBreakdown {
+	return EnergyBreakdown{
+		Kinetic:        KineticEnergy(particles),
+		GravityUniform: UniformGravityPotential(particles, g, floorY),
+		GravityNBody:   NBodyGravityPotential(particles),
+		Coulomb:        CoulombPotentialEnergy(particles),
+	}
+}
+
+// KineticEnergy returns the total kinetic energy, including out-of-plane motion.
+func KineticEnergy(particles []*particle.Particle) float64 {
+	total := 0.0
+	for _, p := range particles {
+		total += p.KineticEnergy()
+	}
+	return total
+}
+
+// UniformGravityPotential returns the potential energy of movable particles in constant gravity g.
+// A particle resting on the floor at floorY, i.e. with its centre at floorY - Radius, has zero energy.
+// Immovable particles are skipped since gravity never acts on them.
+func UniformGravityPotential(particles []*particle.Particle, g, floorY float64) float64 {
+	total := 0.0
+	for _, p := range particles {
+		if !p.Movable {
+			continue
+		}
+		height := floorY - p.Radius - p.Y
+		total += p.Mass * g * height
+	}
+	return total
+}
+
+// NBodyGravityPotential returns the mutual gravitational energy -G*m1*m2/r summed over all pairs.
+func NBodyGravityPotential(particles []*particle.Particle) float64 {
+	total := 0.0
+	for i := 0; i < len(particles); i++ {
+		for j := i + 1; j < len(particles); j++ {
+			p1, p2 := particles[i], particles[j]
+			dist := math.Hypot(p2.X-p1.X, p2.Y-p1.Y)
+			if dist < 1e-5 {
+				continue
+			}
+			total -= constants.GravitationalConstant * p1.Mass * p2.Mass / dist
+		}
+	}
+	return total
+}
+
+// CoulombPotentialEnergy returns the electrostatic energy k*q1*q2/r summed over all pairs.
+// As in electrostatics.CalculateElectrostaticForce, distances below the sum of the radii are clamped.
+func CoulombPotentialEnergy(particles []*particle.Particle) float64 {
+	total := 0.0
+	for i := 0; i < len(particles); i++ {
+		p1 := particles[i]
+		if p1.Charge == 0 {
+			continue
+		}
+		for j := i + 1; j < len(particles); j++ {
+			p2 := particles[j]
+			if p2.Charge == 0 {
+				continue
+			}
+			dist := math.Max(math.Hypot(p2.X-p1.X, p2.Y-p1.Y), p1.Radius+p2.Radius)
+			if dist == 0 {
+				continue
+			}
+			total += constants.CoulombsConstant * p1.Charge * p2.Charge / dist
+		}
+	}
+	return total
+}
+
+// MechanicalEnergy returns the kinetic plus uniform gravity energy. It is cheap enough to
+// measure around every stage of a step to attribute the energy each stage removes.
+func MechanicalEnergy(particles []*particle.Particle, g, floorY float64) float64 {
+	return KineticEnergy(particles) + UniformGravityPotential(particles, g, floorY)
+}
+
+// Velocity is a saved particle velocity.
+type Velocity struct {
+	X, Y, Z float64
+}
+
+// SaveVelocities records the current velocity of every particle.
+func SaveVelocities(particles []*particle.Particle) []Velocity {
+	velocities := make([]Velocity, len(particles))
+	for i, p := range particles {
+		velocities[i] = Velocity{X: p.Vx, Y: p.Vy, Z: p.Vz}
+	}
+	return velocities
+}
+
+// ExternalFieldWork returns the work done on the particles by the external fields during a velocity
+// update of length dt, given their velocities before the update. The magnetic part is reported
+// separately: it is exactly zero in reality, so any value left is error from the integrator.
+// The electric and custom forces are evaluated at the old velocity, as the update applies them,
+// against the mean of the old and new velocity; the magnetic part is the rest of the change in
+// kinetic energy, so the two parts add up to it.
+func ExternalFieldWork(particles []*particle.Particle, before []Velocity, fields force.ExternalFields, t, dt float64) (external, magnetic float64) {
+	for i, p := range particles {
+		if !p.Movable || i >= len(before) {
+			continue
+		}
+
+		// Restore the old velocity so the force matches the one used for the update
+		after := Velocity{X: p.Vx, Y: p.Vy, Z: p.Vz}
+		p.Vx, p.Vy, p.Vz = before[i].X, before[i].Y, before[i].Z
+		fx, fy, fz := force.ExternalForce(p, fields, t)
+		bx, by, bz := fields.MagneticFieldAt(p.X, p.Y, t)
+		mx, my, mz := force.MagneticForce3D(p, bx, by, bz)
+		p.Vx, p.Vy, p.Vz = after.X, after.Y, after.Z
+
+		meanVx := (before[i].X + after.X) / 2
+		meanVy := (before[i].Y + after.Y) / 2
+		meanVz := (before[i].Z + after.Z) / 2
+		work := ((fx-mx)*meanVx + (fy-my)*meanVy + (fz-mz)*meanVz) * dt
+		// Δ(½mv²) = m (v_new - v_old)·(v_new + v_old)/2
+		change := p.Mass * ((after.X-before[i].X)*meanVx + (after.Y-before[i].Y)*meanVy + (after.Z-before[i].Z)*meanVz)
+		external += work
+		magnetic += change - work
+	}
+	return external, magnetic
+}
+
+// KineticTemperature returns the temperature of the movable particles in energy units (k_B*T),
+// from their kinetic energy relative to the centre of mass shared among the two in-plane
+// degrees of freedom of each particle.
+func KineticTemperature(particles []*particle.Particle) float64 {
+	s := SummarizeMomentum(particles)
+	if s.Count == 0 {
+		return 0
+	}
+
+	thermal := 0.0
+	for _, p := range particles {
+		if !p.Movable {
+			continue
+		}
+		dvx := p.Vx - s.CenterVx
+		dvy := p.Vy - s.CenterVy
+		thermal += p.Mass * (dvx*dvx + dvy*dvy)
+	}
+	// Equipartition: ½ k_B T per degree of freedom, so k_B T = Σ m v² / (2N)
+	return thermal / float64(2*s.Count)
+}
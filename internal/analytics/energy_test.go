@@ -0,0 +1,114 @@
+package analytics
+
+import (
+	"math"
+	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/force"
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+func TestMeasureEnergy(t *testing.T) {
+	particles := []*particle.Particle{
+		{X: 0, Y: 90, Vx: 3, Vy: 4, Mass: 2, Radius: 10, Charge: 1e-6, Movable: true},
+		{X: 30, Y: 50, Vz: 2, Mass: 1, Radius: 10, Charge: -2e-6, Movable: true},
+		{X: 0, Y: 50, Mass: 100, Radius: 10}, // Immovable obstacle
+	}
+
+	energy := MeasureEnergy(particles, 10, 100)
+
+	// 1/2*2*25 + 1/2*1*4
+	if math.Abs(energy.Kinetic-27) > epsilon {
+		t.Errorf("Kinetic = %v, want 27", energy.Kinetic)
+	}
+	// Particle 0 rests on the floor; particle 1 is 40 above it; the obstacle is skipped
+	if math.Abs(energy.GravityUniform-400) > epsilon {
+		t.Errorf("GravityUniform = %v, want 400", energy.GravityUniform)
+	}
+
+	// Pairs: (0,1) at distance 50, (0,2) at 40 and (1,2) at 30
+	expectedNBody := -constants.GravitationalConstant * (2*1/50.0 + 2*100/40.0 + 1*100/30.0)
+	if math.Abs(energy.GravityNBody-expectedNBody) > epsilon {
+		t.Errorf("GravityNBody = %v, want %v", energy.GravityNBody, expectedNBody)
+	}
+
+	// Only the two charged particles interact
+	expectedCoulomb := constants.CoulombsConstant * 1e-6 * -2e-6 / 50
+	if math.Abs(energy.Coulomb-expectedCoulomb) > epsilon {
+		t.Errorf("Coulomb = %v, want %v", energy.Coulomb, expectedCoulomb)
+	}
+
+	if math.Abs(energy.Total()-(energy.Kinetic+energy.Potential())) > epsilon {
+		t.Errorf("Total() = %v, want kinetic plus potential", energy.Total())
+	}
+}
+
+func TestCoulombPotentialEnergySoftening(t *testing.T) {
+	// Overlapping particles use the sum of their radii as the distance
+	particles := []*particle.Particle{
+		{X: 0, Y: 0, Radius: 5, Charge: 1e-6},
+		{X: 2, Y: 0, Radius: 5, Charge: 1e-6},
+	}
+	expected := constants.CoulombsConstant * 1e-12 / 10
+	if got := CoulombPotentialEnergy(particles); math.Abs(got-expected) > epsilon {
+		t.Errorf("CoulombPotentialEnergy() = %v, want %v", got, expected)
+	}
+}
+
+func TestExternalFieldWork(t *testing.T) {
+	fields := force.ExternalFields{
+		Electric: []force.ElectricFieldSource{force.UniformElectricField{Ex: 2}},
+		Magnetic: []force.MagneticFieldSource{force.MagneticField{Strength: 3, Direction: 1}},
+	}
+	particles := []*particle.Particle{{Vx: 1, Vy: 2, Mass: 2, Charge: 0.5, Movable: true}}
+	dt := 0.01
+
+	before := SaveVelocities(particles)
+	energyBefore := KineticEnergy(particles)
+	fx, fy, _ := force.ExternalForce(particles[0], fields, 0)
+	particles[0].Vx += fx / particles[0].Mass * dt
+	particles[0].Vy += fy / particles[0].Mass * dt
+
+	external, magnetic := ExternalFieldWork(particles, before, fields, 0, dt)
+
+	// Together the two parts explain the whole change in kinetic energy
+	if change := KineticEnergy(particles) - energyBefore; math.Abs(external+magnetic-change) > epsilon {
+		t.Errorf("external + magnetic work = %v, want the kinetic energy change %v", external+magnetic, change)
+	}
+	// The electric field pushes along +x while the particle moves along +x
+	if external <= 0 {
+		t.Errorf("external work = %v, want positive", external)
+	}
+	// Explicit Euler makes the magnetic field do a little spurious work, of order (dt)²
+	if magnetic < 0 || magnetic > 1e-3 {
+		t.Errorf("magnetic work = %v, want a small positive integration error", magnetic)
+	}
+	if p := particles[0]; p.Vx != before[0].X+fx/2*dt {
+		t.Error("ExternalFieldWork must leave the particle velocity unchanged")
+	}
+}
+
+func TestKineticTemperature(t *testing.T) {
+	// Two particles moving apart at equal speed: all their energy is thermal
+	particles := []*particle.Particle{
+		{Vx: 3, Mass: 2, Movable: true},
+		{Vx: -3, Mass: 2, Movable: true},
+	}
+	if got := KineticTemperature(particles); math.Abs(got-9) > epsilon {
+		t.Errorf("KineticTemperature() = %v, want 9", got)
+	}
+
+	// Moving the whole group does not heat it
+	for _, p := range particles {
+		p.Vy += 100
+	}
+	if got := KineticTemperature(particles); math.Abs(got-9) > epsilon {
+		t.Errorf("KineticTemperature() after a uniform boost = %v, want 9", got)
+	}
+
+	if got := KineticTemperature(nil); got != 0 {
+		t.Errorf("KineticTemperature(nil) = %v, want 0", got)
+	}
+}
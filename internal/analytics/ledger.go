@@ -0,0 +1,95 @@
+package analytics
+
+// LedgerEntry is the energy account for one simulation step.
+type LedgerEntry struct {
+	Step   int
+	Time   float64
+	Energy EnergyBreakdown // Energy at the end of the step
+
+	ExternalWork float64 // Work done by external electric fields and custom forces
+	MagneticWork float64 // Work done by magnetic fields; non-zero values are integration error
+
+	Drag       float64 // Energy removed by air drag
+	Friction   float64 // Energy removed by ground friction
+	Collisions float64 // Energy removed by particle collisions
+	Boundaries float64 // Energy removed by damped bounces off the walls
+
+	// Drift is the total energy minus what it should be: the initial total plus all work done
+	// so far minus all energy dissipated so far. For an exact integrator it stays at zero.
+	Drift float64
+}
+
+// Dissipated returns the total energy removed during the step.
+func (e LedgerEntry) Dissipated() float64 {
+	return e.Drag + e.Friction + e.Collisions + e.Boundaries
+}
+
+// Ledger accumulates per-step energy entries and tracks the drift of the total energy.
+type Ledger struct {
+	Entries    []LedgerEntry
+	MaxEntries int // Oldest entries are discarded beyond this many; 0 keeps all
+
+	started         bool
+	initialTotal    float64
+	totalWork       float64
+	totalDissipated float64
+}
+
+// NewLedger creates a ledger keeping at most maxEntries entries (0 for no limit).
+func NewLedger(maxEntries int) *Ledger {
+	return &Ledger{MaxEntries: maxEntries}
+}
+
+// Start sets the reference energy that drift is measured against. If it is not called,
+// the first recorded entry's energy, before its work and dissipation, is used.
+func (l *Ledger) Start(initial EnergyBreakdown) {
+	l.start(initial.Total())
+}
+
+func (l *Ledger) start(initialTotal float64) {
+	l.started = true
+	l.initialTotal = initialTotal
+	l.totalWork = 0
+	l.totalDissipated = 0
+}
+
+// Started reports whether the reference energy has been set.
+func (l *Ledger) Started() bool {
+	return l.started
+}
+
+// Record completes entry with its drift, appends it and returns it.
+func (l *Ledger) Record(entry LedgerEntry) LedgerEntry {
+	work := entry.ExternalWork + entry.MagneticWork
+	if !l.started {
+		l.start(entry.Energy.Total() - work + entry.Dissipated())
+	}
+
+	l.totalWork += work
+	l.totalDissipated += entry.Dissipated()
+	entry.Drift = entry.Energy.Total() - (l.initialTotal + l.totalWork - l.totalDissipated)
+
+	l.Entries = append(l.Entries, entry)
+	if l.MaxEntries > 0 && len(l.Entries) > l.MaxEntries {
+		l.Entries = append(l.Entries[:0], l.Entries[len(l.Entries)-l.MaxEntries:]...)
+	}
+	return entry
+}
+
+// Last returns the most recent entry.
+func (l *Ledger) Last() (LedgerEntry, bool) {
+	if len(l.Entries) == 0 {
+		return LedgerEntry{}, false
+	}
+	return l.Entries[len(l.Entries)-1], true
+}
+
+// TotalWork returns all work done by external and magnetic fields since the ledger started.
+func (l *Ledger) TotalWork() float64 {
+	return l.totalWork
+}
+
+// TotalDissipated returns all energy removed by dissipative processes since the ledger started.
+func (l *Ledger) TotalDissipated() float64 {
+	return l.totalDissipated
+}
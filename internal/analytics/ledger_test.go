@@ -0,0 +1,60 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLedgerDrift(t *testing.T) {
+	ledger := NewLedger(0)
+	ledger.Start(EnergyBreakdown{Kinetic: 100})
+
+	// 5 J of work in, 10 J dissipated, ending at 95 J: exactly accounted for
+	entry := ledger.Record(LedgerEntry{
+		Step:         1,
+		Energy:       EnergyBreakdown{Kinetic: 60, GravityUniform: 35},
+		ExternalWork: 5,
+		Drag:         4,
+		Collisions:   6,
+	})
+	if math.Abs(entry.Drift) > epsilon {
+		t.Errorf("Drift = %v, want 0", entry.Drift)
+	}
+
+	// Nothing happens but the energy rises by 2 J: that is drift
+	entry = ledger.Record(LedgerEntry{Step: 2, Energy: EnergyBreakdown{Kinetic: 97}})
+	if math.Abs(entry.Drift-2) > epsilon {
+		t.Errorf("Drift = %v, want 2", entry.Drift)
+	}
+
+	if ledger.TotalWork() != 5 || ledger.TotalDissipated() != 10 {
+		t.Errorf("TotalWork() = %v, TotalDissipated() = %v, want 5 and 10", ledger.TotalWork(), ledger.TotalDissipated())
+	}
+	if last, ok := ledger.Last(); !ok || last.Step != 2 {
+		t.Errorf("Last() = %+v, %v, want step 2", last, ok)
+	}
+}
+
+func TestLedgerWithoutStart(t *testing.T) {
+	ledger := NewLedger(0)
+	if ledger.Started() {
+		t.Fatal("new ledger should not be started")
+	}
+
+	// The first entry defines the reference, so it has no drift by construction
+	entry := ledger.Record(LedgerEntry{Energy: EnergyBreakdown{Kinetic: 50}, Boundaries: 3})
+	if entry.Drift != 0 || !ledger.Started() {
+		t.Errorf("first entry Drift = %v, started = %v, want 0 and true", entry.Drift, ledger.Started())
+	}
+}
+
+func TestLedgerMaxEntries(t *testing.T) {
+	ledger := NewLedger(3)
+	for step := 1; step <= 5; step++ {
+		ledger.Record(LedgerEntry{Step: step})
+	}
+
+	if len(ledger.Entries) != 3 || ledger.Entries[0].Step != 3 || ledger.Entries[2].Step != 5 {
+		t.Errorf("Entries = %+v, want steps 3 to 5", ledger.Entries)
+	}
+}
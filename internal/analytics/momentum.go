@@ -0,0 +1,99 @@
+package analytics
+
+import (
+	"fmt"
+	"particle-physics-simulator/internal/particle"
+)
+
+// MomentumSummary holds the momentum totals of a group of particles.
+// Immovable particles behave like walls with infinite mass, so they are left out.
+type MomentumSummary struct {
+	Count     int
+	TotalMass float64
+
+	Px, Py, Pz float64 // Linear momentum
+
+	CenterX, CenterY   float64 // Centre of mass
+	CenterVx, CenterVy float64 // Velocity of the centre of mass
+
+	// AngularMomentum is the out-of-plane angular momentum Lz about the centre of mass.
+	AngularMomentum float64
+}
+
+// SummarizeMomentum computes the momentum totals of the movable particles.
+func SummarizeMomentum(particles []*particle.Particle) MomentumSummary {
+	var s MomentumSummary
+	for _, p := range particles {
+		if !p.Movable {
+			continue
+		}
+		s.Count++
+		s.TotalMass += p.Mass
+		s.Px += p.Mass * p.Vx
+		s.Py += p.Mass * p.Vy
+		s.Pz += p.Mass * p.Vz
+		s.CenterX += p.Mass * p.X
+		s.CenterY += p.Mass * p.Y
+	}
+
+	if s.TotalMass == 0 {
+		return s
+	}
+	s.CenterX /= s.TotalMass
+	s.CenterY /= s.TotalMass
+	s.CenterVx = s.Px / s.TotalMass
+	s.CenterVy = s.Py / s.TotalMass
+	s.AngularMomentum = AngularMomentum(particles, s.CenterX, s.CenterY)
+	return s
+}
+
+// LinearMomentum returns the total momentum of the movable particles.
+func LinearMomentum(particles []*particle.Particle) (float64, float64, float64) {
+	s := SummarizeMomentum(particles)
+	return s.Px, s.Py, s.Pz
+}
+
+// CenterOfMass returns the centre of mass of the movable particles.
+func CenterOfMass(particles []*particle.Particle) (float64, float64) {
+	s := SummarizeMomentum(particles)
+	return s.CenterX, s.CenterY
+}
+
+// AngularMomentum returns the out-of-plane angular momentum Lz = Σ m (x vy - y vx) of the
+// movable particles about the point (ox, oy). Since y grows downwards, positive values are
+// clockwise on screen.
+func AngularMomentum(particles []*particle.Particle, ox, oy float64) float64 {
+	total := 0.0
+	for _, p := range particles {
+		if !p.Movable {
+			continue
+		}
+		total += p.Mass * ((p.X-ox)*p.Vy - (p.Y-oy)*p.Vx)
+	}
+	return total
+}
+
+// SpeciesKey assigns a particle to a species for per-species breakdowns.
+type SpeciesKey func(p *particle.Particle) string
+
+// SpeciesByMassAndCharge groups particles that share a mass and charge.
+func SpeciesByMassAndCharge(p *particle.Particle) string {
+	return fmt.Sprintf("m=%g q=%g", p.Mass, p.Charge)
+}
+
+// SummarizeMomentumBySpecies computes separate momentum totals for every species.
+func SummarizeMomentumBySpecies(particles []*particle.Particle, key SpeciesKey) map[string]MomentumSummary {
+	groups := map[string][]*particle.Particle{}
+	for _, p := range particles {
+		if p.Movable {
+			k := key(p)
+			groups[k] = append(groups[k], p)
+		}
+	}
+
+	summaries := make(map[string]MomentumSummary, len(groups))
+	for k, group := range groups {
+		summaries[k] = SummarizeMomentum(group)
+	}
+	return summaries
+}
@@ -0,0 +1,151 @@
+package analytics
+
+import (
+	"math"
+	"particle-physics-simulator/internal/collisions"
+	"particle-physics-simulator/internal/force"
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+func TestSummarizeMomentum(t *testing.T) {
+	particles := []*particle.Particle{
+		{X: 0, Y: 0, Vx: 1, Vy: 0, Vz: 2, Mass: 1, Movable: true},
+		{X: 4, Y: 0, Vx: 0, Vy: 3, Mass: 3, Movable: true},
+		{X: 100, Y: 100, Mass: 1000}, // Immovable obstacle is ignored
+	}
+
+	s := SummarizeMomentum(particles)
+	if s.Count != 2 || s.TotalMass != 4 {
+		t.Errorf("Count = %d, TotalMass = %v, want 2 and 4", s.Count, s.TotalMass)
+	}
+	if s.Px != 1 || s.Py != 9 || s.Pz != 2 {
+		t.Errorf("momentum = (%v, %v, %v), want (1, 9, 2)", s.Px, s.Py, s.Pz)
+	}
+	if s.CenterX != 3 || s.CenterY != 0 {
+		t.Errorf("centre of mass = (%v, %v), want (3, 0)", s.CenterX, s.CenterY)
+	}
+	if s.CenterVx != 0.25 || s.CenterVy != 2.25 {
+		t.Errorf("centre of mass velocity = (%v, %v), want (0.25, 2.25)", s.CenterVx, s.CenterVy)
+	}
+	// About the centre (3, 0): m*(x-3)*vy for the only particle off the x axis with vy
+	if s.AngularMomentum != 3*1*3 {
+		t.Errorf("AngularMomentum = %v, want 9", s.AngularMomentum)
+	}
+	if L := AngularMomentum(particles, 0, 0); L != 36 {
+		t.Errorf("AngularMomentum about origin = %v, want 36", L)
+	}
+}
+
+func TestSummarizeMomentumBySpecies(t *testing.T) {
+	particles := []*particle.Particle{
+		{Vx: 1, Mass: 1, Charge: 1, Movable: true},
+		{Vx: 2, Mass: 1, Charge: 1, Movable: true},
+		{Vy: 1, Mass: 2, Charge: -1, Movable: true},
+	}
+
+	bySpecies := SummarizeMomentumBySpecies(particles, SpeciesByMassAndCharge)
+	if len(bySpecies) != 2 {
+		t.Fatalf("got %d species, want 2", len(bySpecies))
+	}
+	if s := bySpecies["m=1 q=1"]; s.Count != 2 || s.Px != 3 {
+		t.Errorf("species m=1 q=1 = %+v, want 2 particles with Px 3", s)
+	}
+	if s := bySpecies["m=2 q=-1"]; s.Count != 1 || s.Py != 2 {
+		t.Errorf("species m=2 q=-1 = %+v, want 1 particle with Py 2", s)
+	}
+}
+
+// momentumTolerance allows for rounding error relative to the momentum scale of the test.
+func momentumTolerance(particles []*particle.Particle) float64 {
+	scale := 0.0
+	for _, p := range particles {
+		scale += p.Mass * math.Hypot(p.Vx, p.Vy)
+	}
+	return 1e-12 * math.Max(scale, 1)
+}
+
+func TestApplyForcesParallelConservesMomentum(t *testing.T) {
+	var particles []*particle.Particle
+	for i := 0; i < 64; i++ {
+		fi := float64(i)
+		particles = append(particles, &particle.Particle{
+			X:       40 + 9*math.Mod(fi*7, 11),
+			Y:       40 + 9*math.Mod(fi*5, 13),
+			Vx:      math.Sin(fi),
+			Vy:      math.Cos(fi),
+			Mass:    1 + math.Mod(fi, 4),
+			Radius:  2,
+			Charge:  1e-6 * math.Cos(fi*0.7),
+			Movable: true,
+		})
+	}
+
+	before := SummarizeMomentum(particles)
+	for i := 0; i < 10; i++ {
+		force.ApplyForcesParallel(particles)
+	}
+	after := SummarizeMomentum(particles)
+
+	tol := momentumTolerance(particles)
+	if math.Abs(after.Px-before.Px) > tol || math.Abs(after.Py-before.Py) > tol {
+		t.Errorf("momentum changed from (%v, %v) to (%v, %v)", before.Px, before.Py, after.Px, after.Py)
+	}
+}
+
+func TestApplyForcesParallelIsRepeatable(t *testing.T) {
+	// Force buffers are pooled; a second call must not see forces left over from the first
+	newPair := func() []*particle.Particle {
+		return []*particle.Particle{
+			{X: 0, Y: 0, Mass: 1, Radius: 1, Charge: 1e-6, Movable: true},
+			{X: 10, Y: 0, Mass: 1, Radius: 1, Charge: 1e-6, Movable: true},
+		}
+	}
+
+	first := newPair()
+	force.ApplyForcesParallel(first)
+	second := newPair()
+	force.ApplyForcesParallel(second)
+
+	if first[0].Vx != second[0].Vx || first[1].Vx != second[1].Vx {
+		t.Errorf("repeated calls gave velocities %v and %v", first[0].Vx, second[0].Vx)
+	}
+}
+
+func TestHandleCollisionConservesMomentum(t *testing.T) {
+	tests := []struct {
+		name   string
+		p1, p2 *particle.Particle
+	}{
+		{
+			name: "Head-on, equal masses",
+			p1:   &particle.Particle{X: 0, Y: 0, Vx: 10, Mass: 1, Radius: 5, Movable: true},
+			p2:   &particle.Particle{X: 9, Y: 0, Vx: -5, Mass: 1, Radius: 5, Movable: true},
+		},
+		{
+			name: "Oblique, different masses",
+			p1:   &particle.Particle{X: 0, Y: 0, Vx: 7, Vy: 2, Mass: 3, Radius: 5, Movable: true},
+			p2:   &particle.Particle{X: 6, Y: 5, Vx: -1, Vy: -4, Mass: 0.5, Radius: 5, Movable: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			particles := []*particle.Particle{tt.p1, tt.p2}
+			before := SummarizeMomentum(particles)
+			energyBefore := KineticEnergy(particles)
+
+			collisions.HandleCollision(tt.p1, tt.p2)
+			after := SummarizeMomentum(particles)
+
+			tol := momentumTolerance(particles)
+			if math.Abs(after.Px-before.Px) > tol || math.Abs(after.Py-before.Py) > tol {
+				t.Errorf("momentum changed from (%v, %v) to (%v, %v)", before.Px, before.Py, after.Px, after.Py)
+			}
+			// The collision is perfectly elastic
+			if energyAfter := KineticEnergy(particles); math.Abs(energyAfter-energyBefore) > 1e-9*energyBefore {
+				t.Errorf("kinetic energy changed from %v to %v", energyBefore, energyAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package colormap maps physical quantities to colors, shared by every renderer.
+package colormap
+
+import (
+	"image/color"
+	"math"
+)
+
+// FieldAlpha is the opacity of field overlay colors.
+const FieldAlpha = 200
+
+// Field maps a relative strength in [0, 1] from blue through yellow to red.
+func Field(strength float64) color.RGBA {
+	s := math.Max(0, math.Min(1, strength))
+	if s < 0.5 {
+		f := s * 2
+		return color.RGBA{R: uint8(60 + 195*f), G: uint8(120 + 135*f), B: uint8(255 * (1 - f)), A: FieldAlpha}
+	}
+	f := (s - 0.5) * 2
+	return color.RGBA{R: 255, G: uint8(255 * (1 - f)), B: 0, A: FieldAlpha}
+}
+
+// Potential maps a signed, normalised potential in [-1, 1] onto a diverging blue-black-red
+// colormap with the given opacity.
+func Potential(value float64, alpha uint8) color.RGBA {
+	v := math.Max(-1, math.Min(1, value))
+	// Square root spreads out the small values that cover most of the screen
+	intensity := uint8(255 * math.Sqrt(math.Abs(v)))
+	if v < 0 {
+		return color.RGBA{R: 0, G: intensity / 3, B: intensity, A: alpha}
+	}
+	return color.RGBA{R: intensity, G: intensity / 3, B: 0, A: alpha}
+}
+
+// viridis holds evenly spaced stops of the viridis colormap.
+var viridis = []color.RGBA{
+	{68, 1, 84, 255},
+	{71, 44, 122, 255},
+	{59, 81, 139, 255},
+	{44, 113, 142, 255},
+	{33, 144, 141, 255},
+	{39, 173, 129, 255},
+	{92, 200, 99, 255},
+	{170, 220, 50, 255},
+	{253, 231, 37, 255},
+}
+
+// Sequential maps a value in [0, 1] onto the viridis colormap, dark purple through green to
+// yellow. It stays readable in greyscale and for colour-blind readers, so it suits figures.
+func Sequential(value float64) color.RGBA {
+	v := math.Max(0, math.Min(1, value))
+	if math.IsNaN(value) {
+		v = 0
+	}
+	pos := v * float64(len(viridis)-1)
+	i := min(int(pos), len(viridis)-2)
+	f := pos - float64(i)
+	a, b := viridis[i], viridis[i+1]
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + f*(float64(y)-float64(x))))
+	}
+	return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: 255}
+}
+
+// Diverging maps a signed, normalised value in [-1, 1] from blue through light grey at zero
+// to red, the "cool to warm" colormap. Unlike Potential, zero stays visible on a dark
+// background, so it suits colouring particles by charge.
+func Diverging(value float64) color.RGBA {
+	v := math.Max(-1, math.Min(1, value))
+	if math.IsNaN(value) {
+		v = 0
+	}
+	mid := color.RGBA{221, 221, 221, 255}
+	end := color.RGBA{180, 4, 38, 255}
+	if v < 0 {
+		end = color.RGBA{59, 76, 192, 255}
+		v = -v
+	}
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + v*(float64(y)-float64(x))))
+	}
+	return color.RGBA{R: lerp(mid.R, end.R), G: lerp(mid.G, end.G), B: lerp(mid.B, end.B), A: 255}
+}
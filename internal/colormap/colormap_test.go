@@ -0,0 +1,80 @@
+package colormap
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestField(t *testing.T) {
+	tests := []struct {
+		strength float64
+		want     color.RGBA
+	}{
+		{0, color.RGBA{60, 120, 255, FieldAlpha}},
+		{-1, color.RGBA{60, 120, 255, FieldAlpha}},
+		{0.5, color.RGBA{255, 255, 0, FieldAlpha}},
+		{1, color.RGBA{255, 0, 0, FieldAlpha}},
+		{2, color.RGBA{255, 0, 0, FieldAlpha}},
+	}
+	for _, tt := range tests {
+		if got := Field(tt.strength); got != tt.want {
+			t.Errorf("Field(%v) = %v, want %v", tt.strength, got, tt.want)
+		}
+	}
+}
+
+func TestPotential(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  color.RGBA
+	}{
+		{0, color.RGBA{0, 0, 0, 100}},
+		{1, color.RGBA{255, 85, 0, 100}},
+		{-1, color.RGBA{0, 85, 255, 100}},
+		{-4, color.RGBA{0, 85, 255, 100}},
+		{0.25, color.RGBA{127, 42, 0, 100}},
+	}
+	for _, tt := range tests {
+		if got := Potential(tt.value, 100); got != tt.want {
+			t.Errorf("Potential(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSequential(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  color.RGBA
+	}{
+		{0, color.RGBA{68, 1, 84, 255}},
+		{-3, color.RGBA{68, 1, 84, 255}},
+		{0.5, color.RGBA{33, 144, 141, 255}},
+		{1, color.RGBA{253, 231, 37, 255}},
+		{1.5, color.RGBA{253, 231, 37, 255}},
+		// Halfway between the first two stops
+		{0.0625, color.RGBA{70, 23, 103, 255}},
+	}
+	for _, tt := range tests {
+		if got := Sequential(tt.value); got != tt.want {
+			t.Errorf("Sequential(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDiverging(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  color.RGBA
+	}{
+		{0, color.RGBA{221, 221, 221, 255}},
+		{1, color.RGBA{180, 4, 38, 255}},
+		{-1, color.RGBA{59, 76, 192, 255}},
+		{-5, color.RGBA{59, 76, 192, 255}},
+		{0.5, color.RGBA{201, 113, 130, 255}},
+	}
+	for _, tt := range tests {
+		if got := Diverging(tt.value); got != tt.want {
+			t.Errorf("Diverging(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package electrostatics
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"strconv"
+	"sync"
+)
+
+// Point is a position in simulation coordinates.
+type Point struct {
+	X, Y float64
+}
+
+// FieldLine is a polyline following the electric field from its seed charge.
+type FieldLine struct {
+	Points []Point
+	Source int // Index of the particle the line was seeded from
+	Sink   int // Index of the particle the line ended on, or -1 if it left the area or ran out of steps
+}
+
+// FieldLineOptions controls how field lines are seeded and integrated.
+type FieldLineOptions struct {
+	LinesPerCharge int     // Lines leaving the most strongly charged particle; others get proportionally fewer
+	MaxLines       int     // Upper bound on the total number of lines
+	StepSize       float64 // Integration step length in pixels
+	MaxSteps       int     // Maximum number of steps per line
+	MinX, MinY     float64 // Area outside of which lines stop
+	MaxX, MaxY     float64
+	External       ExternalField // Optional external field included in the integration
+}
+
+// DefaultFieldLineOptions returns options suited to tracing lines over a width x height area.
+func DefaultFieldLineOptions(width, height float64) FieldLineOptions {
+	return FieldLineOptions{
+		LinesPerCharge: 16,
+		MaxLines:       400,
+		StepSize:       4,
+		MaxSteps:       600,
+		MaxX:           width,
+		MaxY:           height,
+	}
+}
+
+// TraceFieldLines integrates field lines with RK4 from the charged particles. Lines start
+// on positive charges and follow E until they reach a negative charge or leave the area.
+// If there are no positive charges, lines are traced backwards from the negative ones.
+// The number of lines per particle is proportional to the magnitude of its charge.
+func TraceFieldLines(particles []*particle.Particle, opts FieldLineOptions) []FieldLine {
+	if opts.StepSize <= 0 || opts.MaxSteps <= 0 || opts.LinesPerCharge <= 0 {
+		return nil
+	}
+
+	// Seed from positive charges, or from negative ones against the field if there are none
+	direction := 1.0
+	maxCharge, hasPositive := 0.0, false
+	for _, p := range particles {
+		maxCharge = math.Max(maxCharge, math.Abs(p.Charge))
+		if p.Charge > 0 {
+			hasPositive = true
+		}
+	}
+	if maxCharge == 0 {
+		return nil
+	}
+	if !hasPositive {
+		direction = -1
+	}
+
+	type seed struct {
+		source int
+		x, y   float64
+	}
+	var seeds []seed
+	for i, p := range particles {
+		if p.Charge*direction <= 0 {
+			continue
+		}
+		n := int(math.Round(float64(opts.LinesPerCharge) * math.Abs(p.Charge) / maxCharge))
+		if n < 1 {
+			n = 1
+		}
+		startRadius := math.Max(p.Radius, opts.StepSize)
+		for k := 0; k < n; k++ {
+			angle := 2 * math.Pi * float64(k) / float64(n)
+			seeds = append(seeds, seed{
+				source: i,
+				x:      p.X + startRadius*math.Cos(angle),
+				y:      p.Y + startRadius*math.Sin(angle),
+			})
+		}
+	}
+	if opts.MaxLines > 0 && len(seeds) > opts.MaxLines {
+		// Thin the seeds evenly rather than dropping whole particles
+		thinned := make([]seed, opts.MaxLines)
+		for i := range thinned {
+			thinned[i] = seeds[i*len(seeds)/opts.MaxLines]
+		}
+		seeds = thinned
+	}
+
+	lines := make([]FieldLine, len(seeds))
+	var wg sync.WaitGroup
+	wg.Add(len(seeds))
+	for i, s := range seeds {
+		go func(i int, s seed) {
+			defer wg.Done()
+			lines[i] = traceLine(particles, opts, s.source, s.x, s.y, direction)
+		}(i, s)
+	}
+	wg.Wait()
+
+	return lines
+}
+
+// traceLine follows the unit field direction from (x, y) using fixed-length RK4 steps.
+func traceLine(particles []*particle.Particle, opts FieldLineOptions, source int, x, y, direction float64) FieldLine {
+	line := FieldLine{Points: []Point{{X: x, Y: y}}, Source: source, Sink: -1}
+	h := opts.StepSize
+
+	// dir returns the unit field direction at a point, scaled by the trace direction
+	dir := func(x, y float64) (float64, float64, bool) {
+		ex, ey := ElectricFieldAt(particles, opts.External, x, y)
+		mag := math.Hypot(ex, ey)
+		if mag == 0 || math.IsNaN(mag) || math.IsInf(mag, 0) {
+			return 0, 0, false
+		}
+		return direction * ex / mag, direction * ey / mag, true
+	}
+
+	for step := 0; step < opts.MaxSteps; step++ {
+		k1x, k1y, ok1 := dir(x, y)
+		k2x, k2y, ok2 := dir(x+h/2*k1x, y+h/2*k1y)
+		k3x, k3y, ok3 := dir(x+h/2*k2x, y+h/2*k2y)
+		k4x, k4y, ok4 := dir(x+h*k3x, y+h*k3y)
+		if !(ok1 && ok2 && ok3 && ok4) {
+			break // Null point: the line has nowhere to go
+		}
+
+		x += h / 6 * (k1x + 2*k2x + 2*k3x + k4x)
+		y += h / 6 * (k1y + 2*k2y + 2*k3y + k4y)
+		line.Points = append(line.Points, Point{X: x, Y: y})
+
+		if x < opts.MinX || x > opts.MaxX || y < opts.MinY || y > opts.MaxY {
+			break
+		}
+		if sink := sinkAt(particles, x, y, direction); sink >= 0 {
+			line.Sink = sink
+			line.Points[len(line.Points)-1] = Point{X: particles[sink].X, Y: particles[sink].Y}
+			break
+		}
+	}
+
+	return line
+}
+
+// sinkAt returns the index of an oppositely charged particle containing (x, y), or -1.
+func sinkAt(particles []*particle.Particle, x, y, direction float64) int {
+	for i, p := range particles {
+		if p.Charge*direction >= 0 {
+			continue
+		}
+		dx, dy := x-p.X, y-p.Y
+		if dx*dx+dy*dy <= p.Radius*p.Radius {
+			return i
+		}
+	}
+	return -1
+}
+
+// WriteFieldLinesCSV exports field lines as vector data with one row per point:
+// line index, point index, x, y, source particle and sink particle (-1 if none).
+func WriteFieldLinesCSV(w io.Writer, lines []FieldLine) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"line", "point", "x", "y", "source", "sink"}); err != nil {
+		return err
+	}
+
+	for i, line := range lines {
+		for j, pt := range line.Points {
+			record := []string{
+				strconv.Itoa(i),
+				strconv.Itoa(j),
+				strconv.FormatFloat(pt.X, 'g', -1, 64),
+				strconv.FormatFloat(pt.Y, 'g', -1, 64),
+				strconv.Itoa(line.Source),
+				strconv.Itoa(line.Sink),
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,100 @@
+package electrostatics
+
+import (
+	"bytes"
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+func TestTraceFieldLinesDipole(t *testing.T) {
+	particles := []*particle.Particle{
+		{X: 100, Y: 100, Charge: 1e-6, Radius: 5},
+		{X: 200, Y: 100, Charge: -1e-6, Radius: 5},
+	}
+	opts := DefaultFieldLineOptions(300, 200)
+	opts.LinesPerCharge = 8
+
+	lines := TraceFieldLines(particles, opts)
+	if len(lines) != 8 {
+		t.Fatalf("TraceFieldLines() returned %d lines, want 8", len(lines))
+	}
+
+	for i, line := range lines {
+		if line.Source != 0 {
+			t.Errorf("line %d source = %d, want 0", i, line.Source)
+		}
+	}
+
+	// The line leaving straight towards the negative charge must end on it
+	direct := lines[0]
+	if direct.Sink != 1 {
+		t.Fatalf("direct line sink = %d, want 1", direct.Sink)
+	}
+	end := direct.Points[len(direct.Points)-1]
+	if end.X != 200 || end.Y != 100 {
+		t.Errorf("direct line ends at (%v, %v), want (200, 100)", end.X, end.Y)
+	}
+	for _, pt := range direct.Points {
+		if math.Abs(pt.Y-100) > 1e-6 {
+			t.Errorf("direct line left the axis at (%v, %v)", pt.X, pt.Y)
+			break
+		}
+	}
+
+	// Every point stays within one step of the area
+	for _, line := range lines {
+		for _, pt := range line.Points {
+			if pt.X < -opts.StepSize || pt.X > 300+opts.StepSize || pt.Y < -opts.StepSize || pt.Y > 200+opts.StepSize {
+				t.Fatalf("point (%v, %v) is outside the area", pt.X, pt.Y)
+			}
+		}
+	}
+}
+
+func TestTraceFieldLinesSeeding(t *testing.T) {
+	particles := []*particle.Particle{
+		{X: 100, Y: 100, Charge: -2e-6, Radius: 5},
+		{X: 300, Y: 100, Charge: -1e-6, Radius: 5},
+		{X: 200, Y: 200, Radius: 5},
+	}
+	opts := DefaultFieldLineOptions(400, 300)
+	opts.LinesPerCharge = 6
+	opts.MaxSteps = 5
+
+	// With only negative charges, lines are seeded on them in proportion to |q|
+	lines := TraceFieldLines(particles, opts)
+	counts := map[int]int{}
+	for _, line := range lines {
+		counts[line.Source]++
+	}
+	if counts[0] != 6 || counts[1] != 3 || counts[2] != 0 {
+		t.Errorf("lines per source = %v, want map[0:6 1:3]", counts)
+	}
+
+	opts.MaxLines = 4
+	if lines := TraceFieldLines(particles, opts); len(lines) != 4 {
+		t.Errorf("TraceFieldLines() with MaxLines 4 returned %d lines", len(lines))
+	}
+
+	if lines := TraceFieldLines(particles[2:], opts); lines != nil {
+		t.Errorf("TraceFieldLines() without charges returned %d lines, want none", len(lines))
+	}
+}
+
+func TestWriteFieldLinesCSV(t *testing.T) {
+	lines := []FieldLine{
+		{Points: []Point{{X: 0, Y: 0}, {X: 1.5, Y: 2}}, Source: 0, Sink: 3},
+		{Points: []Point{{X: 4, Y: 5}}, Source: 1, Sink: -1},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteFieldLinesCSV(&buf, lines); err != nil {
+		t.Fatalf("WriteFieldLinesCSV returned error: %v", err)
+	}
+
+	expected := "line,point,x,y,source,sink\n0,0,0,0,0,3\n0,1,1.5,2,0,3\n1,0,4,5,1,-1\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("WriteFieldLinesCSV() =\n%s\nwant\n%s", got, expected)
+	}
+}
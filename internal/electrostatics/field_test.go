@@ -0,0 +1,112 @@
+package electrostatics
+
+import (
+	"math"
+	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+func TestElectricFieldAt(t *testing.T) {
+	tests := []struct {
+		name       string
+		particles  []*particle.Particle
+		external   ExternalField
+		x, y       float64
+		expectedEx float64
+		expectedEy float64
+	}{
+		{
+			name:       "Positive charge points away",
+			particles:  []*particle.Particle{{X: 0, Y: 0, Charge: 1e-6, Radius: 0.1}},
+			x:          2,
+			y:          0,
+			expectedEx: constants.CoulombsConstant * 1e-6 / 4,
+		},
+		{
+			name:       "Negative charge points towards",
+			particles:  []*particle.Particle{{X: 0, Y: 0, Charge: -1e-6, Radius: 0.1}},
+			x:          0,
+			y:          2,
+			expectedEy: -constants.CoulombsConstant * 1e-6 / 4,
+		},
+		{
+			name: "Dipole midpoint",
+			particles: []*particle.Particle{
+				{X: -1, Y: 0, Charge: 1e-6, Radius: 0.1},
+				{X: 1, Y: 0, Charge: -1e-6, Radius: 0.1},
+			},
+			expectedEx: 2 * constants.CoulombsConstant * 1e-6,
+		},
+		{
+			name:       "Inside a particle the distance is clamped to its radius",
+			particles:  []*particle.Particle{{X: 0, Y: 0, Charge: 1e-6, Radius: 2}},
+			x:          1,
+			expectedEx: constants.CoulombsConstant * 1e-6 / 4,
+		},
+		{
+			name:      "Uncharged particles and the centre of a charge contribute nothing",
+			particles: []*particle.Particle{{X: 5, Y: 5, Radius: 1}, {X: 0, Y: 0, Charge: 1, Radius: 1}},
+		},
+		{
+			name:       "External field is added",
+			particles:  []*particle.Particle{},
+			external:   func(x, y float64) (float64, float64) { return x, 3 },
+			x:          2,
+			expectedEx: 2,
+			expectedEy: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex, ey := ElectricFieldAt(tt.particles, tt.external, tt.x, tt.y)
+			if math.Abs(ex-tt.expectedEx) > epsilon || math.Abs(ey-tt.expectedEy) > epsilon {
+				t.Errorf("ElectricFieldAt() = (%v, %v), want (%v, %v)", ex, ey, tt.expectedEx, tt.expectedEy)
+			}
+		})
+	}
+}
+
+// TestFieldMatchesForce checks that q*E from a particle equals the force it exerts on a test charge.
+func TestFieldMatchesForce(t *testing.T) {
+	source := &particle.Particle{X: 0, Y: 0, Charge: 2e-6}
+	probe := &particle.Particle{X: 3, Y: 4, Charge: 1e-6}
+
+	ex, ey := ElectricFieldAt([]*particle.Particle{source}, nil, probe.X, probe.Y)
+	fx, fy := CalculateElectrostaticForceVector(probe, source)
+
+	// CalculateElectrostaticForceVector points from p1 towards p2 for like charges, i.e. the opposite sign
+	if math.Abs(probe.Charge*ex+fx) > epsilon || math.Abs(probe.Charge*ey+fy) > epsilon {
+		t.Errorf("q*E = (%v, %v), want (%v, %v)", probe.Charge*ex, probe.Charge*ey, -fx, -fy)
+	}
+}
+
+func TestSampleElectricField(t *testing.T) {
+	particles := []*particle.Particle{{X: 50, Y: 50, Charge: 1e-6, Radius: 1}}
+	grid := SampleElectricField(particles, nil, 0, 0, 100, 100, 4, 2)
+
+	if grid.Cols != 4 || grid.Rows != 2 || len(grid.Ex) != 8 {
+		t.Fatalf("grid size = %dx%d with %d samples, want 4x2 with 8", grid.Cols, grid.Rows, len(grid.Ex))
+	}
+
+	x, y := grid.Point(3, 1)
+	if x != 87.5 || y != 75 {
+		t.Errorf("Point(3, 1) = (%v, %v), want (87.5, 75)", x, y)
+	}
+
+	for row := 0; row < grid.Rows; row++ {
+		for col := 0; col < grid.Cols; col++ {
+			x, y := grid.Point(col, row)
+			wantX, wantY := ElectricFieldAt(particles, nil, x, y)
+			gotX, gotY := grid.At(col, row)
+			if gotX != wantX || gotY != wantY {
+				t.Errorf("At(%d, %d) = (%v, %v), want (%v, %v)", col, row, gotX, gotY, wantX, wantY)
+			}
+		}
+	}
+
+	if grid.MaxMagnitude() <= 0 {
+		t.Error("Expected a positive maximum field magnitude")
+	}
+}
@@ -0,0 +1,64 @@
+package electrostatics
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Grid describes a regular grid of cells over a rectangular area. Samples are taken at cell centres.
+type Grid struct {
+	MinX, MinY float64 // Corner of the sampled area
+	CellWidth  float64
+	CellHeight float64
+	Cols, Rows int
+}
+
+// NewGrid divides [minX, maxX] x [minY, maxY] into cols x rows cells, using at least one of each.
+func NewGrid(minX, minY, maxX, maxY float64, cols, rows int) Grid {
+	if cols < 1 {
+		cols = 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return Grid{
+		MinX: minX, MinY: minY,
+		CellWidth:  (maxX - minX) / float64(cols),
+		CellHeight: (maxY - minY) / float64(rows),
+		Cols:       cols,
+		Rows:       rows,
+	}
+}
+
+// Point returns the position of the sample at (col, row).
+func (g Grid) Point(col, row int) (float64, float64) {
+	return g.MinX + (float64(col)+0.5)*g.CellWidth, g.MinY + (float64(row)+0.5)*g.CellHeight
+}
+
+// parallelRows calls fn for every row, spreading the rows over the available CPUs.
+func parallelRows(rows int, fn func(row int)) {
+	numGoroutines := runtime.NumCPU()
+	if numGoroutines > rows {
+		numGoroutines = rows
+	}
+	chunkSize := (rows + numGoroutines - 1) / numGoroutines
+	var wg sync.WaitGroup
+
+	for g := 0; g < numGoroutines; g++ {
+		start := g * chunkSize
+		end := start + chunkSize
+		if end > rows {
+			end = rows
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for row := start; row < end; row++ {
+				fn(row)
+			}
+		}(start, end)
+	}
+
+	wg.Wait()
+}
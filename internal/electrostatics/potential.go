@@ -0,0 +1,159 @@
+package electrostatics
+
+import (
+	"math"
+	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/particle"
+	"sort"
+)
+
+// PotentialAt calculates the electric potential at (x, y) from all charged particles,
+// taking zero potential at infinity. Closer than a particle's radius the distance is
+// clamped to the radius, the same softening used for the field and the pairwise forces.
+// External fields are not included since they need not have a scalar potential.
+func PotentialAt(particles []*particle.Particle, x, y float64) float64 {
+	potential := 0.0
+	for _, p := range particles {
+		if p.Charge == 0 {
+			continue
+		}
+
+		dx := x - p.X
+		dy := y - p.Y
+		dist := math.Max(math.Sqrt(dx*dx+dy*dy), p.Radius)
+		if dist == 0 {
+			continue
+		}
+		potential += constants.CoulombsConstant * p.Charge / dist
+	}
+	return potential
+}
+
+// PotentialGrid holds the electric potential sampled on a grid.
+type PotentialGrid struct {
+	Grid
+	Values []float64 // Row-major potentials in volts, len = Cols*Rows
+}
+
+// At returns the potential sampled at (col, row).
+func (g *PotentialGrid) At(col, row int) float64 {
+	return g.Values[row*g.Cols+col]
+}
+
+// SamplePotential samples the potential on a cols x rows grid covering [minX, maxX] x [minY, maxY].
+func SamplePotential(particles []*particle.Particle, minX, minY, maxX, maxY float64, cols, rows int) *PotentialGrid {
+	geometry := NewGrid(minX, minY, maxX, maxY, cols, rows)
+	grid := &PotentialGrid{
+		Grid:   geometry,
+		Values: make([]float64, geometry.Cols*geometry.Rows),
+	}
+
+	parallelRows(grid.Rows, func(row int) {
+		for col := 0; col < grid.Cols; col++ {
+			x, y := grid.Point(col, row)
+			grid.Values[row*grid.Cols+col] = PotentialAt(particles, x, y)
+		}
+	})
+
+	return grid
+}
+
+// Range returns the potentials at the lower and upper percentiles (0-100) of the grid.
+// Point charges make the extremes very large, so percentiles give a more useful scale.
+func (g *PotentialGrid) Range(lowerPercentile, upperPercentile float64) (float64, float64) {
+	if len(g.Values) == 0 {
+		return 0, 0
+	}
+
+	sorted := append([]float64(nil), g.Values...)
+	sort.Float64s(sorted)
+	index := func(percentile float64) int {
+		i := int(math.Round(percentile / 100 * float64(len(sorted)-1)))
+		return int(math.Max(0, math.Min(float64(len(sorted)-1), float64(i))))
+	}
+	return sorted[index(lowerPercentile)], sorted[index(upperPercentile)]
+}
+
+// EquipotentialLevels returns n potentials evenly spaced between the 2nd and 98th percentiles.
+func (g *PotentialGrid) EquipotentialLevels(n int) []float64 {
+	if n <= 0 {
+		return nil
+	}
+
+	low, high := g.Range(2, 98)
+	if low == high {
+		return nil
+	}
+
+	levels := make([]float64, n)
+	for i := range levels {
+		levels[i] = low + (high-low)*(float64(i)+0.5)/float64(n)
+	}
+	return levels
+}
+
+// Segment is a straight piece of a contour line.
+type Segment struct {
+	A, B Point
+}
+
+// Contour returns the line segments where the potential equals level, found with marching squares
+// over the squares formed by neighbouring samples. Crossing points are linearly interpolated.
+func (g *PotentialGrid) Contour(level float64) []Segment {
+	var segments []Segment
+
+	for row := 0; row < g.Rows-1; row++ {
+		for col := 0; col < g.Cols-1; col++ {
+			// Corners in order: top-left, top-right, bottom-right, bottom-left
+			v := [4]float64{g.At(col, row), g.At(col+1, row), g.At(col+1, row+1), g.At(col, row+1)}
+			x0, y0 := g.Point(col, row)
+			x1, y1 := g.Point(col+1, row+1)
+			corners := [4]Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+
+			caseIndex := 0
+			for i := range v {
+				if v[i] > level {
+					caseIndex |= 1 << i
+				}
+			}
+			if caseIndex == 0 || caseIndex == 15 {
+				continue
+			}
+
+			// crossing returns where the contour crosses the edge from corner a to corner b
+			crossing := func(a, b int) Point {
+				t := (level - v[a]) / (v[b] - v[a])
+				return Point{
+					X: corners[a].X + t*(corners[b].X-corners[a].X),
+					Y: corners[a].Y + t*(corners[b].Y-corners[a].Y),
+				}
+			}
+			top, right, bottom, left := crossing(0, 1), crossing(1, 2), crossing(2, 3), crossing(3, 0)
+
+			switch caseIndex {
+			case 1, 14:
+				segments = append(segments, Segment{A: left, B: top})
+			case 2, 13:
+				segments = append(segments, Segment{A: top, B: right})
+			case 3, 12:
+				segments = append(segments, Segment{A: left, B: right})
+			case 4, 11:
+				segments = append(segments, Segment{A: right, B: bottom})
+			case 6, 9:
+				segments = append(segments, Segment{A: top, B: bottom})
+			case 7, 8:
+				segments = append(segments, Segment{A: left, B: bottom})
+			case 5, 10:
+				// Saddle: use the centre value to decide which diagonal corners are connected
+				centreAbove := (v[0]+v[1]+v[2]+v[3])/4 > level
+				if centreAbove == (caseIndex == 5) {
+					segments = append(segments, Segment{A: left, B: bottom}, Segment{A: top, B: right})
+				} else {
+					segments = append(segments, Segment{A: left, B: top}, Segment{A: right, B: bottom})
+				}
+			}
+		}
+	}
+
+	return segments
+}
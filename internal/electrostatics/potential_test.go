@@ -0,0 +1,134 @@
+package electrostatics
+
+import (
+	"math"
+	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+func TestPotentialAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		particles []*particle.Particle
+		x, y      float64
+		expected  float64
+	}{
+		{
+			name:      "Single charge",
+			particles: []*particle.Particle{{X: 0, Y: 0, Charge: 1e-6, Radius: 1}},
+			x:         3,
+			y:         4,
+			expected:  constants.CoulombsConstant * 1e-6 / 5,
+		},
+		{
+			name:      "Clamped inside the radius",
+			particles: []*particle.Particle{{X: 0, Y: 0, Charge: 1e-6, Radius: 2}},
+			x:         1,
+			expected:  constants.CoulombsConstant * 1e-6 / 2,
+		},
+		{
+			name: "Dipole midpoint is zero",
+			particles: []*particle.Particle{
+				{X: -1, Y: 0, Charge: 1e-6, Radius: 0.1},
+				{X: 1, Y: 0, Charge: -1e-6, Radius: 0.1},
+			},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PotentialAt(tt.particles, tt.x, tt.y)
+			if math.Abs(got-tt.expected) > epsilon {
+				t.Errorf("PotentialAt() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestPotentialGradient checks that E = -∇V outside the particles.
+func TestPotentialGradient(t *testing.T) {
+	particles := []*particle.Particle{
+		{X: 0, Y: 0, Charge: 1e-6, Radius: 1},
+		{X: 10, Y: 5, Charge: -2e-6, Radius: 1},
+	}
+	x, y, h := 4.0, 7.0, 1e-4
+
+	gradX := (PotentialAt(particles, x+h, y) - PotentialAt(particles, x-h, y)) / (2 * h)
+	gradY := (PotentialAt(particles, x, y+h) - PotentialAt(particles, x, y-h)) / (2 * h)
+	ex, ey := ElectricFieldAt(particles, nil, x, y)
+
+	if math.Abs(ex+gradX) > 1e-3*math.Abs(ex) || math.Abs(ey+gradY) > 1e-3*math.Abs(ey) {
+		t.Errorf("-∇V = (%v, %v), want E = (%v, %v)", -gradX, -gradY, ex, ey)
+	}
+}
+
+func TestContour(t *testing.T) {
+	// A linear potential V = x sampled at x = 0.5, 1.5, 2.5, 3.5 has a vertical contour at x = 2
+	grid := &PotentialGrid{Grid: NewGrid(0, 0, 4, 3, 4, 3)}
+	for row := 0; row < grid.Rows; row++ {
+		for col := 0; col < grid.Cols; col++ {
+			x, _ := grid.Point(col, row)
+			grid.Values = append(grid.Values, x)
+		}
+	}
+
+	segments := grid.Contour(2)
+	if len(segments) != 2 {
+		t.Fatalf("Contour() returned %d segments, want 2", len(segments))
+	}
+	for _, s := range segments {
+		if s.A.X != 2 || s.B.X != 2 {
+			t.Errorf("segment %+v does not lie on x = 2", s)
+		}
+	}
+
+	if segments := grid.Contour(10); len(segments) != 0 {
+		t.Errorf("Contour() outside the range returned %d segments", len(segments))
+	}
+}
+
+func TestContourSaddle(t *testing.T) {
+	// Opposite corners high: the high centre keeps them connected, so the contour cuts off the low corners
+	grid := &PotentialGrid{Grid: NewGrid(0, 0, 2, 2, 2, 2), Values: []float64{1, 0, 0.2, 1}}
+
+	segments := grid.Contour(0.5)
+	if len(segments) != 2 {
+		t.Fatalf("Contour() returned %d segments, want 2", len(segments))
+	}
+	for _, s := range segments {
+		// Each segment must cut off a corner: one end on a horizontal edge, one on a vertical edge
+		horizontal := s.A.Y == 0.5 || s.A.Y == 1.5 || s.B.Y == 0.5 || s.B.Y == 1.5
+		vertical := s.A.X == 0.5 || s.A.X == 1.5 || s.B.X == 0.5 || s.B.X == 1.5
+		if !horizontal || !vertical {
+			t.Errorf("segment %+v does not cut off a corner", s)
+		}
+	}
+}
+
+func TestEquipotentialLevels(t *testing.T) {
+	particles := []*particle.Particle{
+		{X: 25, Y: 25, Charge: 1e-6, Radius: 2},
+		{X: 75, Y: 25, Charge: -1e-6, Radius: 2},
+	}
+	grid := SamplePotential(particles, 0, 0, 100, 50, 20, 10)
+	if len(grid.Values) != 200 {
+		t.Fatalf("SamplePotential() returned %d values, want 200", len(grid.Values))
+	}
+
+	levels := grid.EquipotentialLevels(5)
+	if len(levels) != 5 {
+		t.Fatalf("EquipotentialLevels() returned %d levels, want 5", len(levels))
+	}
+	low, high := grid.Range(2, 98)
+	for i, level := range levels {
+		if level <= low || level >= high || (i > 0 && level <= levels[i-1]) {
+			t.Errorf("levels %v are not increasing within (%v, %v)", levels, low, high)
+			break
+		}
+	}
+	if segments := grid.Contour(levels[2]); len(segments) == 0 {
+		t.Error("Expected the middle equipotential to cross the grid")
+	}
+}
@@ -0,0 +1,176 @@
+// Package expr implements a small, safe arithmetic expression language used to
+// describe fields and forces without writing Go code.
+//
+// Expressions support numbers, + - * / % ^, parentheses, the math functions in
+// functions below, the constants pi and e, and named variables and parameters
+// chosen by the caller. An expression is compiled once and can then be
+// evaluated cheaply many times, e.g. once per particle per step.
+package expr
+
+import (
+	"fmt"
+	"math"
+)
+
+// Expr is a compiled expression.
+type Expr struct {
+	source string
+	vars   []string
+	root   node
+}
+
+// Error describes a problem found while compiling an expression.
+type Error struct {
+	Source string
+	Column int // 1-based column of the offending token
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("expr: column %d: %s in %q", e.Column, e.Msg, e.Source)
+}
+
+// Compile parses src. vars names the variables, in the order their values are
+// passed to Eval; params are named constants fixed at compile time.
+func Compile(src string, vars []string, params map[string]float64) (*Expr, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{src: src, tokens: tokens, vars: vars, params: params}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+
+	return &Expr{source: src, vars: append([]string(nil), vars...), root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the expression cannot be compiled.
+// It is intended for expressions that are fixed in the source code.
+func MustCompile(src string, vars []string, params map[string]float64) *Expr {
+	e, err := Compile(src, vars, params)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Eval evaluates the expression. values holds one value per variable, in the
+// order given to Compile; missing values are treated as 0.
+func (e *Expr) Eval(values ...float64) float64 {
+	if len(values) < len(e.vars) {
+		padded := make([]float64, len(e.vars))
+		copy(padded, values)
+		values = padded
+	}
+	return e.root.eval(values)
+}
+
+// IsConstant reports whether the expression does not depend on any variable.
+func (e *Expr) IsConstant() bool {
+	return e.root.constant
+}
+
+// String returns the source the expression was compiled from.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// node is a compiled sub-expression. Constant sub-expressions are folded at compile time.
+type node struct {
+	eval     func(values []float64) float64
+	constant bool
+}
+
+func constantNode(v float64) node {
+	return node{eval: func([]float64) float64 { return v }, constant: true}
+}
+
+func variableNode(index int) node {
+	return node{eval: func(values []float64) float64 { return values[index] }}
+}
+
+// fold evaluates n once if it only depends on constants.
+func fold(n node) node {
+	if n.constant {
+		return constantNode(n.eval(nil))
+	}
+	return n
+}
+
+func unaryNode(fn func(a float64) float64, a node) node {
+	ea := a.eval
+	return fold(node{
+		eval:     func(values []float64) float64 { return fn(ea(values)) },
+		constant: a.constant,
+	})
+}
+
+func binaryNode(fn func(a, b float64) float64, a, b node) node {
+	ea, eb := a.eval, b.eval
+	return fold(node{
+		eval:     func(values []float64) float64 { return fn(ea(values), eb(values)) },
+		constant: a.constant && b.constant,
+	})
+}
+
+// function is a built-in function callable from expressions.
+type function struct {
+	arity int
+	call1 func(a float64) float64
+	call2 func(a, b float64) float64
+}
+
+var functions = map[string]function{
+	"sin":   {arity: 1, call1: math.Sin},
+	"cos":   {arity: 1, call1: math.Cos},
+	"tan":   {arity: 1, call1: math.Tan},
+	"asin":  {arity: 1, call1: math.Asin},
+	"acos":  {arity: 1, call1: math.Acos},
+	"atan":  {arity: 1, call1: math.Atan},
+	"sinh":  {arity: 1, call1: math.Sinh},
+	"cosh":  {arity: 1, call1: math.Cosh},
+	"tanh":  {arity: 1, call1: math.Tanh},
+	"exp":   {arity: 1, call1: math.Exp},
+	"log":   {arity: 1, call1: math.Log},
+	"log10": {arity: 1, call1: math.Log10},
+	"sqrt":  {arity: 1, call1: math.Sqrt},
+	"abs":   {arity: 1, call1: math.Abs},
+	"floor": {arity: 1, call1: math.Floor},
+	"ceil":  {arity: 1, call1: math.Ceil},
+	"sign":  {arity: 1, call1: sign},
+	"atan2": {arity: 2, call2: math.Atan2},
+	"pow":   {arity: 2, call2: math.Pow},
+	"hypot": {arity: 2, call2: math.Hypot},
+	"min":   {arity: 2, call2: math.Min},
+	"max":   {arity: 2, call2: math.Max},
+	"step":  {arity: 2, call2: step},
+}
+
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+func sign(a float64) float64 {
+	switch {
+	case a > 0:
+		return 1
+	case a < 0:
+		return -1
+	}
+	return 0
+}
+
+// step(a, edge) is 1 when a >= edge and 0 otherwise, useful for switching fields on in a region.
+func step(a, edge float64) float64 {
+	if a >= edge {
+		return 1
+	}
+	return 0
+}
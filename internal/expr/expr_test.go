@@ -0,0 +1,105 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := []string{"x", "y", "t"}
+	params := map[string]float64{"B0": 2, "k": 0.5}
+
+	tests := []struct {
+		name     string
+		src      string
+		values   []float64
+		expected float64
+	}{
+		{name: "Number", src: "42", expected: 42},
+		{name: "Scientific notation", src: "1.5e-3 * 2", expected: 3e-3},
+		{name: "Precedence", src: "1 + 2 * 3", expected: 7},
+		{name: "Parentheses", src: "(1 + 2) * 3", expected: 9},
+		{name: "Left associative subtraction", src: "10 - 4 - 3", expected: 3},
+		{name: "Right associative power", src: "2 ^ 3 ^ 2", expected: 512},
+		{name: "Unary minus binds looser than power", src: "-2 ^ 2", expected: -4},
+		{name: "Modulo", src: "7 % 3", expected: 1},
+		{name: "Variables", src: "x * y + t", values: []float64{2, 3, 4}, expected: 10},
+		{name: "Parameters", src: "B0 * exp(-k * x)", values: []float64{2}, expected: 2 * math.Exp(-1)},
+		{name: "Constants", src: "cos(pi)", expected: -1},
+		{name: "Two argument function", src: "atan2(y, x)", values: []float64{1, 1}, expected: math.Pi / 4},
+		{name: "Step function", src: "step(x, 100)", values: []float64{150}, expected: 1},
+		{name: "Missing values are zero", src: "x + y + t", values: []float64{1}, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.src, vars, params)
+			if err != nil {
+				t.Fatalf("Compile(%q) returned error: %v", tt.src, err)
+			}
+			got := e.Eval(tt.values...)
+			if math.Abs(got-tt.expected) > 1e-12 {
+				t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src    string
+		column int
+		msg    string
+	}{
+		{src: "1 +", column: 4, msg: "unexpected end of expression"},
+		{src: "sin(x", column: 6, msg: "expected \")\""},
+		{src: "(1 + 2", column: 7, msg: "to close \"(\" at column 1"},
+		{src: "z * 2", column: 1, msg: "unknown name \"z\""},
+		{src: "foo(1)", column: 1, msg: "unknown function \"foo\""},
+		{src: "max(1)", column: 1, msg: "max takes 2 argument(s), got 1"},
+		{src: "sin + 1", column: 1, msg: "must be called with arguments"},
+		{src: "2 $ 3", column: 3, msg: "unexpected character '$'"},
+		{src: "1 2", column: 3, msg: "unexpected \"2\""},
+		{src: "1..2", column: 1, msg: "invalid number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Compile(tt.src, []string{"x"}, nil)
+			var exprErr *Error
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("Compile(%q) error = %v, want *Error", tt.src, err)
+			}
+			if exprErr.Column != tt.column {
+				t.Errorf("Compile(%q) column = %d, want %d", tt.src, exprErr.Column, tt.column)
+			}
+			if !strings.Contains(exprErr.Error(), tt.msg) {
+				t.Errorf("Compile(%q) error = %q, want it to contain %q", tt.src, exprErr.Error(), tt.msg)
+			}
+		})
+	}
+}
+
+func TestConstantFolding(t *testing.T) {
+	e := MustCompile("2 * pi * sqrt(4)", []string{"x"}, nil)
+	if !e.IsConstant() {
+		t.Error("Expected expression without variables to be constant")
+	}
+	if got := e.Eval(); math.Abs(got-4*math.Pi) > 1e-12 {
+		t.Errorf("Eval() = %v, want %v", got, 4*math.Pi)
+	}
+
+	if MustCompile("2 * x", []string{"x"}, nil).IsConstant() {
+		t.Error("Expected expression using x not to be constant")
+	}
+}
+
+func BenchmarkEval(b *testing.B) {
+	e := MustCompile("B0 * exp(-((x - 900)^2 + (y - 475)^2) / (2 * w^2)) * sin(2 * pi * f * t)",
+		[]string{"x", "y", "t"}, map[string]float64{"B0": 1, "w": 200, "f": 3})
+	for i := 0; i < b.N; i++ {
+		e.Eval(100, 200, 0.5)
+	}
+}
@@ -0,0 +1,297 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	value  float64
+	column int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// tokenize splits src into tokens, reporting the first invalid character.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		column := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// Exponent, e.g. 1.5e-3
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					for j < len(runes) && unicode.IsDigit(runes[j]) {
+						j++
+					}
+					i = j
+				}
+			}
+			text := string(runes[start:i])
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &Error{Source: src, Column: column, Msg: fmt.Sprintf("invalid number %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value, column: column})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), column: column})
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", column: column})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", column: column})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", column: column})
+			i++
+		case r == '+' || r == '-' || r == '*' || r == '/' || r == '%' || r == '^':
+			tokens = append(tokens, token{kind: tokenOperator, text: string(r), column: column})
+			i++
+		default:
+			return nil, &Error{Source: src, Column: column, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, column: len(runes) + 1})
+	return tokens, nil
+}
+
+// parser is a recursive descent parser producing compiled nodes directly.
+//
+//	expression = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/" | "%") unary }
+//	unary      = ("+" | "-") unary | power
+//	power      = primary [ "^" unary ]
+//	primary    = number | name | name "(" args ")" | "(" expression ")"
+type parser struct {
+	src    string
+	tokens []token
+	pos    int
+	vars   []string
+	params map[string]float64
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &Error{Source: p.src, Column: tok.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) isOperator(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseExpression() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return node{}, err
+	}
+
+	for p.isOperator("+", "-") {
+		op := p.next().text
+		right, err := p.parseTerm()
+		if err != nil {
+			return node{}, err
+		}
+		if op == "+" {
+			left = binaryNode(func(a, b float64) float64 { return a + b }, left, right)
+		} else {
+			left = binaryNode(func(a, b float64) float64 { return a - b }, left, right)
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return node{}, err
+	}
+
+	for p.isOperator("*", "/", "%") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return node{}, err
+		}
+		switch op {
+		case "*":
+			left = binaryNode(func(a, b float64) float64 { return a * b }, left, right)
+		case "/":
+			left = binaryNode(func(a, b float64) float64 { return a / b }, left, right)
+		default:
+			left = binaryNode(math.Mod, left, right)
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOperator("+", "-") {
+		op := p.next().text
+		operand, err := p.parseUnary()
+		if err != nil {
+			return node{}, err
+		}
+		if op == "-" {
+			return unaryNode(func(a float64) float64 { return -a }, operand), nil
+		}
+		return operand, nil
+	}
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (node, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return node{}, err
+	}
+
+	if p.isOperator("^") {
+		p.next()
+		// Right associative: 2^3^2 = 2^(3^2)
+		exponent, err := p.parseUnary()
+		if err != nil {
+			return node{}, err
+		}
+		return binaryNode(math.Pow, base, exponent), nil
+	}
+	return base, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		return constantNode(tok.value), nil
+	case tokenLParen:
+		inner, err := p.parseExpression()
+		if err != nil {
+			return node{}, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return node{}, p.errorf(closing, "expected \")\" to close \"(\" at column %d, found %s", tok.column, closing)
+		}
+		return inner, nil
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(tok)
+		}
+		return p.resolveName(tok)
+	}
+
+	return node{}, p.errorf(tok, "unexpected %s, expected a number, name or \"(\"", tok)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return node{}, p.errorf(name, "unknown function %q", name.text)
+	}
+	p.next() // "("
+
+	var args []node
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return node{}, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if closing := p.next(); closing.kind != tokenRParen {
+		return node{}, p.errorf(closing, "expected \")\" after arguments to %s, found %s", name.text, closing)
+	}
+	if len(args) != fn.arity {
+		return node{}, p.errorf(name, "%s takes %d argument(s), got %d", name.text, fn.arity, len(args))
+	}
+
+	if fn.arity == 1 {
+		return unaryNode(fn.call1, args[0]), nil
+	}
+	return binaryNode(fn.call2, args[0], args[1]), nil
+}
+
+// resolveName looks a bare name up as a variable, then a parameter, then a built-in constant.
+func (p *parser) resolveName(tok token) (node, error) {
+	for i, v := range p.vars {
+		if v == tok.text {
+			return variableNode(i), nil
+		}
+	}
+	if value, ok := p.params[tok.text]; ok {
+		return constantNode(value), nil
+	}
+	if value, ok := constants[tok.text]; ok {
+		return constantNode(value), nil
+	}
+	if _, ok := functions[tok.text]; ok {
+		return node{}, p.errorf(tok, "function %q must be called with arguments", tok.text)
+	}
+	return node{}, p.errorf(tok, "unknown name %q", tok.text)
+}
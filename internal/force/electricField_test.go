@@ -0,0 +1,138 @@
+package force
+
+import (
+	"math"
+	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+const fieldTolerance = 1e-9
+
+func TestParallelPlateField(t *testing.T) {
+	plates := ParallelPlateField{X: 100, Y: 200, Length: 300, Gap: 50, Voltage: 100}
+
+	tests := []struct {
+		name       string
+		field      ParallelPlateField
+		x, y       float64
+		expectedEx float64
+		expectedEy float64
+	}{
+		{name: "Inside horizontal plates", field: plates, x: 150, y: 220, expectedEx: 0, expectedEy: 2},
+		{name: "Left of the plates", field: plates, x: 50, y: 220, expectedEx: 0, expectedEy: 0},
+		{name: "Outside the gap", field: plates, x: 150, y: 260, expectedEx: 0, expectedEy: 0},
+		{
+			name:       "Inside vertical plates",
+			field:      ParallelPlateField{X: 100, Y: 200, Length: 300, Gap: 50, Voltage: -100, Vertical: true},
+			x:          120,
+			y:          450,
+			expectedEx: -2,
+			expectedEy: 0,
+		},
+		{name: "Zero gap", field: ParallelPlateField{Length: 10, Voltage: 5}, x: 0, y: 0, expectedEx: 0, expectedEy: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex, ey := tt.field.FieldAt(tt.x, tt.y, 0)
+			if math.Abs(ex-tt.expectedEx) > fieldTolerance || math.Abs(ey-tt.expectedEy) > fieldTolerance {
+				t.Errorf("FieldAt() = (%v, %v), want (%v, %v)", ex, ey, tt.expectedEx, tt.expectedEy)
+			}
+		})
+	}
+}
+
+func TestPointSourceField(t *testing.T) {
+	source := PointSourceField{X: 0, Y: 0, Charge: 1e-6, MinDistance: 1}
+
+	ex, ey := source.FieldAt(3, 4, 0)
+	expected := constants.CoulombsConstant * 1e-6 / 25
+	if math.Abs(ex-expected*0.6) > fieldTolerance || math.Abs(ey-expected*0.8) > fieldTolerance {
+		t.Errorf("FieldAt(3, 4) = (%v, %v), want (%v, %v)", ex, ey, expected*0.6, expected*0.8)
+	}
+
+	// Closer than MinDistance the magnitude is clamped
+	ex, _ = source.FieldAt(0.5, 0, 0)
+	clamped := constants.CoulombsConstant * 1e-6
+	if math.Abs(ex-clamped) > fieldTolerance {
+		t.Errorf("FieldAt(0.5, 0) = %v, want clamped %v", ex, clamped)
+	}
+
+	// At the source itself there is no defined direction
+	ex, ey = source.FieldAt(0, 0, 0)
+	if ex != 0 || ey != 0 {
+		t.Errorf("FieldAt at the source = (%v, %v), want (0, 0)", ex, ey)
+	}
+}
+
+func TestElectricForce(t *testing.T) {
+	field := UniformElectricField{Ex: 3, Ey: -2}
+
+	p := &particle.Particle{Charge: -2}
+	fx, fy := ElectricForce(p, field, 0)
+	if fx != -6 || fy != 4 {
+		t.Errorf("ElectricForce() = (%v, %v), want (-6, 4)", fx, fy)
+	}
+
+	neutral := &particle.Particle{}
+	fx, fy = ElectricForce(neutral, field, 0)
+	if fx != 0 || fy != 0 {
+		t.Errorf("ElectricForce() on neutral particle = (%v, %v), want (0, 0)", fx, fy)
+	}
+}
+
+func TestLorentzForce(t *testing.T) {
+	fields := ExternalFields{
+		Electric: []ElectricFieldSource{
+			UniformElectricField{Ex: 1},
+			ElectricField2D{FieldFunc: func(x, y float64) (float64, float64) { return x, y }},
+		},
+		Magnetic: []MagneticFieldSource{
+			MagneticField{Strength: 2, Direction: -1},
+			MagneticField2D{FieldFunc: func(x, y float64) (float64, int) { return 1, 1 }},
+		},
+	}
+
+	p := &particle.Particle{X: 2, Y: 3, Vx: 4, Vy: 5, Charge: 2}
+	fx, fy, fz := LorentzForce(p, fields, 0)
+
+	// E = (1+2, 3), B = -2+1 = -1
+	// F = q(E + v × B) = 2 * (3 + vy*B, 3 - vx*B) = 2 * (3 - 5, 3 + 4)
+	if math.Abs(fx+4) > fieldTolerance || math.Abs(fy-14) > fieldTolerance || fz != 0 {
+		t.Errorf("LorentzForce() = (%v, %v, %v), want (-4, 14, 0)", fx, fy, fz)
+	}
+
+	if !(ExternalFields{}).IsEmpty() {
+		t.Error("Expected zero-value ExternalFields to be empty")
+	}
+}
+
+func TestTimeVaryingFields(t *testing.T) {
+	fields := ExternalFields{
+		Electric: []ElectricFieldSource{
+			UniformElectricField{Ex: 10, Modulation: Sinusoid(0.25, 0)},
+		},
+		Magnetic: []MagneticFieldSource{
+			MagneticField{Strength: 2, Direction: 1, Modulation: Pulse(1, 1, 0)},
+		},
+	}
+
+	// A quarter period into the sinusoid the field is at its peak and the pulse has not started
+	ex, _ := fields.ElectricFieldAt(0, 0, 1)
+	if math.Abs(ex-10) > fieldTolerance {
+		t.Errorf("ElectricFieldAt(t=1) = %v, want 10", ex)
+	}
+	if _, _, B := fields.MagneticFieldAt(0, 0, 0.5); B != 0 {
+		t.Errorf("MagneticFieldAt(t=0.5) = %v, want 0 before the pulse", B)
+	}
+	if _, _, B := fields.MagneticFieldAt(0, 0, 1.5); B != 2 {
+		t.Errorf("MagneticFieldAt(t=1.5) = %v, want 2 during the pulse", B)
+	}
+
+	// Half a period in, the sinusoid crosses zero
+	ex, _ = fields.ElectricFieldAt(0, 0, 2)
+	if math.Abs(ex) > fieldTolerance {
+		t.Errorf("ElectricFieldAt(t=2) = %v, want 0", ex)
+	}
+}
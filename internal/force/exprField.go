@@ -0,0 +1,107 @@
+package force
+
+import (
+	"fmt"
+	"particle-physics-simulator/internal/expr"
+	"particle-physics-simulator/internal/particle"
+)
+
+// FieldVariables are the variables available in field expressions, in evaluation order:
+// position (x, y) in pixels and simulation time t in seconds.
+var FieldVariables = []string{"x", "y", "t"}
+
+// ForceVariables are the variables available in custom force expressions, in evaluation order:
+// position, velocity, charge q, mass m and simulation time t.
+var ForceVariables = []string{"x", "y", "vx", "vy", "q", "m", "t"}
+
+// ExprElectricField is an electric field whose components are expressions in x, y and t.
+type ExprElectricField struct {
+	Ex, Ey *expr.Expr
+}
+
+// NewExprElectricField compiles the expressions for the two field components.
+func NewExprElectricField(ex, ey string, params map[string]float64) (ExprElectricField, error) {
+	exExpr, err := expr.Compile(ex, FieldVariables, params)
+	if err != nil {
+		return ExprElectricField{}, fmt.Errorf("electric field Ex: %w", err)
+	}
+	eyExpr, err := expr.Compile(ey, FieldVariables, params)
+	if err != nil {
+		return ExprElectricField{}, fmt.Errorf("electric field Ey: %w", err)
+	}
+	return ExprElectricField{Ex: exExpr, Ey: eyExpr}, nil
+}
+
+// FieldAt evaluates both component expressions at (x, y) and time t.
+func (f ExprElectricField) FieldAt(x, y, t float64) (float64, float64) {
+	return f.Ex.Eval(x, y, t), f.Ey.Eval(x, y, t)
+}
+
+// ExprMagneticField is a magnetic field whose components are expressions in x, y and t.
+// Bx and By may be nil for a purely out-of-plane field; a positive Bz points out of the plane.
+type ExprMagneticField struct {
+	Bx, By, Bz *expr.Expr
+}
+
+// NewExprMagneticField compiles the expression for the signed out-of-plane field strength.
+func NewExprMagneticField(bz string, params map[string]float64) (ExprMagneticField, error) {
+	bzExpr, err := expr.Compile(bz, FieldVariables, params)
+	if err != nil {
+		return ExprMagneticField{}, fmt.Errorf("magnetic field Bz: %w", err)
+	}
+	return ExprMagneticField{Bz: bzExpr}, nil
+}
+
+// NewExprMagneticField3D compiles the expressions for all three field components.
+func NewExprMagneticField3D(bx, by, bz string, params map[string]float64) (ExprMagneticField, error) {
+	field, err := NewExprMagneticField(bz, params)
+	if err != nil {
+		return ExprMagneticField{}, err
+	}
+	if field.Bx, err = expr.Compile(bx, FieldVariables, params); err != nil {
+		return ExprMagneticField{}, fmt.Errorf("magnetic field Bx: %w", err)
+	}
+	if field.By, err = expr.Compile(by, FieldVariables, params); err != nil {
+		return ExprMagneticField{}, fmt.Errorf("magnetic field By: %w", err)
+	}
+	return field, nil
+}
+
+// FieldAt evaluates the field expressions at (x, y) and time t.
+func (f ExprMagneticField) FieldAt(x, y, t float64) (float64, float64, float64) {
+	bx, by, bz := 0.0, 0.0, 0.0
+	if f.Bx != nil {
+		bx = f.Bx.Eval(x, y, t)
+	}
+	if f.By != nil {
+		by = f.By.Eval(x, y, t)
+	}
+	if f.Bz != nil {
+		bz = f.Bz.Eval(x, y, t)
+	}
+	return bx, by, bz
+}
+
+// ExprForce is a custom force whose components are expressions in the particle state and time.
+type ExprForce struct {
+	Fx, Fy *expr.Expr
+}
+
+// NewExprForce compiles the expressions for the two force components.
+func NewExprForce(fx, fy string, params map[string]float64) (ExprForce, error) {
+	fxExpr, err := expr.Compile(fx, ForceVariables, params)
+	if err != nil {
+		return ExprForce{}, fmt.Errorf("force Fx: %w", err)
+	}
+	fyExpr, err := expr.Compile(fy, ForceVariables, params)
+	if err != nil {
+		return ExprForce{}, fmt.Errorf("force Fy: %w", err)
+	}
+	return ExprForce{Fx: fxExpr, Fy: fyExpr}, nil
+}
+
+// ForceOn evaluates the force on a particle at time t.
+func (f ExprForce) ForceOn(p *particle.Particle, t float64) (float64, float64) {
+	return f.Fx.Eval(p.X, p.Y, p.Vx, p.Vy, p.Charge, p.Mass, t),
+		f.Fy.Eval(p.X, p.Y, p.Vx, p.Vy, p.Charge, p.Mass, t)
+}
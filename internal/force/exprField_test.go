@@ -0,0 +1,53 @@
+package force
+
+import (
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"strings"
+	"testing"
+)
+
+func TestExprFields(t *testing.T) {
+	params := map[string]float64{"E0": 5, "B0": 2}
+
+	electric, err := NewExprElectricField("E0 * x", "-E0 * t", params)
+	if err != nil {
+		t.Fatalf("NewExprElectricField returned error: %v", err)
+	}
+	ex, ey := electric.FieldAt(2, 0, 3)
+	if ex != 10 || ey != -15 {
+		t.Errorf("FieldAt() = (%v, %v), want (10, -15)", ex, ey)
+	}
+
+	magnetic, err := NewExprMagneticField("B0 * cos(pi * y)", params)
+	if err != nil {
+		t.Fatalf("NewExprMagneticField returned error: %v", err)
+	}
+	if _, _, B := magnetic.FieldAt(0, 1, 0); math.Abs(B+2) > fieldTolerance {
+		t.Errorf("FieldAt() = %v, want -2", B)
+	}
+
+	_, err = NewExprElectricField("x", "y +", params)
+	if err == nil || !strings.HasPrefix(err.Error(), "electric field Ey: expr: column 4") {
+		t.Errorf("NewExprElectricField error = %v, want Ey parse error at column 4", err)
+	}
+}
+
+func TestExprForce(t *testing.T) {
+	// Linear drag plus a constant push along x
+	drag, err := NewExprForce("1 - gamma * vx", "-gamma * vy", map[string]float64{"gamma": 0.5})
+	if err != nil {
+		t.Fatalf("NewExprForce returned error: %v", err)
+	}
+
+	fields := ExternalFields{Forces: []CustomForce{drag}}
+	p := &particle.Particle{Vx: 4, Vy: -2, Mass: 1}
+	fx, fy, _ := ExternalForce(p, fields, 0)
+	if fx != -1 || fy != 1 {
+		t.Errorf("ExternalForce() = (%v, %v), want (-1, 1)", fx, fy)
+	}
+
+	if _, err := NewExprForce("q * z", "0", nil); err == nil {
+		t.Error("Expected error for unknown variable z")
+	}
+}
@@ -0,0 +1,74 @@
+package force
+
+import "particle-physics-simulator/internal/particle"
+
+// MagneticFieldSource is an externally applied magnetic field.
+// FieldAt returns (Bx, By, Bz) at time t, where z points out of the plane.
+type MagneticFieldSource interface {
+	FieldAt(x, y, t float64) (float64, float64, float64)
+}
+
+// CustomForce is any additional force acting on individual particles, such as an expression-defined force.
+type CustomForce interface {
+	ForceOn(p *particle.Particle, t float64) (float64, float64)
+}
+
+// ExternalFields groups the electric and magnetic fields applied to the simulation from outside,
+// along with any custom forces. Sources of the same kind are superposed.
+type ExternalFields struct {
+	Electric []ElectricFieldSource
+	Magnetic []MagneticFieldSource
+	Forces   []CustomForce
+}
+
+// ElectricFieldAt returns the total external electric field at (x, y) and time t.
+func (f ExternalFields) ElectricFieldAt(x, y, t float64) (float64, float64) {
+	ex, ey := 0.0, 0.0
+	for _, source := range f.Electric {
+		sx, sy := source.FieldAt(x, y, t)
+		ex += sx
+		ey += sy
+	}
+	return ex, ey
+}
+
+// MagneticFieldAt returns the total external magnetic field at (x, y) and time t.
+func (f ExternalFields) MagneticFieldAt(x, y, t float64) (float64, float64, float64) {
+	bx, by, bz := 0.0, 0.0, 0.0
+	for _, source := range f.Magnetic {
+		sx, sy, sz := source.FieldAt(x, y, t)
+		bx += sx
+		by += sy
+		bz += sz
+	}
+	return bx, by, bz
+}
+
+// IsEmpty reports whether no external field is configured.
+func (f ExternalFields) IsEmpty() bool {
+	return len(f.Electric) == 0 && len(f.Magnetic) == 0 && len(f.Forces) == 0
+}
+
+// LorentzForce calculates q(E + v × B) on a particle from all external fields at time t.
+// The electric field lies in the plane; the magnetic part uses the full 3-vector B and velocity.
+func LorentzForce(p *particle.Particle, fields ExternalFields, t float64) (float64, float64, float64) {
+	if p.Charge == 0 {
+		return 0, 0, 0
+	}
+
+	ex, ey := fields.ElectricFieldAt(p.X, p.Y, t)
+	bx, by, bz := fields.MagneticFieldAt(p.X, p.Y, t)
+	mx, my, mz := MagneticForce3D(p, bx, by, bz)
+	return p.Charge*ex + mx, p.Charge*ey + my, mz
+}
+
+// ExternalForce calculates the total force on a particle from the external fields and custom forces at time t.
+func ExternalForce(p *particle.Particle, fields ExternalFields, t float64) (float64, float64, float64) {
+	fx, fy, fz := LorentzForce(p, fields, t)
+	for _, custom := range fields.Forces {
+		cx, cy := custom.ForceOn(p, t)
+		fx += cx
+		fy += cy
+	}
+	return fx, fy, fz
+}
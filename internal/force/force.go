@@ -42,7 +42,12 @@ func getForceArray(size int) []float64 {
 	if cap(arr) < size {
 		return make([]float64, size) 
 	}
-	return arr[:size]
+	arr = arr[:size]
+	// Pooled arrays still hold the forces of their previous use
+	for i := range arr {
+		arr[i] = 0
+	}
+	return arr
 }
 
 func releaseForceArray(arr []float64) {
@@ -67,6 +72,11 @@ func ApplyForcesParallel(particles []*particle.Particle) {
 	chunkSize := (n + numGoroutines - 1) / numGoroutines
 	var wg sync.WaitGroup
 
+	// Each goroutine accumulates into its own arrays, since the reaction forces it
+	// applies to particle j may fall in another goroutine's chunk
+	localX := make([][]float64, numGoroutines)
+	localY := make([][]float64, numGoroutines)
+
 	// Parallelize force calculation
 	for g := 0; g < numGoroutines; g++ {
 		start := g * chunkSize
@@ -75,8 +85,11 @@ func ApplyForcesParallel(particles []*particle.Particle) {
 			end = n
 		}
 
+		localX[g] = getForceArray(n)
+		localY[g] = getForceArray(n)
+
 		wg.Add(1)
-		go func(start, end int) {
+		go func(start, end int, forceX, forceY []float64) {
 			defer wg.Done()
 			for i := start; i < end; i++ {
 				p1 := particles[i]
@@ -124,11 +137,21 @@ func ApplyForcesParallel(particles []*particle.Particle) {
 					forceY[j] -= fy
 				}
 			}
-		}(start, end)
+		}(start, end, localX[g], localY[g])
 	}
 
 	wg.Wait()
 
+	// Combine the per-goroutine forces
+	for g := range localX {
+		for i := 0; i < n; i++ {
+			forceX[i] += localX[g][i]
+			forceY[i] += localY[g][i]
+		}
+		releaseForceArray(localX[g])
+		releaseForceArray(localY[g])
+	}
+
 	// Apply forces to update particle velocities
 	for i, p := range particles {
 		if p.Movable {
@@ -1,6 +1,7 @@
 package force
 
 import (
+    "particle-physics-simulator/internal/constants"
     "particle-physics-simulator/internal/particle"
     "testing"
     "math"
@@ -14,128 +15,42 @@ func approxEqual(a, b float64) bool {
 
 func TestNewForce(t *testing.T) {
     tests := []struct {
-        name               string
-        value             float64
-        xComponent        float64
-        yComponent        float64
-        zComponent        float64
-        expectedMagnitude float64
+        name       string
+        value      float64
+        xComponent float64
+        yComponent float64
     }{
         {
-            name:               "Unit force along x-axis",
-            value:             1.0,
-            xComponent:        1.0,
-            yComponent:        0.0,
-            zComponent:        0.0,
-            expectedMagnitude: 1.0,
+            name:       "Unit force along x-axis",
+            value:      1.0,
+            xComponent: 1.0,
+            yComponent: 0.0,
         },
         {
-            name:               "Force with equal components",
-            value:             1.0,
-            xComponent:        1.0,
-            yComponent:        1.0,
-            zComponent:        1.0,
-            expectedMagnitude: math.Sqrt(3),
+            name:       "Force with equal components",
+            value:      1.0,
+            xComponent: 1.0 / math.Sqrt(2),
+            yComponent: 1.0 / math.Sqrt(2),
         },
         {
-            name:               "Zero force",
-            value:             0.0,
-            xComponent:        0.0,
-            yComponent:        0.0,
-            zComponent:        0.0,
-            expectedMagnitude: 0.0,
+            name:       "Zero force",
+            value:      0.0,
+            xComponent: 0.0,
+            yComponent: 0.0,
         },
     }
 
     for _, tt := range tests {
         t.Run(tt.name, func(t *testing.T) {
-            f := NewForce(tt.value, tt.xComponent, tt.yComponent, tt.zComponent)
+            f := NewForce(tt.value, tt.xComponent, tt.yComponent)
             if f == nil {
                 t.Fatal("NewForce returned nil")
             }
             if !approxEqual(f.Value, tt.value) {
                 t.Errorf("Value = %v, want %v", f.Value, tt.value)
             }
-            if !approxEqual(f.Magnitude(), tt.expectedMagnitude) {
-                t.Errorf("Magnitude = %v, want %v", f.Magnitude(), tt.expectedMagnitude)
-            }
-        })
-    }
-}
-
-func TestForceDirection(t *testing.T) {
-    tests := []struct {
-        name     string
-        force    *Force
-        expectedX float64
-        expectedY float64
-        expectedZ float64
-    }{
-        {
-            name:      "Unit vector along x",
-            force:     NewForce(1.0, 1.0, 0.0, 0.0),
-            expectedX: 1.0,
-            expectedY: 0.0,
-            expectedZ: 0.0,
-        },
-        {
-            name:      "45 degrees in xy-plane",
-            force:     NewForce(1.0, 1.0, 1.0, 0.0),
-            expectedX: 1.0/math.Sqrt(2),
-            expectedY: 1.0/math.Sqrt(2),
-            expectedZ: 0.0,
-        },
-    }
-
-    for _, tt := range tests {
-        t.Run(tt.name, func(t *testing.T) {
-            x, y, z := tt.force.Direction()
-            if !approxEqual(x, tt.expectedX) || !approxEqual(y, tt.expectedY) || !approxEqual(z, tt.expectedZ) {
-                t.Errorf("Direction() = (%v, %v, %v), want (%v, %v, %v)",
-                    x, y, z, tt.expectedX, tt.expectedY, tt.expectedZ)
-            }
-        })
-    }
-}
-
-func TestApplyForce(t *testing.T) {
-    tests := []struct {
-        name           string
-        particle      *particle.Particle
-        force         *Force
-        expectedVx    float64
-        expectedVy    float64
-    }{
-        {
-            name: "Unit force on unit mass",
-            particle: &particle.Particle{
-                Mass: 1.0,
-                Vx:   0.0,
-                Vy:   0.0,
-            },
-            force:      NewForce(1.0, 1.0, 0.0, 0.0),
-            expectedVx: 1.0,
-            expectedVy: 0.0,
-        },
-        {
-            name: "Force on larger mass",
-            particle: &particle.Particle{
-                Mass: 2.0,
-                Vx:   0.0,
-                Vy:   0.0,
-            },
-            force:      NewForce(2.0, 1.0, 1.0, 0.0),
-            expectedVx: 1.0,
-            expectedVy: 1.0,
-        },
-    }
-
-    for _, tt := range tests {
-        t.Run(tt.name, func(t *testing.T) {
-            ApplyForce(tt.particle, tt.force)
-            if !approxEqual(tt.particle.Vx, tt.expectedVx) || !approxEqual(tt.particle.Vy, tt.expectedVy) {
-                t.Errorf("Velocity = (%v, %v), want (%v, %v)",
-                    tt.particle.Vx, tt.particle.Vy, tt.expectedVx, tt.expectedVy)
+            if !approxEqual(f.XComponent, tt.xComponent) || !approxEqual(f.YComponent, tt.yComponent) {
+                t.Errorf("Components = (%v, %v), want (%v, %v)", f.XComponent, f.YComponent, tt.xComponent, tt.yComponent)
             }
         })
     }
@@ -294,7 +209,7 @@ func TestGravitationalForce(t *testing.T) {
                 X: 1, Y: 0, Z: 0,
                 Mass: 1.0,
             },
-            expected: GravitationalConstant,
+            expected: constants.GravitationalConstant,
         },
         {
             name: "Double mass at unit distance",
@@ -306,15 +221,17 @@ func TestGravitationalForce(t *testing.T) {
                 X: 1, Y: 0, Z: 0,
                 Mass: 1.0,
             },
-            expected: 2 * GravitationalConstant,
+            expected: 2 * constants.GravitationalConstant,
         },
     }
 
     for _, tt := range tests {
         t.Run(tt.name, func(t *testing.T) {
-            force := CalculateGravitationalForce(tt.p1, tt.p2)
-            if !approxEqual(force, tt.expected) {
-                t.Errorf("CalculateGravitationalForce() = %v, want %v", force, tt.expected)
+            ApplyGravitationalForces([]*particle.Particle{tt.p1, tt.p2})
+            // The particles pull each other together along x with equal and opposite forces
+            force1, force2 := tt.p1.Ax*tt.p1.Mass, tt.p2.Ax*tt.p2.Mass
+            if math.Abs(force1-tt.expected) > 1e-9*tt.expected || math.Abs(force2+tt.expected) > 1e-9*tt.expected {
+                t.Errorf("forces = %v, %v, want %v towards each other", force1, force2, tt.expected)
             }
         })
     }
@@ -0,0 +1,61 @@
+package force
+
+import (
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+func TestMagneticForce3D(t *testing.T) {
+	p := &particle.Particle{Charge: 2, Vx: 2, Vy: 3, Vz: 4}
+
+	fx, fy, fz := MagneticForce3D(p, 1, -1, 0.5)
+
+	// v × B = (vy*Bz - vz*By, vz*Bx - vx*Bz, vx*By - vy*Bx) = (5.5, 3, -5)
+	if fx != 11 || fy != 6 || fz != -10 {
+		t.Errorf("MagneticForce3D() = (%v, %v, %v), want (11, 6, -10)", fx, fy, fz)
+	}
+
+	// The magnetic force is always perpendicular to the velocity and does no work
+	if power := fx*p.Vx + fy*p.Vy + fz*p.Vz; math.Abs(power) > fieldTolerance {
+		t.Errorf("F·v = %v, want 0", power)
+	}
+
+	// With only Bz it reduces to the in-plane MagneticForce
+	p.Vz = 0
+	fx, fy, fz = MagneticForce3D(p, 0, 0, 1.5)
+	ix, iy := MagneticForce(p, 1.5)
+	if fx != ix || fy != iy || fz != 0 {
+		t.Errorf("MagneticForce3D() with Bz only = (%v, %v, %v), want (%v, %v, 0)", fx, fy, fz, ix, iy)
+	}
+}
+
+func TestInPlaneMagneticField(t *testing.T) {
+	fields := ExternalFields{
+		Magnetic: []MagneticFieldSource{
+			MagneticFieldVector{Bx: 1},
+			MagneticField3D{FieldFunc: func(x, y float64) (float64, float64, float64) { return 0, x, 0 }},
+		},
+	}
+
+	bx, by, bz := fields.MagneticFieldAt(2, 0, 0)
+	if bx != 1 || by != 2 || bz != 0 {
+		t.Errorf("MagneticFieldAt() = (%v, %v, %v), want (1, 2, 0)", bx, by, bz)
+	}
+
+	// Motion along y in a field along x is pushed out of the plane
+	p := &particle.Particle{Charge: 1, Vy: 3}
+	_, _, fz := LorentzForce(p, ExternalFields{Magnetic: []MagneticFieldSource{MagneticFieldVector{Bx: 1}}}, 0)
+	if fz != -3 {
+		t.Errorf("LorentzForce() z component = %v, want -3", fz)
+	}
+
+	field, err := NewExprMagneticField3D("x", "-y", "1", nil)
+	if err != nil {
+		t.Fatalf("NewExprMagneticField3D returned error: %v", err)
+	}
+	bx, by, bz = field.FieldAt(2, 3, 0)
+	if bx != 2 || by != -3 || bz != 1 {
+		t.Errorf("ExprMagneticField.FieldAt() = (%v, %v, %v), want (2, -3, 1)", bx, by, bz)
+	}
+}
@@ -16,26 +16,84 @@ func MagneticForce(p *particle.Particle, B float64) (float64, float64) {
 	return q * p.Vy * B, -q * p.Vx * B
 }
 
+// MagneticForce3D calculates the full Lorentz force q(v × B) for a field B = (Bx, By, Bz),
+// including the out-of-plane velocity Vz.
+func MagneticForce3D(p *particle.Particle, Bx, By, Bz float64) (float64, float64, float64) {
+	if p.Charge == 0 {
+		return 0, 0, 0
+	}
+
+	q := p.Charge
+	fx := q * (p.Vy*Bz - p.Vz*By)
+	fy := q * (p.Vz*Bx - p.Vx*Bz)
+	fz := q * (p.Vx*By - p.Vy*Bx)
+	return fx, fy, fz
+}
+
 // MagneticField represents a uniform magnetic field in a 2D simulation.
 type MagneticField struct {
-	Strength  float64 // Magnitude of the B field
-	Direction int     // +1 for out of the plane, -1 for into the plane
+	Strength   float64  // Magnitude of the B field
+	Direction  int      // +1 for out of the plane, -1 for into the plane
+	Modulation Waveform // Time dependence of the field, constant if nil
 }
 
-func MagneticForceWithDirection(p *particle.Particle, field MagneticField) (float64, float64) {
-	B := field.Strength * float64(field.Direction)
+// FieldAt returns the out-of-plane field at time t, which is the same everywhere.
+func (field MagneticField) FieldAt(x, y, t float64) (float64, float64, float64) {
+	return 0, 0, field.Strength * float64(field.Direction) * field.Modulation.at(t)
+}
+
+// MagneticForceWithDirection calculates the magnetic force on a particle in a uniform field at time t.
+func MagneticForceWithDirection(p *particle.Particle, field MagneticField, t float64) (float64, float64) {
+	_, _, B := field.FieldAt(p.X, p.Y, t)
 	return MagneticForce(p, B)
 }
 
 // MagneticField2D models a non-uniform magnetic field as a function of position.
 type MagneticField2D struct {
-	FieldFunc func(x, y float64) (float64, int) // Function to get (magnitude, direction) at a given position
+	FieldFunc  func(x, y float64) (float64, int) // Function to get (magnitude, direction) at a given position
+	Modulation Waveform                          // Time dependence of the field, constant if nil
+}
+
+// FieldAt returns the out-of-plane field at (x, y) and time t, treating a nil function as no field.
+func (field MagneticField2D) FieldAt(x, y, t float64) (float64, float64, float64) {
+	if field.FieldFunc == nil {
+		return 0, 0, 0
+	}
+	B, direction := field.FieldFunc(x, y)
+	return 0, 0, B * float64(direction) * field.Modulation.at(t)
+}
+
+// MagneticFieldVector represents a uniform magnetic field with in-plane and out-of-plane components.
+type MagneticFieldVector struct {
+	Bx, By, Bz float64  // Field components in tesla; z points out of the plane
+	Modulation Waveform // Time dependence of the field, constant if nil
+}
+
+// FieldAt returns the field components at time t, which are the same everywhere.
+func (field MagneticFieldVector) FieldAt(x, y, t float64) (float64, float64, float64) {
+	scale := field.Modulation.at(t)
+	return field.Bx * scale, field.By * scale, field.Bz * scale
+}
+
+// MagneticField3D models a non-uniform magnetic field with all three components, such as a magnetic mirror.
+type MagneticField3D struct {
+	FieldFunc  func(x, y float64) (float64, float64, float64) // Function to get (Bx, By, Bz) at a given position
+	Modulation Waveform                                       // Time dependence of the field, constant if nil
+}
+
+// FieldAt evaluates the field function at (x, y) and time t, treating a nil function as no field.
+func (field MagneticField3D) FieldAt(x, y, t float64) (float64, float64, float64) {
+	if field.FieldFunc == nil {
+		return 0, 0, 0
+	}
+	bx, by, bz := field.FieldFunc(x, y)
+	scale := field.Modulation.at(t)
+	return bx * scale, by * scale, bz * scale
 }
 
-// MagneticForceNonUniform calculates the magnetic force for a particle in a non-uniform field.
-func MagneticForceNonUniform(p *particle.Particle, field MagneticField2D) (float64, float64) {
-	B, direction := field.FieldFunc(p.X, p.Y)
-	B *= float64(direction)
+// MagneticForceNonUniform calculates the magnetic force for a particle in a non-uniform field at time t.
+func MagneticForceNonUniform(p *particle.Particle, field MagneticField2D, t float64) (float64, float64) {
+	_, _, B := field.FieldAt(p.X, p.Y, t)
 	return MagneticForce(p, B)
 }
 
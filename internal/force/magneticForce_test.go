@@ -25,7 +25,7 @@ func TestMagneticForce(t *testing.T) {
 	expectedForceZ := p.Charge * (p.Vx*magneticFieldY - p.Vy*magneticFieldX) // F_z = q * (v_x * B_y - v_y * B_x)
 
 	// Call the function under test
-	forceX, forceY, forceZ := MagneticForce(p, magneticFieldX, magneticFieldY, magneticFieldZ)
+	forceX, forceY, forceZ := MagneticForce3D(p, magneticFieldX, magneticFieldY, magneticFieldZ)
 
 	// Check if the calculated magnetic forces are correct
 	if forceX != expectedForceX {
@@ -39,3 +39,36 @@ func TestMagneticForce(t *testing.T) {
 	}
 }
 
+func TestMagneticForceModulation(t *testing.T) {
+	p := &particle.Particle{X: 10, Y: 20, Vx: 2, Vy: 3, Charge: 1}
+	// A pulse switches the field on from t = 1 to t = 2
+	modulation := Pulse(1, 1, 0)
+	uniform := MagneticField{Strength: 4, Direction: 1, Modulation: modulation}
+	nonUniform := MagneticField2D{
+		FieldFunc:  func(x, y float64) (float64, int) { return 4, 1 },
+		Modulation: modulation,
+	}
+
+	tests := []struct {
+		name  string
+		force func(t float64) (float64, float64)
+	}{
+		{"uniform", func(t float64) (float64, float64) { return MagneticForceWithDirection(p, uniform, t) }},
+		{"non-uniform", func(t float64) (float64, float64) { return MagneticForceNonUniform(p, nonUniform, t) }},
+	}
+
+	onX, onY := MagneticForce(p, 4)
+	for _, tt := range tests {
+		if fx, fy := tt.force(0); fx != 0 || fy != 0 {
+			t.Errorf("%s before the pulse: force = (%v, %v), want zero", tt.name, fx, fy)
+		}
+		if fx, fy := tt.force(1.5); fx != onX || fy != onY {
+			t.Errorf("%s during the pulse: force = (%v, %v), want (%v, %v)", tt.name, fx, fy, onX, onY)
+		}
+	}
+
+	// No field function means no field rather than a panic
+	if fx, fy := MagneticForceNonUniform(p, MagneticField2D{}, 0); fx != 0 || fy != 0 {
+		t.Errorf("force = (%v, %v) without a field function, want zero", fx, fy)
+	}
+}
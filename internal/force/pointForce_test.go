@@ -0,0 +1,45 @@
+package force
+
+import (
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+func TestPointForce(t *testing.T) {
+	f := NewPointForce()
+	f.X, f.Y, f.Radius = 100, 100, 50
+	f.Strength = 10
+
+	p := &particle.Particle{X: 75, Y: 100, Mass: 2, Vx: 1}
+	if fx, fy := f.ForceOn(p, 0); fx != 0 || fy != 0 {
+		t.Errorf("inactive force = (%v, %v), want zero", fx, fy)
+	}
+	f.Active = true
+
+	tests := []struct {
+		mode   PointForceMode
+		x      float64
+		fx, fy float64
+	}{
+		{Attract, 75, 10, 0}, // Halfway out, half the strength, times the mass
+		{Repel, 75, -10, 0},
+		{Attract, 40, 0, 0}, // Outside the radius
+		{Spring, 75, 2 * (40*25 - 8*1), 0},
+	}
+	for _, tt := range tests {
+		f.Mode = tt.mode
+		p.X = tt.x
+		fx, fy := f.ForceOn(p, 0)
+		if math.Abs(fx-tt.fx) > 1e-9 || math.Abs(fy-tt.fy) > 1e-9 {
+			t.Errorf("%v at x = %v: force (%v, %v), want (%v, %v)", tt.mode, tt.x, fx, fy, tt.fx, tt.fy)
+		}
+	}
+
+	// Acts through the external fields like any other custom force
+	f.Mode = Attract
+	p.X = 75
+	if fx, _, _ := ExternalForce(p, ExternalFields{Forces: []CustomForce{f}}, 0); fx != 10 {
+		t.Errorf("ExternalForce() x = %v, want 10", fx)
+	}
+}
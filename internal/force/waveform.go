@@ -0,0 +1,77 @@
+package force
+
+import (
+	"math"
+	"sort"
+)
+
+// Waveform scales a field with simulation time. It returns a dimensionless factor
+// that multiplies the static field; a nil Waveform leaves the field constant.
+type Waveform func(t float64) float64
+
+// at evaluates the waveform, treating nil as a constant factor of 1.
+func (w Waveform) at(t float64) float64 {
+	if w == nil {
+		return 1
+	}
+	return w(t)
+}
+
+// Sinusoid returns an AC waveform sin(2πft + phase) with frequency f in Hz and phase in radians.
+func Sinusoid(frequency, phase float64) Waveform {
+	return func(t float64) float64 {
+		return math.Sin(2*math.Pi*frequency*t + phase)
+	}
+}
+
+// Pulse returns a waveform that is 1 for width seconds starting at start and 0 otherwise.
+// If period is positive the pulse repeats every period seconds.
+func Pulse(start, width, period float64) Waveform {
+	return func(t float64) float64 {
+		elapsed := t - start
+		if elapsed < 0 {
+			return 0
+		}
+		if period > 0 {
+			elapsed = math.Mod(elapsed, period)
+		}
+		if elapsed < width {
+			return 1
+		}
+		return 0
+	}
+}
+
+// Ramp returns a waveform that rises linearly from 0 at start to 1 after duration seconds and then holds.
+func Ramp(start, duration float64) Waveform {
+	return func(t float64) float64 {
+		if t <= start {
+			return 0
+		}
+		if duration <= 0 || t >= start+duration {
+			return 1
+		}
+		return (t - start) / duration
+	}
+}
+
+// ScheduleStep sets a waveform to Value from Time onwards.
+type ScheduleStep struct {
+	Time  float64
+	Value float64
+}
+
+// Piecewise returns a waveform that holds the value of the latest step whose Time has passed, and 0 before the first step.
+func Piecewise(steps ...ScheduleStep) Waveform {
+	sorted := append([]ScheduleStep(nil), steps...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+
+	return func(t float64) float64 {
+		// Index of the first step that has not started yet
+		i := sort.Search(len(sorted), func(i int) bool { return sorted[i].Time > t })
+		if i == 0 {
+			return 0
+		}
+		return sorted[i-1].Value
+	}
+}
@@ -0,0 +1,49 @@
+package force
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWaveforms(t *testing.T) {
+	tests := []struct {
+		name     string
+		waveform Waveform
+		t        float64
+		expected float64
+	}{
+		{name: "Nil waveform is constant", waveform: nil, t: 42, expected: 1},
+		{name: "Sinusoid at quarter period", waveform: Sinusoid(2, 0), t: 0.125, expected: 1},
+		{name: "Sinusoid with phase", waveform: Sinusoid(1, math.Pi/2), t: 0, expected: 1},
+		{name: "Pulse before start", waveform: Pulse(1, 0.5, 0), t: 0.9, expected: 0},
+		{name: "Pulse during width", waveform: Pulse(1, 0.5, 0), t: 1.2, expected: 1},
+		{name: "Single pulse does not repeat", waveform: Pulse(1, 0.5, 0), t: 3.2, expected: 0},
+		{name: "Repeating pulse", waveform: Pulse(1, 0.5, 2), t: 3.2, expected: 1},
+		{name: "Repeating pulse between pulses", waveform: Pulse(1, 0.5, 2), t: 2.2, expected: 0},
+		{name: "Ramp before start", waveform: Ramp(1, 2), t: 0, expected: 0},
+		{name: "Ramp halfway", waveform: Ramp(1, 2), t: 2, expected: 0.5},
+		{name: "Ramp holds", waveform: Ramp(1, 2), t: 10, expected: 1},
+		{name: "Piecewise before first step", waveform: Piecewise(ScheduleStep{Time: 1, Value: 3}), t: 0.5, expected: 0},
+		{
+			name:     "Piecewise holds latest step",
+			waveform: Piecewise(ScheduleStep{Time: 2, Value: -1}, ScheduleStep{Time: 1, Value: 3}),
+			t:        1.5,
+			expected: 3,
+		},
+		{
+			name:     "Piecewise switches at step time",
+			waveform: Piecewise(ScheduleStep{Time: 1, Value: 3}, ScheduleStep{Time: 2, Value: -1}),
+			t:        2,
+			expected: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.waveform.at(tt.t)
+			if math.Abs(got-tt.expected) > fieldTolerance {
+				t.Errorf("waveform(%v) = %v, want %v", tt.t, got, tt.expected)
+			}
+		})
+	}
+}
@@ -5,6 +5,11 @@ import "fmt"
 
 // internal/particle/analytics.go
 func (p *Particle) GetInfo() string {
-    return fmt.Sprintf("Mass: %.2f, Position: (%.6f, %.6f), Velocity: (%.6f, %.6f), Grounded: %v",
-        p.Mass, p.X, p.Y, p.Vx, p.Vy, p.IsGrounded)
+    return fmt.Sprintf("Mass: %.2f, Position: (%.6f, %.6f), Velocity: (%.6f, %.6f), Kinetic Energy: %.6f, Grounded: %v",
+        p.Mass, p.X, p.Y, p.Vx, p.Vy, p.KineticEnergy(), p.IsGrounded)
+}
+
+// KineticEnergy returns 1/2 m v², including any out-of-plane velocity.
+func (p *Particle) KineticEnergy() float64 {
+    return 0.5 * p.Mass * (p.Vx*p.Vx + p.Vy*p.Vy + p.Vz*p.Vz)
 }
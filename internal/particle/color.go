@@ -0,0 +1,12 @@
+package particle
+
+import "image/color"
+
+// RGBA8 converts the color to 8-bit channels for drawing.
+func (c Color) RGBA8() color.RGBA {
+	return color.RGBA{R: channel8(c.R), G: channel8(c.G), B: channel8(c.B), A: channel8(c.A)}
+}
+
+func channel8(v float32) uint8 {
+	return uint8(min(max(v, 0), 1) * 255)
+}
@@ -0,0 +1,21 @@
+package particle
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestRGBA8(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want color.RGBA
+	}{
+		{Color{R: 1, G: 0, B: 0.5, A: 1}, color.RGBA{R: 255, G: 0, B: 127, A: 255}},
+		{Color{R: 2, G: -1, B: 0, A: 0}, color.RGBA{R: 255}},
+	}
+	for _, tt := range tests {
+		if got := tt.c.RGBA8(); got != tt.want {
+			t.Errorf("%+v.RGBA8() = %v, want %v", tt.c, got, tt.want)
+		}
+	}
+}
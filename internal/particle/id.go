@@ -0,0 +1,30 @@
+package particle
+
+import "sync/atomic"
+
+var lastID atomic.Int64
+
+// NextID returns a new particle ID. IDs start at 1, so 0 marks a particle without one.
+func NextID() int {
+	return int(lastID.Add(1))
+}
+
+// ReserveID makes sure NextID never returns id or anything below it, so particles loaded
+// with existing IDs don't clash with new ones.
+func ReserveID(id int) {
+	for {
+		last := lastID.Load()
+		if int64(id) <= last || lastID.CompareAndSwap(last, int64(id)) {
+			return
+		}
+	}
+}
+
+// AssignIDs gives an ID to every particle that doesn't have one yet.
+func AssignIDs(particles []*Particle) {
+	for _, p := range particles {
+		if p.ID == 0 {
+			p.ID = NextID()
+		}
+	}
+}
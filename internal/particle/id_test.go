@@ -0,0 +1,22 @@
+package particle
+
+import "testing"
+
+func TestIDs(t *testing.T) {
+	a := NewParticle(0, 0, 0, 0, 0, 0, 1, 1, Color{}, true)
+	b := NewCoulombParticle(0, 0, 0, 0, 0, 0, 1, 1, Color{}, 1, true)
+	if a.ID == 0 || b.ID == 0 || a.ID == b.ID {
+		t.Errorf("constructors gave IDs %d and %d, want distinct non-zero IDs", a.ID, b.ID)
+	}
+
+	ReserveID(b.ID + 100)
+	if id := NextID(); id <= b.ID+100 {
+		t.Errorf("NextID() = %d after reserving %d", id, b.ID+100)
+	}
+
+	particles := []*Particle{{}, {ID: 7}}
+	AssignIDs(particles)
+	if particles[0].ID == 0 || particles[1].ID != 7 {
+		t.Errorf("AssignIDs() gave IDs %d and %d", particles[0].ID, particles[1].ID)
+	}
+}
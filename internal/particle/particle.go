@@ -2,9 +2,11 @@
 package particle
 
 type Particle struct {
+	ID      int // Stable identifier, see NextID
 	X, Y    float64
 	Vx, Vy float64
 	Ax, Ay float64
+	Z, Vz, Az  float64 // Out-of-plane motion, only driven by in-plane magnetic fields
 	Mass       float64
 	Radius     float64
 	Color      Color
@@ -20,6 +22,7 @@ type Color struct {
 
 func NewParticle(x, y,  vx, vy, ax, ay, mass, radius float64, color Color,movable bool) *Particle {
 	return &Particle{
+		ID: NextID(),
 		X: x, Y: y,
 		Vx: vx, Vy: vy, 
 		Ax: ax, Ay: ay,
@@ -32,6 +35,7 @@ func NewParticle(x, y,  vx, vy, ax, ay, mass, radius float64, color Color,movabl
 
 func NewCoulombParticle(x, y,  vx, vy, ax, ay,  mass, radius float64, color Color, charge float64,movable bool) *Particle {
     return &Particle{
+        ID: NextID(),
         X: x, Y: y, 
         Vx: vx, Vy: vy, 
         Ax: ax, Ay: ay,
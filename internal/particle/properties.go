@@ -0,0 +1,64 @@
+package particle
+
+// Properties are what a particle is, as opposed to where it is and how it moves.
+type Properties struct {
+	Mass    float64
+	Radius  float64
+	Charge  float64
+	Color   Color
+	Movable bool
+}
+
+// Properties returns the particle's properties.
+func (p *Particle) Properties() Properties {
+	return Properties{
+		Mass:    p.Mass,
+		Radius:  p.Radius,
+		Charge:  p.Charge,
+		Color:   p.Color,
+		Movable: p.Movable,
+	}
+}
+
+// SetProperties changes the particle's properties, leaving its position and motion alone.
+func (p *Particle) SetProperties(props Properties) {
+	p.Mass = props.Mass
+	p.Radius = props.Radius
+	p.Charge = props.Charge
+	p.Color = props.Color
+	p.Movable = props.Movable
+}
+
+// New creates a particle with these properties at rest at (x, y).
+func (props Properties) New(x, y float64) *Particle {
+	return NewCoulombParticle(x, y, 0, 0, 0, 0, props.Mass, props.Radius, props.Color, props.Charge, props.Movable)
+}
+
+// Species is a named set of properties to create particles with.
+type Species struct {
+	Name string
+	Properties
+}
+
+// SpeciesList returns the species offered when placing particles. The first is the neutral
+// particle placed by default.
+func SpeciesList() []Species {
+	return []Species{
+		{"Neutral", Properties{Mass: 10, Radius: 10, Color: Color{R: 0.5, G: 0.7, B: 1, A: 1}, Movable: true}},
+		{"Positive", Properties{Mass: 5, Radius: 5, Charge: 0.1, Color: Color{R: 1, G: 0.3, B: 0.3, A: 1}, Movable: true}},
+		{"Negative", Properties{Mass: 5, Radius: 5, Charge: -0.1, Color: Color{R: 0.3, G: 0.5, B: 1, A: 1}, Movable: true}},
+		{"Heavy", Properties{Mass: 100, Radius: 20, Color: Color{R: 0.6, G: 0.6, B: 0.6, A: 1}, Movable: true}},
+		{"Anchor", Properties{Mass: 50, Radius: 15, Charge: 0.5, Color: Color{R: 1, G: 1, B: 0, A: 1}}},
+	}
+}
+
+// SpeciesOf returns the index in SpeciesList of the species with exactly these properties,
+// or -1 if none has.
+func SpeciesOf(props Properties) int {
+	for i, s := range SpeciesList() {
+		if s.Properties == props {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,26 @@
+package particle
+
+import "testing"
+
+func TestProperties(t *testing.T) {
+	species := SpeciesList()
+	p := species[1].New(3, 4)
+	if p.X != 3 || p.Y != 4 || p.ID == 0 || p.Properties() != species[1].Properties {
+		t.Errorf("New made %+v", *p)
+	}
+	if got := SpeciesOf(p.Properties()); got != 1 {
+		t.Errorf("SpeciesOf = %d, want 1", got)
+	}
+
+	p.Vx = 7
+	p.SetProperties(species[3].Properties)
+	if p.Mass != species[3].Mass || p.Vx != 7 || p.X != 3 {
+		t.Errorf("SetProperties gave %+v", *p)
+	}
+
+	custom := species[0].Properties
+	custom.Mass++
+	if got := SpeciesOf(custom); got != -1 {
+		t.Errorf("SpeciesOf(custom) = %d, want -1", got)
+	}
+}
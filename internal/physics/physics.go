@@ -1,6 +1,7 @@
 package physics
 
 import (
+	"math"
 	"particle-physics-simulator/internal/constants"
 	"particle-physics-simulator/internal/electrostatics"
 	"particle-physics-simulator/internal/force"
@@ -16,6 +17,7 @@ func ApplyGravity(p *particle.Particle) {
 func ApplyAirFriction(p *particle.Particle) {
 	p.Vx -= p.Vx * constants.AirDragCoefficient
 	p.Vy -= p.Vy * constants.AirDragCoefficient
+	p.Vz -= p.Vz * constants.AirDragCoefficient
 }
 
 func ApplyFriction(p *particle.Particle) {
@@ -44,6 +46,7 @@ func UpdateVelocity(p *particle.Particle, dt float64) {
         ApplyGravity(p)
 		p.Vx += p.Ax * dt
 		p.Vy += p.Ay * dt
+		p.Vz += p.Az * dt
 	}
 }
 
@@ -51,6 +54,7 @@ func UpdatePosition(p *particle.Particle, dt float64) {
 	if p.Movable {
 		p.X += p.Vx * dt
 		p.Y += p.Vy * dt
+		p.Z += p.Vz * dt
 	}
 }
 
@@ -89,15 +93,91 @@ func ApplyBoundaryConditions(p *particle.Particle, screenWidth, screenHeight int
 	}
 }
 
-// ApplyMagneticForces applies magnetic forces to particles.
-func ApplyMagneticForces(particles []*particle.Particle, magneticField force.MagneticField) {
+// ApplyMagneticForces applies magnetic forces to particles at simulation time t.
+func ApplyMagneticForces(particles []*particle.Particle, magneticField force.MagneticField, t float64) {
 	for _, p := range particles {
-		fx, fy := force.MagneticForceWithDirection(p, magneticField)
+		fx, fy := force.MagneticForceWithDirection(p, magneticField, t)
 		p.Ax += fx / p.Mass
 		p.Ay += fy / p.Mass
 	}
 }
 
+// ApplyExternalFields applies the Lorentz force from externally applied fields, plus any custom forces,
+// at simulation time t, changing the velocity of every movable particle over the time step dt.
+//
+// The velocity is advanced with the Boris scheme: half the electric and custom kick, a rotation
+// about the magnetic field, then the other half of the kick. The rotation keeps the speed
+// exactly, so a magnetic field does no work and gyration orbits don't spiral outwards.
+func ApplyExternalFields(particles []*particle.Particle, fields force.ExternalFields, t, dt float64) {
+	for _, p := range particles {
+		if !p.Movable || p.Mass == 0 {
+			continue
+		}
+
+		// The kick is everything but the magnetic force, at the velocity before the step
+		fx, fy, fz := force.ExternalForce(p, fields, t)
+		bx, by, bz := fields.MagneticFieldAt(p.X, p.Y, t)
+		mx, my, mz := force.MagneticForce3D(p, bx, by, bz)
+		kx := (fx - mx) / p.Mass * dt / 2
+		ky := (fy - my) / p.Mass * dt / 2
+		kz := (fz - mz) / p.Mass * dt / 2
+
+		vx, vy, vz := p.Vx+kx, p.Vy+ky, p.Vz+kz
+		if p.Charge != 0 {
+			vx, vy, vz = borisRotate(vx, vy, vz, p.Charge/p.Mass*dt/2, bx, by, bz)
+		}
+		p.Vx, p.Vy, p.Vz = vx+kx, vy+ky, vz+kz
+	}
+}
+
+// borisRotate rotates the velocity v about the field B by the angle the particle gyrates
+// through in a step, where h is q/m times half the step.
+func borisRotate(vx, vy, vz, h, bx, by, bz float64) (float64, float64, float64) {
+	tx, ty, tz := h*bx, h*by, h*bz
+	s := 2 / (1 + tx*tx + ty*ty + tz*tz)
+
+	// v' = v + v × t, then v+ = v + v' × s*t
+	px := vx + vy*tz - vz*ty
+	py := vy + vz*tx - vx*tz
+	pz := vz + vx*ty - vy*tx
+	return vx + s*(py*tz-pz*ty), vy + s*(pz*tx-px*tz), vz + s*(px*ty-py*tx)
+}
+
+// ApplyPairForces applies the particles' mutual Coulomb and gravitational forces over the time step dt,
+// changing the velocity of every movable particle. The forces are the gradients of
+// analytics.CoulombPotentialEnergy and analytics.NBodyGravityPotential, so the Coulomb force
+// vanishes once two particles overlap, where that energy stops changing.
+func ApplyPairForces(particles []*particle.Particle, dt float64) {
+	for i := 0; i < len(particles); i++ {
+		for j := i + 1; j < len(particles); j++ {
+			p1, p2 := particles[i], particles[j]
+			dx := p2.X - p1.X
+			dy := p2.Y - p1.Y
+			dist := math.Hypot(dx, dy)
+			if dist < 1e-5 {
+				continue
+			}
+
+			// Positive magnitudes pull the particles together
+			magnitude := constants.GravitationalConstant * p1.Mass * p2.Mass / (dist * dist)
+			if dist > p1.Radius+p2.Radius {
+				magnitude -= constants.CoulombsConstant * p1.Charge * p2.Charge / (dist * dist)
+			}
+			fx := magnitude * dx / dist
+			fy := magnitude * dy / dist
+
+			if p1.Movable && p1.Mass != 0 {
+				p1.Vx += fx / p1.Mass * dt
+				p1.Vy += fy / p1.Mass * dt
+			}
+			if p2.Movable && p2.Mass != 0 {
+				p2.Vx -= fx / p2.Mass * dt
+				p2.Vy -= fy / p2.Mass * dt
+			}
+		}
+	}
+}
+
 func ApplyElectrostaticForces(particles []*particle.Particle) {
 	for i := range particles {
 		totalFx, totalFy := 0.0, 0.0
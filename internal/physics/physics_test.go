@@ -5,6 +5,7 @@ import (
 	"testing"
 	"particle-physics-simulator/internal/particle"
 	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/force"
 )
 
 func TestApplyGravity(t *testing.T) {
@@ -175,7 +176,7 @@ func TestUpdatePosition(t *testing.T) {
 				Y:          10,
 				Z:          0,
 				Vx:         1.0,
-				Vy:         2.0,
+				Vy:         0.0, // Landing stops the vertical motion
 				Vz:         3.0,
 			},
 			dt: 0.5,
@@ -216,11 +217,9 @@ func TestApplyMagneticForces(t *testing.T) {
 		},
 	}
 
-	magneticFieldX := 1.0
-	magneticFieldY := 0.0
-	magneticFieldZ := 0.0
+	magneticField := force.MagneticField{Strength: 1.0, Direction: 1}
 
-	ApplyMagneticForces(particles, magneticFieldX, magneticFieldY, magneticFieldZ)
+	ApplyMagneticForces(particles, magneticField, 0)
 
 	// The magnetic force should be perpendicular to both velocity and magnetic field
 	if particles[0].Ax == 0 && particles[0].Ay == 0 && particles[0].Az == 0 {
@@ -254,3 +253,25 @@ func TestApplyElectrostaticForces(t *testing.T) {
 		t.Error("ApplyElectrostaticForces() did not create attractive force between opposite charges")
 	}
 }
+
+func TestApplyExternalFieldsKeepsSpeed(t *testing.T) {
+	// A charge gyrating in a uniform Bz: r = mv/(qB) = 25, with a period of 2πm/(qB) = π seconds
+	p := &particle.Particle{Vx: 50, Mass: 1, Charge: 1, Movable: true}
+	fields := force.ExternalFields{
+		Magnetic: []force.MagneticFieldSource{force.MagneticField{Strength: 2, Direction: 1}},
+	}
+	dt := 1.0 / 120
+	speed := math.Hypot(p.Vx, p.Vy)
+
+	for i := 0; i < 20*377; i++ {
+		ApplyExternalFields([]*particle.Particle{p}, fields, float64(i)*dt, dt)
+		UpdatePosition(p, dt)
+
+		if got := math.Hypot(p.Vx, p.Vy); math.Abs(got-speed) > 1e-9*speed {
+			t.Fatalf("step %d: speed = %v, want %v", i, got, speed)
+		}
+		if r := math.Hypot(p.X, p.Y+25); math.Abs(r-25) > 0.25 {
+			t.Fatalf("step %d: orbit radius = %v, want 25", i, r)
+		}
+	}
+}
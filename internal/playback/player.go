@@ -0,0 +1,144 @@
+// Package playback steps through a recorded trajectory in time, independently of any window.
+package playback
+
+import (
+	"particle-physics-simulator/internal/trajectory"
+	"sort"
+)
+
+const (
+	MinSpeed = 1.0 / 64
+	MaxSpeed = 64.0
+)
+
+// Player tracks the playback position in a trajectory. Time moves with the recorded
+// simulation time, so frames recorded with an uneven stride still play at a steady rate.
+type Player struct {
+	Source  trajectory.Source
+	Playing bool
+	Speed   float64 // Simulation seconds played per real second
+
+	time  float64
+	index int
+
+	current      trajectory.Frame
+	currentIndex int // Index of current, or -1 before the first frame is decoded
+}
+
+// NewPlayer creates a paused player at the first frame, playing at normal speed.
+func NewPlayer(src trajectory.Source) *Player {
+	p := &Player{Source: src, Speed: 1, currentIndex: -1}
+	if src.NumFrames() > 0 {
+		_, p.time = src.FrameInfo(0)
+	}
+	return p
+}
+
+// Index returns the number of the frame shown at the current time.
+func (p *Player) Index() int {
+	return p.index
+}
+
+// Time returns the current playback time in simulation seconds.
+func (p *Player) Time() float64 {
+	return p.time
+}
+
+// Range returns the times of the first and last frames.
+func (p *Player) Range() (start, end float64) {
+	n := p.Source.NumFrames()
+	if n == 0 {
+		return 0, 0
+	}
+	_, start = p.Source.FrameInfo(0)
+	_, end = p.Source.FrameInfo(n - 1)
+	return start, end
+}
+
+// Progress returns how far through the trajectory the current time is, from 0 to 1.
+func (p *Player) Progress() float64 {
+	start, end := p.Range()
+	if end <= start {
+		return 0
+	}
+	return (p.time - start) / (end - start)
+}
+
+// Advance moves the playback time on by realDt seconds of wall-clock time while playing,
+// stopping at the last frame.
+func (p *Player) Advance(realDt float64) {
+	if !p.Playing {
+		return
+	}
+	_, end := p.Range()
+	p.SeekTime(p.time + realDt*p.Speed)
+	if p.time >= end {
+		p.Playing = false
+	}
+}
+
+// SeekTime moves to the given time, clamped to the recorded range.
+func (p *Player) SeekTime(t float64) {
+	n := p.Source.NumFrames()
+	if n == 0 {
+		return
+	}
+	start, end := p.Range()
+	p.time = min(max(t, start), end)
+
+	// The last frame recorded at or before the current time
+	p.index = sort.Search(n, func(i int) bool {
+		_, frameTime := p.Source.FrameInfo(i)
+		return frameTime > p.time
+	}) - 1
+	p.index = max(p.index, 0)
+}
+
+// SeekProgress moves to a fraction of the way through the trajectory, e.g. from a
+// position on a timeline.
+func (p *Player) SeekProgress(fraction float64) {
+	start, end := p.Range()
+	p.SeekTime(start + fraction*(end-start))
+}
+
+// StepFrames pauses and moves n frames forward, or backwards for negative n.
+func (p *Player) StepFrames(n int) {
+	p.Playing = false
+	p.SeekFrame(p.index + n)
+}
+
+// SeekFrame moves to frame i, clamped to the recorded frames.
+func (p *Player) SeekFrame(i int) {
+	count := p.Source.NumFrames()
+	if count == 0 {
+		return
+	}
+	p.index = min(max(i, 0), count-1)
+	_, p.time = p.Source.FrameInfo(p.index)
+}
+
+// SetSpeed sets the playback speed, clamped to [MinSpeed, MaxSpeed].
+func (p *Player) SetSpeed(speed float64) {
+	p.Speed = min(max(speed, MinSpeed), MaxSpeed)
+}
+
+// TogglePlaying starts or pauses playback, restarting from the beginning at the end.
+func (p *Player) TogglePlaying() {
+	if !p.Playing && p.index == p.Source.NumFrames()-1 {
+		p.SeekFrame(0)
+	}
+	p.Playing = !p.Playing
+}
+
+// Current returns the frame at the current time. Frames are decoded only when the
+// index changes, so it is cheap to call every draw.
+func (p *Player) Current() (trajectory.Frame, error) {
+	if p.currentIndex != p.index {
+		f, err := p.Source.Frame(p.index)
+		if err != nil {
+			return trajectory.Frame{}, err
+		}
+		p.current, p.currentIndex = f, p.index
+	}
+	return p.current, nil
+}
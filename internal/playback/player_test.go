@@ -0,0 +1,122 @@
+package playback
+
+import (
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/trajectory"
+	"testing"
+)
+
+// testSource has frames at 0, 1, 2, 4 and 8 seconds, with one particle at x = frame time.
+func testSource() trajectory.FrameList {
+	var frames trajectory.FrameList
+	for i, time := range []float64{0, 1, 2, 4, 8} {
+		frames = append(frames, trajectory.Frame{
+			Step:      i * 10,
+			Time:      time,
+			Particles: []*particle.Particle{{X: time}},
+		})
+	}
+	return frames
+}
+
+func TestAdvance(t *testing.T) {
+	p := NewPlayer(testSource())
+	p.Advance(1)
+	if p.Time() != 0 {
+		t.Errorf("paused player advanced to %v", p.Time())
+	}
+
+	p.Playing = true
+	p.SetSpeed(2)
+	p.Advance(1.5)
+	if p.Time() != 3 || p.Index() != 2 {
+		t.Errorf("after 1.5 s at 2x: time %v frame %d, want 3 and frame 2", p.Time(), p.Index())
+	}
+
+	p.Advance(10)
+	if p.Time() != 8 || p.Index() != 4 || p.Playing {
+		t.Errorf("at the end: time %v frame %d playing %v, want 8, frame 4, stopped", p.Time(), p.Index(), p.Playing)
+	}
+
+	// Playing again from the end starts over
+	p.TogglePlaying()
+	if !p.Playing || p.Index() != 0 {
+		t.Errorf("TogglePlaying() at the end: playing %v frame %d", p.Playing, p.Index())
+	}
+}
+
+func TestSeek(t *testing.T) {
+	p := NewPlayer(testSource())
+
+	tests := []struct {
+		fraction  float64
+		wantTime  float64
+		wantIndex int
+	}{
+		{0, 0, 0},
+		{0.5, 4, 3},
+		{0.49, 3.92, 2},
+		{1, 8, 4},
+		{-1, 0, 0},
+		{2, 8, 4},
+	}
+	for _, tt := range tests {
+		p.SeekProgress(tt.fraction)
+		if math.Abs(p.Time()-tt.wantTime) > 1e-9 || p.Index() != tt.wantIndex {
+			t.Errorf("SeekProgress(%v): time %v frame %d, want %v and frame %d", tt.fraction, p.Time(), p.Index(), tt.wantTime, tt.wantIndex)
+		}
+	}
+	p.SeekProgress(0.25)
+	if p.Progress() != 0.25 {
+		t.Errorf("Progress() = %v, want 0.25", p.Progress())
+	}
+}
+
+func TestStepFrames(t *testing.T) {
+	p := NewPlayer(testSource())
+	p.Playing = true
+	p.StepFrames(3)
+	if p.Playing || p.Index() != 3 || p.Time() != 4 {
+		t.Errorf("StepFrames(3): playing %v frame %d time %v", p.Playing, p.Index(), p.Time())
+	}
+	p.StepFrames(-10)
+	if p.Index() != 0 {
+		t.Errorf("StepFrames(-10) went to frame %d, want 0", p.Index())
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	p := NewPlayer(testSource())
+	p.SeekFrame(3)
+	f, err := p.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Step != 30 || f.Particles[0].X != 4 {
+		t.Errorf("Current() = step %d x %v, want step 30 x 4", f.Step, f.Particles[0].X)
+	}
+}
+
+func TestSetSpeed(t *testing.T) {
+	p := NewPlayer(testSource())
+	p.SetSpeed(1000)
+	if p.Speed != MaxSpeed {
+		t.Errorf("Speed = %v, want %v", p.Speed, MaxSpeed)
+	}
+	p.SetSpeed(0)
+	if p.Speed != MinSpeed {
+		t.Errorf("Speed = %v, want %v", p.Speed, MinSpeed)
+	}
+}
+
+func TestEmptySource(t *testing.T) {
+	p := NewPlayer(trajectory.FrameList(nil))
+	p.Playing = true
+	p.Advance(1)
+	p.SeekProgress(0.5)
+	p.StepFrames(1)
+	if p.Time() != 0 || p.Index() != 0 || p.Progress() != 0 {
+		t.Errorf("empty player moved: time %v frame %d", p.Time(), p.Index())
+	}
+}
@@ -0,0 +1,188 @@
+// Package raster draws the simulation into images with the standard image package, for
+// servers without a GPU or display.
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Canvas is an image drawn in world coordinates. Shapes are anti-aliased by their pixel
+// coverage and blended over what is already drawn.
+type Canvas struct {
+	Image *image.RGBA
+	Scale float64 // Image pixels per world pixel
+}
+
+// NewCanvas creates a canvas for a width x height world drawn at the given scale.
+func NewCanvas(width, height, scale float64) *Canvas {
+	if scale <= 0 {
+		scale = 1
+	}
+	w := int(math.Ceil(width * scale))
+	h := int(math.Ceil(height * scale))
+	return &Canvas{Image: image.NewRGBA(image.Rect(0, 0, w, h)), Scale: scale}
+}
+
+// Clear fills the whole canvas with c, replacing what was drawn.
+func (cv *Canvas) Clear(c color.RGBA) {
+	pix := cv.Image.Pix
+	for i := 0; i < len(pix); i += 4 {
+		pix[i], pix[i+1], pix[i+2], pix[i+3] = c.R, c.G, c.B, c.A
+	}
+}
+
+// FillCircle draws a filled circle.
+func (cv *Canvas) FillCircle(x, y, radius float64, c color.RGBA) {
+	x, y, radius = x*cv.Scale, y*cv.Scale, radius*cv.Scale
+	cv.cover(x-radius, y-radius, x+radius, y+radius, c, func(px, py float64) float64 {
+		return radius + 0.5 - math.Hypot(px-x, py-y)
+	})
+}
+
+// StrokeCircle draws the outline of a circle.
+func (cv *Canvas) StrokeCircle(x, y, radius, width float64, c color.RGBA) {
+	x, y, radius, width = x*cv.Scale, y*cv.Scale, radius*cv.Scale, width*cv.Scale
+	outer := radius + width/2
+	cv.cover(x-outer, y-outer, x+outer, y+outer, c, func(px, py float64) float64 {
+		return width/2 + 0.5 - math.Abs(math.Hypot(px-x, py-y)-radius)
+	})
+}
+
+// Line draws a line of the given width with rounded ends.
+func (cv *Canvas) Line(x0, y0, x1, y1, width float64, c color.RGBA) {
+	x0, y0, x1, y1, width = x0*cv.Scale, y0*cv.Scale, x1*cv.Scale, y1*cv.Scale, width*cv.Scale
+	half := width / 2
+	// Thin lines still cover at least a pixel's width so they stay visible
+	if half < 0.5 {
+		half = 0.5
+	}
+	dx, dy := x1-x0, y1-y0
+	lengthSq := dx*dx + dy*dy
+	cv.cover(math.Min(x0, x1)-half, math.Min(y0, y1)-half, math.Max(x0, x1)+half, math.Max(y0, y1)+half, c,
+		func(px, py float64) float64 {
+			// Distance from the pixel centre to the nearest point of the segment
+			t := 0.0
+			if lengthSq > 0 {
+				t = math.Max(0, math.Min(1, ((px-x0)*dx+(py-y0)*dy)/lengthSq))
+			}
+			return half + 0.5 - math.Hypot(px-(x0+t*dx), py-(y0+t*dy))
+		})
+}
+
+// Polyline draws connected line segments through the points.
+func (cv *Canvas) Polyline(xs, ys []float64, width float64, c color.RGBA) {
+	for i := 1; i < len(xs) && i < len(ys); i++ {
+		cv.Line(xs[i-1], ys[i-1], xs[i], ys[i], width, c)
+	}
+}
+
+// Arrow draws an arrow centred on (x, y) with the given extent and a two-line head.
+func (cv *Canvas) Arrow(x, y, dx, dy, width float64, c color.RGBA) {
+	tipX, tipY := x+dx, y+dy
+	cv.Line(x-dx, y-dy, tipX, tipY, width, c)
+
+	headLength := 0.4 * math.Hypot(dx, dy)
+	angle := math.Atan2(dy, dx)
+	for _, side := range []float64{-1, 1} {
+		a := angle + math.Pi - side*math.Pi/6
+		cv.Line(tipX, tipY, tipX+headLength*math.Cos(a), tipY+headLength*math.Sin(a), width, c)
+	}
+}
+
+// FillRect draws a filled axis-aligned rectangle.
+func (cv *Canvas) FillRect(x, y, width, height float64, c color.RGBA) {
+	x0, y0 := x*cv.Scale, y*cv.Scale
+	x1, y1 := (x+width)*cv.Scale, (y+height)*cv.Scale
+	cv.cover(x0, y0, x1, y1, c, func(px, py float64) float64 {
+		// Overlap of the pixel with the rectangle along each axis
+		cx := math.Min(px+0.5, x1) - math.Max(px-0.5, x0)
+		cy := math.Min(py+0.5, y1) - math.Max(py-0.5, y0)
+		return math.Min(cx, 1) * math.Min(cy, 1)
+	})
+}
+
+// StrokeRect draws the outline of an axis-aligned rectangle.
+func (cv *Canvas) StrokeRect(x, y, width, height, lineWidth float64, c color.RGBA) {
+	cv.Line(x, y, x+width, y, lineWidth, c)
+	cv.Line(x+width, y, x+width, y+height, lineWidth, c)
+	cv.Line(x+width, y+height, x, y+height, lineWidth, c)
+	cv.Line(x, y+height, x, y, lineWidth, c)
+}
+
+// FillPolygon fills the polygon through the points, which may be concave, with its edges
+// anti-aliased over about a pixel.
+func (cv *Canvas) FillPolygon(xs, ys []float64, c color.RGBA) {
+	n := min(len(xs), len(ys))
+	if n < 3 {
+		return
+	}
+	px := make([]float64, n)
+	py := make([]float64, n)
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := 0; i < n; i++ {
+		px[i], py[i] = xs[i]*cv.Scale, ys[i]*cv.Scale
+		minX, maxX = math.Min(minX, px[i]), math.Max(maxX, px[i])
+		minY, maxY = math.Min(minY, py[i]), math.Max(maxY, py[i])
+	}
+
+	cv.cover(minX, minY, maxX, maxY, c, func(x, y float64) float64 {
+		// Even-odd inside test and distance to the nearest edge
+		inside := false
+		dist := math.Inf(1)
+		for i, j := 0, n-1; i < n; j, i = i, i+1 {
+			if (py[i] > y) != (py[j] > y) && x < px[i]+(y-py[i])*(px[j]-px[i])/(py[j]-py[i]) {
+				inside = !inside
+			}
+			dx, dy := px[j]-px[i], py[j]-py[i]
+			t := 0.0
+			if lengthSq := dx*dx + dy*dy; lengthSq > 0 {
+				t = math.Max(0, math.Min(1, ((x-px[i])*dx+(y-py[i])*dy)/lengthSq))
+			}
+			dist = math.Min(dist, math.Hypot(x-(px[i]+t*dx), y-(py[i]+t*dy)))
+		}
+		if inside {
+			return 0.5 + dist
+		}
+		return 0.5 - dist
+	})
+}
+
+// cover blends c into every pixel of the bounding box (in image coordinates) with the
+// coverage returned for the pixel centre, clamped to [0, 1].
+func (cv *Canvas) cover(minX, minY, maxX, maxY float64, c color.RGBA, coverage func(px, py float64) float64) {
+	if c.A == 0 {
+		return
+	}
+	bounds := cv.Image.Bounds()
+	x0 := max(int(math.Floor(minX)), bounds.Min.X)
+	y0 := max(int(math.Floor(minY)), bounds.Min.Y)
+	x1 := min(int(math.Ceil(maxX)), bounds.Max.X-1)
+	y1 := min(int(math.Ceil(maxY)), bounds.Max.Y-1)
+
+	for py := y0; py <= y1; py++ {
+		for px := x0; px <= x1; px++ {
+			a := coverage(float64(px)+0.5, float64(py)+0.5)
+			if a <= 0 {
+				continue
+			}
+			cv.blend(px, py, c, math.Min(a, 1))
+		}
+	}
+}
+
+// blend draws c over the pixel at (x, y) with the given coverage.
+func (cv *Canvas) blend(x, y int, c color.RGBA, coverage float64) {
+	i := cv.Image.PixOffset(x, y)
+	pix := cv.Image.Pix[i : i+4 : i+4]
+
+	// image.RGBA holds premultiplied alpha
+	alpha := float64(c.A) / 255 * coverage
+	keep := 1 - alpha
+	pix[0] = uint8(float64(c.R)*alpha + float64(pix[0])*keep + 0.5)
+	pix[1] = uint8(float64(c.G)*alpha + float64(pix[1])*keep + 0.5)
+	pix[2] = uint8(float64(c.B)*alpha + float64(pix[2])*keep + 0.5)
+	pix[3] = uint8(255*alpha + float64(pix[3])*keep + 0.5)
+}
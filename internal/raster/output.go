@@ -0,0 +1,106 @@
+package raster
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// PNGSequence writes images as numbered PNG files, e.g. frame_00000.png, frame_00001.png.
+type PNGSequence struct {
+	Dir    string
+	Prefix string // Defaults to "frame_"
+
+	count int
+}
+
+// NewPNGSequence creates the output directory if needed.
+func NewPNGSequence(dir, prefix string) (*PNGSequence, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if prefix == "" {
+		prefix = "frame_"
+	}
+	return &PNGSequence{Dir: dir, Prefix: prefix}, nil
+}
+
+// WriteFrame writes the next numbered file.
+func (s *PNGSequence) WriteFrame(img image.Image) error {
+	path := filepath.Join(s.Dir, fmt.Sprintf("%s%05d.png", s.Prefix, s.count))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	s.count++
+	return f.Close()
+}
+
+// Count returns the number of frames written.
+func (s *PNGSequence) Count() int {
+	return s.count
+}
+
+// GIFAnimation collects images into an animated GIF. Frames are reduced to a fixed
+// 256-colour palette as they are added; the whole animation is held in memory until
+// Encode, so long runs should be drawn at a reduced canvas scale.
+type GIFAnimation struct {
+	Delay  int  // Time between frames in hundredths of a second
+	Dither bool // Use Floyd-Steinberg dithering instead of the nearest palette colour
+
+	anim gif.GIF
+}
+
+// NewGIFAnimation creates an animation showing each frame for delay hundredths of a second.
+func NewGIFAnimation(delay int) *GIFAnimation {
+	return &GIFAnimation{Delay: delay}
+}
+
+// AddFrame appends a copy of img to the animation.
+func (a *GIFAnimation) AddFrame(img image.Image) {
+	bounds := img.Bounds()
+	frame := image.NewPaletted(bounds, palette.Plan9)
+	if a.Dither {
+		draw.FloydSteinberg.Draw(frame, bounds, img, bounds.Min)
+	} else {
+		draw.Draw(frame, bounds, img, bounds.Min, draw.Src)
+	}
+	a.anim.Image = append(a.anim.Image, frame)
+	a.anim.Delay = append(a.anim.Delay, a.Delay)
+}
+
+// Len returns the number of frames added.
+func (a *GIFAnimation) Len() int {
+	return len(a.anim.Image)
+}
+
+// Encode writes the animation, looping forever.
+func (a *GIFAnimation) Encode(w io.Writer) error {
+	if len(a.anim.Image) == 0 {
+		return fmt.Errorf("raster: animation has no frames")
+	}
+	return gif.EncodeAll(w, &a.anim)
+}
+
+// WriteFile writes the animation to a file.
+func (a *GIFAnimation) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := a.Encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,217 @@
+package raster
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/renderer"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	black = color.RGBA{A: 255}
+	red   = color.RGBA{R: 255, A: 255}
+)
+
+func TestFillCircle(t *testing.T) {
+	cv := NewCanvas(40, 40, 1)
+	cv.Clear(black)
+	cv.FillCircle(20, 20, 8, red)
+
+	if got := cv.Image.RGBAAt(20, 20); got != red {
+		t.Errorf("centre = %v, want %v", got, red)
+	}
+	if got := cv.Image.RGBAAt(20, 31); got != black {
+		t.Errorf("outside = %v, want %v", got, black)
+	}
+	// The edge pixel is only partly covered
+	if got := cv.Image.RGBAAt(27, 20); got.R == 0 || got.R == 255 {
+		t.Errorf("edge = %v, want a blend", got)
+	}
+}
+
+func TestScale(t *testing.T) {
+	cv := NewCanvas(100, 50, 0.5)
+	if b := cv.Image.Bounds(); b.Dx() != 50 || b.Dy() != 25 {
+		t.Fatalf("image is %dx%d, want 50x25", b.Dx(), b.Dy())
+	}
+	cv.Clear(black)
+	cv.FillCircle(80, 40, 4, red)
+	if got := cv.Image.RGBAAt(40, 20); got != red {
+		t.Errorf("scaled centre = %v, want %v", got, red)
+	}
+}
+
+func TestLineAndBlending(t *testing.T) {
+	cv := NewCanvas(20, 20, 1)
+	cv.Clear(black)
+	cv.Line(2, 10, 18, 10, 2, color.RGBA{R: 255, A: 128})
+
+	got := cv.Image.RGBAAt(10, 10)
+	if got.R < 120 || got.R > 135 || got.A != 255 {
+		t.Errorf("half transparent line = %v, want red about 128 over opaque black", got)
+	}
+	if got := cv.Image.RGBAAt(10, 14); got != black {
+		t.Errorf("pixel off the line = %v", got)
+	}
+}
+
+func TestFillRect(t *testing.T) {
+	cv := NewCanvas(10, 10, 1)
+	cv.Clear(black)
+	cv.FillRect(2, 2, 3, 3, red)
+	if got := cv.Image.RGBAAt(3, 3); got != red {
+		t.Errorf("inside = %v", got)
+	}
+	if got := cv.Image.RGBAAt(5, 3); got != black {
+		t.Errorf("right of the rectangle = %v", got)
+	}
+}
+
+func TestFillPolygon(t *testing.T) {
+	cv := NewCanvas(20, 20, 1)
+	cv.Clear(black)
+	// An L shape, to check concave polygons
+	cv.FillPolygon([]float64{2, 18, 18, 10, 10, 2}, []float64{2, 2, 18, 18, 10, 10}, red)
+
+	for _, p := range [][2]int{{5, 5}, {14, 14}} {
+		if got := cv.Image.RGBAAt(p[0], p[1]); got != red {
+			t.Errorf("inside at %v = %v, want %v", p, got, red)
+		}
+	}
+	if got := cv.Image.RGBAAt(5, 14); got != black {
+		t.Errorf("in the notch = %v, want %v", got, black)
+	}
+}
+
+func TestText(t *testing.T) {
+	cv := NewCanvas(40, 20, 1)
+	cv.Clear(black)
+	cv.Text("|", 0, 0, 10, red)
+
+	// The bar is the middle column of the glyph, from the second row of the cell
+	if got := cv.Image.RGBAAt(2, 4); got != red {
+		t.Errorf("bar = %v, want %v", got, red)
+	}
+	if got := cv.Image.RGBAAt(0, 4); got != black {
+		t.Errorf("left of the bar = %v, want %v", got, black)
+	}
+
+	if got := TextWidth("ab", 20); got != 22 {
+		t.Errorf("TextWidth = %v, want 22", got)
+	}
+}
+
+func TestRendererDrawScene(t *testing.T) {
+	particles := []*particle.Particle{
+		{X: 100, Y: 100, Radius: 10, Charge: 1, Color: particle.Color{G: 1, A: 1}},
+		{X: 300, Y: 150, Radius: 10, Charge: -1, Color: particle.Color{B: 1, A: 1}},
+	}
+	r := NewRenderer(400, 200, 1)
+	var frames []*image.RGBA
+	r.OnFrame = func(img *image.RGBA) error {
+		frames = append(frames, img)
+		return nil
+	}
+
+	r.BeginFrame(black)
+	renderer.DrawScene(r, renderer.Scene{
+		Particles: particles,
+		Width:     400,
+		Height:    200,
+		Overlays:  renderer.Overlays{ElectricField: true, FieldLines: true, Potential: true},
+		Walls:     true,
+	})
+	r.EndFrame()
+
+	if len(frames) != 1 || r.Frames() != 1 {
+		t.Fatalf("OnFrame called %d times after %d frames, want 1", len(frames), r.Frames())
+	}
+	img := frames[0]
+	if got := img.RGBAAt(100, 100); got != (color.RGBA{G: 255, A: 255}) {
+		t.Errorf("particle centre = %v, want green", got)
+	}
+	if got := img.RGBAAt(0, 100); got.R < 100 || got.R != got.G || got.G != got.B {
+		t.Errorf("wall = %v, want grey", got)
+	}
+	// The heatmap is reddish near the positive charge
+	if got := img.RGBAAt(100, 125); got.R <= got.B {
+		t.Errorf("potential near the positive charge = %v, want red", got)
+	}
+}
+
+func TestRendererError(t *testing.T) {
+	r := NewRenderer(10, 10, 1)
+	r.OnFrame = func(*image.RGBA) error { return errors.New("disk full") }
+	if r.ShouldClose() {
+		t.Fatal("ShouldClose before any frame")
+	}
+	r.BeginFrame(black)
+	r.EndFrame()
+	if !r.ShouldClose() || r.Close() == nil {
+		t.Error("OnFrame error not reported")
+	}
+}
+
+func TestPNGSequence(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "frames")
+	seq, err := NewPNGSequence(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cv := NewCanvas(8, 8, 1)
+	for i := 0; i < 3; i++ {
+		if err := seq.WriteFrame(cv.Image); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(dir, "frame_00002.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := png.Decode(f); err != nil {
+		t.Errorf("frame_00002.png: %v", err)
+	}
+	if seq.Count() != 3 {
+		t.Errorf("Count() = %d, want 3", seq.Count())
+	}
+}
+
+func TestGIFAnimation(t *testing.T) {
+	anim := NewGIFAnimation(4)
+	var buf bytes.Buffer
+	if err := anim.Encode(&buf); err == nil {
+		t.Error("encoding an empty animation succeeded")
+	}
+
+	cv := NewCanvas(16, 16, 1)
+	for _, c := range []color.RGBA{black, red} {
+		cv.Clear(c)
+		anim.AddFrame(cv.Image)
+	}
+	anim.Dither = true
+	anim.AddFrame(cv.Image)
+	if err := anim.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Image) != 3 || decoded.Delay[0] != 4 {
+		t.Fatalf("decoded %d frames with delay %v", len(decoded.Image), decoded.Delay)
+	}
+	r, g, b, _ := decoded.Image[1].At(8, 8).RGBA()
+	if r>>8 < 200 || g>>8 > 50 || b>>8 > 50 {
+		t.Errorf("second frame = %d %d %d, want red", r>>8, g>>8, b>>8)
+	}
+}
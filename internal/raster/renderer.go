@@ -0,0 +1,100 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"particle-physics-simulator/internal/renderer"
+)
+
+// Renderer is the image backend: each frame is drawn into a canvas and handed to OnFrame
+// when it ends. It has no input and never asks to close on its own.
+type Renderer struct {
+	Canvas  *Canvas
+	OnFrame func(img *image.RGBA) error // Called with the finished frame, or nil
+
+	width, height int
+	frames        int
+	err           error
+	xs, ys        []float64
+}
+
+// NewRenderer creates an image backend for a width x height area drawn at the given scale.
+func NewRenderer(width, height int, scale float64) *Renderer {
+	return &Renderer{
+		Canvas: NewCanvas(float64(width), float64(height), scale),
+		width:  width,
+		height: height,
+	}
+}
+
+// Frames returns the number of completed frames.
+func (r *Renderer) Frames() int {
+	return r.frames
+}
+
+// Err returns the first error returned by OnFrame.
+func (r *Renderer) Err() error {
+	return r.err
+}
+
+func (r *Renderer) BeginFrame(background color.RGBA) {
+	r.Canvas.Clear(background)
+}
+
+func (r *Renderer) EndFrame() {
+	r.frames++
+	if r.OnFrame != nil && r.err == nil {
+		r.err = r.OnFrame(r.Canvas.Image)
+	}
+}
+
+func (r *Renderer) Size() (int, int) {
+	return r.width, r.height
+}
+
+func (r *Renderer) Circle(x, y, radius float64, c color.RGBA) {
+	r.Canvas.FillCircle(x, y, radius, c)
+}
+
+func (r *Renderer) Line(x0, y0, x1, y1, width float64, c color.RGBA) {
+	r.Canvas.Line(x0, y0, x1, y1, width, c)
+}
+
+func (r *Renderer) Polygon(points []renderer.Point, c color.RGBA) {
+	r.xs, r.ys = r.xs[:0], r.ys[:0]
+	for _, p := range points {
+		r.xs = append(r.xs, p.X)
+		r.ys = append(r.ys, p.Y)
+	}
+	r.Canvas.FillPolygon(r.xs, r.ys, c)
+}
+
+func (r *Renderer) Rect(x, y, width, height float64, c color.RGBA) {
+	r.Canvas.FillRect(x, y, width, height, c)
+}
+
+func (r *Renderer) Text(s string, x, y, size float64, c color.RGBA) {
+	r.Canvas.Text(s, x, y, size, c)
+}
+
+func (r *Renderer) TextWidth(s string, size float64) float64 {
+	return TextWidth(s, size)
+}
+
+func (r *Renderer) Input() renderer.Input {
+	return renderer.Input{}
+}
+
+func (r *Renderer) FPS() int {
+	return 0
+}
+
+// ShouldClose reports true once OnFrame has failed.
+func (r *Renderer) ShouldClose() bool {
+	return r.err != nil
+}
+
+// Close returns the first error returned by OnFrame.
+func (r *Renderer) Close() error {
+	return r.err
+}
@@ -0,0 +1,74 @@
+package renderer
+
+import "image/color"
+
+// Renderer is a drawing backend. Coordinates are in pixels of the simulated area with the
+// origin at the top left, the same as the particle positions.
+//
+// The raylib window is one backend; the null, image and SVG backends draw the same frames
+// without a display so the simulation loop can run in tests and on servers.
+type Renderer interface {
+	// BeginFrame starts a frame cleared to the background colour.
+	BeginFrame(background color.RGBA)
+	// EndFrame finishes the frame, presenting or writing it out.
+	EndFrame()
+	// Size returns the drawable width and height.
+	Size() (width, height int)
+
+	Circle(x, y, radius float64, c color.RGBA)
+	Line(x0, y0, x1, y1, width float64, c color.RGBA)
+	// Polygon fills a convex polygon.
+	Polygon(points []Point, c color.RGBA)
+	Rect(x, y, width, height float64, c color.RGBA)
+	// Text draws s with its top left corner at (x, y), size pixels tall.
+	Text(s string, x, y, size float64, c color.RGBA)
+	// TextWidth returns the width Text would draw s at.
+	TextWidth(s string, size float64) float64
+
+	// Input returns the input for the current frame.
+	Input() Input
+	// FPS returns the measured frame rate, or zero if the backend doesn't keep time.
+	FPS() int
+	// ShouldClose reports whether the user or the backend has asked to stop.
+	ShouldClose() bool
+	Close() error
+}
+
+// Window is implemented by backends that show a window the user can close, maximise and
+// minimise with the buttons drawn by DrawWindowButtons.
+type Window interface {
+	RequestClose()
+	ToggleMaximized()
+	Minimize()
+}
+
+// Point is a position in pixels.
+type Point struct {
+	X, Y float64
+}
+
+// Rectangle is an axis-aligned area in pixels.
+type Rectangle struct {
+	X, Y, Width, Height float64
+}
+
+// Contains reports whether (x, y) lies inside the rectangle.
+func (r Rectangle) Contains(x, y float64) bool {
+	return x >= r.X && x <= r.X+r.Width && y >= r.Y && y <= r.Y+r.Height
+}
+
+// Colours used by the interface, matching raylib's palette.
+var (
+	black    = color.RGBA{A: 255}
+	white    = color.RGBA{R: 245, G: 245, B: 245, A: 255}
+	gray     = color.RGBA{R: 130, G: 130, B: 130, A: 255}
+	darkGray = color.RGBA{R: 80, G: 80, B: 80, A: 255}
+	yellow   = color.RGBA{R: 253, G: 249, B: 0, A: 255}
+	red      = color.RGBA{R: 230, G: 41, B: 55, A: 255}
+	skyBlue  = color.RGBA{R: 102, G: 191, B: 255, A: 255}
+
+	selectionColor = color.RGBA{R: 255, G: 255, B: 255, A: 200}
+)
+
+// Background is the colour frames are cleared to.
+var Background = black
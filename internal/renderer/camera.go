@@ -0,0 +1,194 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Zoom limits of the camera.
+const (
+	MinZoom = 0.05
+	MaxZoom = 20.0
+)
+
+// Follow selects what the camera keeps in the centre of the screen.
+type Follow int
+
+const (
+	FollowNone         Follow = iota
+	FollowSelected            // The selected particle
+	FollowCenterOfMass        // The centre of mass of the movable particles
+)
+
+func (f Follow) String() string {
+	switch f {
+	case FollowNone:
+		return "none"
+	case FollowSelected:
+		return "selected particle"
+	case FollowCenterOfMass:
+		return "centre of mass"
+	}
+	return fmt.Sprintf("Follow(%d)", int(f))
+}
+
+// Camera maps world coordinates to the screen: the world point (X, Y) is drawn at the top
+// left corner, magnified by Zoom.
+type Camera struct {
+	X, Y   float64
+	Zoom   float64
+	Follow Follow
+
+	home view // The view Reset returns to
+}
+
+type view struct {
+	x, y, zoom float64
+}
+
+// NewCamera returns a camera showing the world unmagnified from the origin, where world and
+// screen coordinates are the same.
+func NewCamera() *Camera {
+	return &Camera{Zoom: 1, home: view{zoom: 1}}
+}
+
+// Reset returns to the home view, unmagnified from the origin unless Fit has set another,
+// and stops following.
+func (c *Camera) Reset() {
+	c.X, c.Y, c.Zoom = c.home.x, c.home.y, c.home.zoom
+	c.Follow = FollowNone
+}
+
+// AtHome reports whether the camera shows the view Reset returns to and follows nothing.
+func (c *Camera) AtHome() bool {
+	return c.X == c.home.x && c.Y == c.home.y && c.Zoom == c.home.zoom && c.Follow == FollowNone
+}
+
+// Fit makes the home view the one that shows the whole of a world of the given size as large
+// as the screen allows, centred. The current view is left alone.
+func (c *Camera) Fit(worldWidth, worldHeight, screenWidth, screenHeight float64) {
+	zoom := math.Max(MinZoom, math.Min(MaxZoom, math.Min(screenWidth/worldWidth, screenHeight/worldHeight)))
+	c.home = view{
+		x:    worldWidth/2 - screenWidth/2/zoom,
+		y:    worldHeight/2 - screenHeight/2/zoom,
+		zoom: zoom,
+	}
+}
+
+// Resize keeps the view when the screen changes size: the world point in the middle of the
+// old screen is in the middle of the new one, magnified as much more or less as keeps what
+// was shown in view.
+func (c *Camera) Resize(oldWidth, oldHeight, newWidth, newHeight float64) {
+	x, y := c.ScreenToWorld(oldWidth/2, oldHeight/2)
+	c.Zoom = math.Max(MinZoom, math.Min(MaxZoom, c.Zoom*math.Min(newWidth/oldWidth, newHeight/oldHeight)))
+	c.CenterOn(x, y, newWidth, newHeight)
+}
+
+// WorldToScreen converts a world position to screen pixels.
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	return (x - c.X) * c.Zoom, (y - c.Y) * c.Zoom
+}
+
+// ScreenToWorld converts a screen position to world coordinates.
+func (c *Camera) ScreenToWorld(x, y float64) (float64, float64) {
+	return x/c.Zoom + c.X, y/c.Zoom + c.Y
+}
+
+// ZoomAt multiplies the zoom by factor, within MinZoom and MaxZoom, keeping the world point
+// under the screen position (sx, sy) in place.
+func (c *Camera) ZoomAt(sx, sy, factor float64) {
+	wx, wy := c.ScreenToWorld(sx, sy)
+	c.Zoom = math.Max(MinZoom, math.Min(MaxZoom, c.Zoom*factor))
+	c.X, c.Y = wx-sx/c.Zoom, wy-sy/c.Zoom
+}
+
+// Pan moves the view by (dx, dy) screen pixels.
+func (c *Camera) Pan(dx, dy float64) {
+	c.X += dx / c.Zoom
+	c.Y += dy / c.Zoom
+}
+
+// CenterOn moves the view so the world point (x, y) is in the middle of a screen of the
+// given size.
+func (c *Camera) CenterOn(x, y, screenWidth, screenHeight float64) {
+	c.X = x - screenWidth/2/c.Zoom
+	c.Y = y - screenHeight/2/c.Zoom
+}
+
+// View is a renderer that draws in world coordinates through a camera onto another
+// renderer. Shapes scale with the zoom while text keeps its size on screen, and the mouse
+// position of its input is in world coordinates, so code that draws or picks particles works
+// the same at any zoom.
+type View struct {
+	Renderer
+	Camera *Camera
+
+	points []Point
+}
+
+// NewView draws through cam onto r.
+func NewView(r Renderer, cam *Camera) *View {
+	return &View{Renderer: r, Camera: cam}
+}
+
+func (v *View) Circle(x, y, radius float64, c color.RGBA) {
+	x, y = v.Camera.WorldToScreen(x, y)
+	v.Renderer.Circle(x, y, radius*v.Camera.Zoom, c)
+}
+
+func (v *View) Line(x0, y0, x1, y1, width float64, c color.RGBA) {
+	x0, y0 = v.Camera.WorldToScreen(x0, y0)
+	x1, y1 = v.Camera.WorldToScreen(x1, y1)
+	v.Renderer.Line(x0, y0, x1, y1, width*v.Camera.Zoom, c)
+}
+
+func (v *View) Polygon(points []Point, c color.RGBA) {
+	v.points = v.points[:0]
+	for _, p := range points {
+		x, y := v.Camera.WorldToScreen(p.X, p.Y)
+		v.points = append(v.points, Point{X: x, Y: y})
+	}
+	v.Renderer.Polygon(v.points, c)
+}
+
+func (v *View) Rect(x, y, width, height float64, c color.RGBA) {
+	x, y = v.Camera.WorldToScreen(x, y)
+	v.Renderer.Rect(x, y, width*v.Camera.Zoom, height*v.Camera.Zoom, c)
+}
+
+// Text draws s at the screen position of (x, y), at its size on screen.
+func (v *View) Text(s string, x, y, size float64, c color.RGBA) {
+	x, y = v.Camera.WorldToScreen(x, y)
+	v.Renderer.Text(s, x, y, size, c)
+}
+
+// TextWidth returns the width of the text in world units.
+func (v *View) TextWidth(s string, size float64) float64 {
+	return v.Renderer.TextWidth(s, size) / v.Camera.Zoom
+}
+
+// Input returns the input with the mouse position in world coordinates. Mouse movement and
+// the wheel stay in screen units.
+func (v *View) Input() Input {
+	in := v.Renderer.Input()
+	in.MouseX, in.MouseY = v.Camera.ScreenToWorld(in.MouseX, in.MouseY)
+	return in
+}
+
+// viewInstructions lists the camera controls under the other instructions.
+const viewInstructions = "View: [Wheel] Zoom | [Middle Drag] Pan | [0] Reset View | [C] Follow Selected/Centre of Mass"
+
+// DrawCameraInfo shows the zoom and what the camera follows, under the window buttons, when
+// the view isn't the home view.
+func DrawCameraInfo(r Renderer, cam *Camera) {
+	if cam.AtHome() {
+		return
+	}
+	info := fmt.Sprintf("View: %.2fx", cam.Zoom)
+	if cam.Follow != FollowNone {
+		info += ", following " + cam.Follow.String()
+	}
+	width, _ := r.Size()
+	r.Text(info, float64(width)-r.TextWidth(info, 20)-10, float64(buttonSize)+10, 20, white)
+}
@@ -0,0 +1,88 @@
+package renderer
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCameraZoomAt(t *testing.T) {
+	cam := NewCamera()
+	cam.ZoomAt(300, 200, 2)
+
+	// The point under the cursor stays put
+	if x, y := cam.ScreenToWorld(300, 200); math.Abs(x-300) > 1e-9 || math.Abs(y-200) > 1e-9 {
+		t.Errorf("point under the cursor moved to (%v, %v)", x, y)
+	}
+	if x, y := cam.WorldToScreen(400, 200); x != 500 || y != 200 {
+		t.Errorf("WorldToScreen(400, 200) = (%v, %v), want (500, 200)", x, y)
+	}
+
+	cam.ZoomAt(0, 0, 1e6)
+	if cam.Zoom != MaxZoom {
+		t.Errorf("Zoom = %v, want the limit %v", cam.Zoom, MaxZoom)
+	}
+	cam.Reset()
+	if *cam != *NewCamera() {
+		t.Errorf("Reset left %+v", *cam)
+	}
+}
+
+func TestCameraPanAndCenter(t *testing.T) {
+	cam := NewCamera()
+	cam.Zoom = 4
+	cam.Pan(40, -20)
+	if cam.X != 10 || cam.Y != -5 {
+		t.Errorf("after panning the camera is at (%v, %v), want (10, -5)", cam.X, cam.Y)
+	}
+
+	cam.CenterOn(100, 100, 800, 600)
+	if x, y := cam.WorldToScreen(100, 100); x != 400 || y != 300 {
+		t.Errorf("centred point is drawn at (%v, %v), want (400, 300)", x, y)
+	}
+}
+
+func TestViewInput(t *testing.T) {
+	n := NewNull(800, 600)
+	n.Frames = []Input{{MouseX: 100, MouseY: 50, MouseDX: 3, Wheel: 1}}
+	cam := NewCamera()
+	cam.Zoom = 2
+	cam.X, cam.Y = 10, 20
+
+	in := NewView(n, cam).Input()
+	if in.MouseX != 60 || in.MouseY != 45 {
+		t.Errorf("mouse in the world = (%v, %v), want (60, 45)", in.MouseX, in.MouseY)
+	}
+	if in.MouseDX != 3 || in.Wheel != 1 {
+		t.Error("mouse movement and wheel should stay in screen units")
+	}
+}
+
+func TestCameraFit(t *testing.T) {
+	cam := NewCamera()
+	cam.Fit(800, 600, 1600, 1000)
+	if cam.Zoom != 1 || cam.AtHome() {
+		t.Error("Fit should only change the home view")
+	}
+
+	cam.Reset()
+	if cam.Zoom != 1000.0/600 {
+		t.Errorf("Zoom = %v, want the height to fill the screen", cam.Zoom)
+	}
+	if x, y := cam.WorldToScreen(400, 300); math.Abs(x-800) > 1e-9 || math.Abs(y-500) > 1e-9 {
+		t.Errorf("world centre is drawn at (%v, %v), want the screen centre", x, y)
+	}
+}
+
+func TestCameraResize(t *testing.T) {
+	cam := NewCamera()
+	cam.Zoom = 2
+	cam.CenterOn(100, 50, 800, 600)
+	cam.Resize(800, 600, 1600, 900)
+
+	if cam.Zoom != 3 {
+		t.Errorf("Zoom = %v, want 3", cam.Zoom)
+	}
+	if x, y := cam.WorldToScreen(100, 50); x != 800 || y != 450 {
+		t.Errorf("centre of the view is drawn at (%v, %v), want (800, 450)", x, y)
+	}
+}
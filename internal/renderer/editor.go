@@ -0,0 +1,158 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"particle-physics-simulator/internal/particle"
+)
+
+const (
+	editorWidth  = 260
+	editorTop    = 60 // Below the window buttons and the view information
+	editorRow    = 28
+	editorMargin = 10
+	editorRows   = 6
+)
+
+// Limits of the values the editor sliders set.
+const (
+	EditorMaxMass   = 100.0
+	EditorMaxRadius = 40.0
+	EditorMaxCharge = 1.0
+)
+
+// EditorPalette is the colours offered by the editor.
+var EditorPalette = []particle.Color{
+	{R: 1, G: 0, B: 0, A: 1},
+	{R: 0, G: 1, B: 0, A: 1},
+	{R: 0, G: 0, B: 1, A: 1},
+	{R: 1, G: 1, B: 0, A: 1},
+	{R: 1, G: 0, B: 1, A: 1},
+	{R: 0, G: 1, B: 1, A: 1},
+	{R: 0.5, G: 0.7, B: 1, A: 1},
+	{R: 1, G: 1, B: 1, A: 1},
+}
+
+// Editor is the particle editor panel. With particles selected it edits them; otherwise it
+// sets the properties new particles are placed with.
+type Editor struct {
+	Open    bool
+	Placing particle.Properties // Properties of particles placed by clicking
+}
+
+// NewEditor returns a closed editor placing the first species.
+func NewEditor() *Editor {
+	return &Editor{Placing: particle.SpeciesList()[0].Properties}
+}
+
+// Bounds returns the area of the panel on a screen of the given size, along the right edge.
+func (e *Editor) Bounds(width, height int) Rectangle {
+	return Rectangle{
+		X:      float64(width - editorWidth - editorMargin),
+		Y:      editorTop,
+		Width:  editorWidth,
+		Height: 2*editorMargin + editorRow*(editorRows+1),
+	}
+}
+
+// Covers reports whether the open panel is under the screen position (x, y), so clicks there
+// are for the panel and not the world.
+func (e *Editor) Covers(r Renderer, x, y float64) bool {
+	return e.Open && e.Bounds(r.Size()).Contains(x, y)
+}
+
+// DrawEditor draws the editor panel if it is open and applies this frame's changes: to the
+// selected particles if there are any, otherwise to the properties new particles get. The
+// panel shows the first selected particle, and only the properties changed are set on the
+// others. The input is read from r, so r must draw in screen coordinates. It reports
+// whether anything changed.
+func DrawEditor(r Renderer, e *Editor, selected []*particle.Particle) bool {
+	if !e.Open {
+		return false
+	}
+	in := r.Input()
+	bounds := e.Bounds(r.Size())
+	DrawPanel(r, bounds)
+
+	props := e.Placing
+	title := "New Particles"
+	switch {
+	case len(selected) == 1:
+		props = selected[0].Properties()
+		title = fmt.Sprintf("Particle %d", selected[0].ID)
+	case len(selected) > 1:
+		props = selected[0].Properties()
+		title = fmt.Sprintf("%d Particles", len(selected))
+	}
+	before := props
+
+	x := bounds.X + editorMargin
+	width := bounds.Width - 2*editorMargin
+	row := func(i int) Rectangle {
+		return Rectangle{X: x, Y: bounds.Y + editorMargin + float64(i*editorRow), Width: width, Height: editorRow - 4}
+	}
+	r.Text(title, x, row(0).Y+2, 20, white)
+
+	// The species button cycles through the species, starting over from a custom particle
+	species := particle.SpeciesList()
+	current := particle.SpeciesOf(props)
+	name := "Custom"
+	if current >= 0 {
+		name = species[current].Name
+	}
+	whole := false // Set every property rather than only those changed
+	if Button(r, in, row(1), "Species: "+name) {
+		props = species[(current+1)%len(species)].Properties
+		whole = true
+	}
+
+	props.Mass = Slider(r, in, row(2), "Mass", props.Mass, 0.5, EditorMaxMass, 0.5)
+	props.Radius = Slider(r, in, row(3), "Radius", props.Radius, 2, EditorMaxRadius, 1)
+	props.Charge = Slider(r, in, row(4), "Charge", props.Charge, -EditorMaxCharge, EditorMaxCharge, 0.01)
+
+	colors := make([]color.RGBA, len(EditorPalette))
+	for i, c := range EditorPalette {
+		colors[i] = c.RGBA8()
+	}
+	if i := Swatches(r, in, row(5), colors, props.Color.RGBA8()); i >= 0 {
+		props.Color = EditorPalette[i]
+	}
+	props.Movable = Checkbox(r, in, row(6), "Movable", props.Movable)
+
+	if props == before {
+		return false
+	}
+	if len(selected) == 0 {
+		e.Placing = props
+		return true
+	}
+	for _, p := range selected {
+		if whole {
+			p.SetProperties(props)
+		} else {
+			p.SetProperties(changed(p.Properties(), before, props))
+		}
+	}
+	return true
+}
+
+// changed returns props with the properties that differ between before and after set to
+// their value after.
+func changed(props, before, after particle.Properties) particle.Properties {
+	if after.Mass != before.Mass {
+		props.Mass = after.Mass
+	}
+	if after.Radius != before.Radius {
+		props.Radius = after.Radius
+	}
+	if after.Charge != before.Charge {
+		props.Charge = after.Charge
+	}
+	if after.Color != before.Color {
+		props.Color = after.Color
+	}
+	if after.Movable != before.Movable {
+		props.Movable = after.Movable
+	}
+	return props
+}
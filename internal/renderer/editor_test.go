@@ -0,0 +1,74 @@
+package renderer
+
+import (
+	"particle-physics-simulator/internal/particle"
+	"strings"
+	"testing"
+)
+
+// editorRowCentre returns the middle of the i-th row of the editor on a 1000x800 screen.
+func editorRowCentre(e *Editor, i int) (float64, float64) {
+	b := e.Bounds(1000, 800)
+	return b.X + b.Width/2, b.Y + editorMargin + float64(i*editorRow) + editorRow/2 - 2
+}
+
+func TestDrawEditor(t *testing.T) {
+	e := NewEditor()
+	n := NewNull(1000, 800)
+	x, y := editorRowCentre(e, 1)
+	n.Frames = []Input{Click(x, y, MouseLeft)}
+
+	if DrawEditor(n, e, nil) {
+		t.Error("closed editor changed something")
+	}
+
+	e.Open = true
+	if !DrawEditor(n, e, nil) || e.Placing != particle.SpeciesList()[1].Properties {
+		t.Errorf("species button left %+v", e.Placing)
+	}
+
+	// The mass slider runs from 0.5 on the left to the maximum on the right
+	p := particle.SpeciesList()[0].New(0, 0)
+	b := e.Bounds(1000, 800)
+	_, y = editorRowCentre(e, 2)
+	n.Frames[0] = Click(b.X+b.Width-editorMargin, y, MouseLeft)
+	before := e.Placing
+	if !DrawEditor(n, e, []*particle.Particle{p}) || p.Mass != EditorMaxMass {
+		t.Errorf("mass slider set the mass to %v", p.Mass)
+	}
+	if e.Placing != before {
+		t.Error("editing a selected particle changed the placing properties")
+	}
+}
+
+func TestDrawEditorGroup(t *testing.T) {
+	e := NewEditor()
+	e.Open = true
+	n := NewNull(1000, 800)
+	b := e.Bounds(1000, 800)
+	_, y := editorRowCentre(e, 3)
+	n.Frames = []Input{Click(b.X+b.Width-editorMargin, y, MouseLeft)}
+
+	first := particle.SpeciesList()[0].New(0, 0)
+	second := particle.SpeciesList()[3].New(0, 0)
+	DrawEditor(n, e, []*particle.Particle{first, second})
+	if first.Radius != EditorMaxRadius || second.Radius != EditorMaxRadius {
+		t.Errorf("radii %v and %v, want both set", first.Radius, second.Radius)
+	}
+	if second.Mass != particle.SpeciesList()[3].Mass {
+		t.Errorf("mass of the second particle changed to %v", second.Mass)
+	}
+}
+
+func TestDrawSelectionInfo(t *testing.T) {
+	n := NewNull(800, 600)
+	n.BeginFrame(black)
+	DrawSelectionInfo(n, nil)
+	DrawSelectionInfo(n, []*particle.Particle{{Mass: 2, Charge: 1, X: 10, Movable: true}, {Mass: 2, Charge: 1, X: 30}})
+	n.EndFrame()
+
+	if len(n.Last.Texts) != 2 || n.Last.Texts[0] != "Selected: 2 (1 pinned)" ||
+		!strings.Contains(n.Last.Texts[1], "Mass 4 | Charge 2") || !strings.Contains(n.Last.Texts[1], "Centre of Mass (20, 0)") {
+		t.Errorf("texts = %q", n.Last.Texts)
+	}
+}
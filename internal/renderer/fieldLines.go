@@ -0,0 +1,66 @@
+package renderer
+
+import (
+	"image/color"
+	"particle-physics-simulator/internal/electrostatics"
+	"particle-physics-simulator/internal/particle"
+)
+
+// fieldLineColor is used for all field lines so they read as one overlay over the coloured particles.
+var fieldLineColor = color.RGBA{R: 200, G: 220, B: 255, A: 150}
+
+// DrawFieldLines draws each traced field line as a polyline.
+func DrawFieldLines(r Renderer, lines []electrostatics.FieldLine) {
+	for _, line := range lines {
+		for i := 1; i < len(line.Points); i++ {
+			from := line.Points[i-1]
+			to := line.Points[i]
+			r.Line(from.X, from.Y, to.X, to.Y, 1, fieldLineColor)
+		}
+	}
+}
+
+// FieldLineInterval is the number of steps the cached field lines are kept for while the
+// simulation runs. Tracing every frame is too slow with many charges, and lines a few steps
+// old still show the field.
+const FieldLineInterval = 10
+
+// FieldLineCache keeps traced field lines between frames, since tracing is costly. The lines
+// are traced again when the charges or fields are edited, when the options change and every
+// FieldLineInterval steps while time moves on.
+type FieldLineCache struct {
+	traced  bool
+	step    int
+	version int
+	opts    electrostatics.FieldLineOptions
+	lines   []electrostatics.FieldLine
+}
+
+// NewFieldLineCache returns an empty cache.
+func NewFieldLineCache() *FieldLineCache {
+	return &FieldLineCache{}
+}
+
+// Trace returns the field lines of the particles at the given step. version counts the edits
+// made to the charges and fields, so any edit traces the lines again. Going back in time traces
+// them again too. A nil cache traces every time.
+func (c *FieldLineCache) Trace(particles []*particle.Particle, opts electrostatics.FieldLineOptions, step, version int) []electrostatics.FieldLine {
+	if c == nil {
+		return electrostatics.TraceFieldLines(particles, opts)
+	}
+	if c.traced && c.version == version && sameOptions(c.opts, opts) && step >= c.step && step-c.step < FieldLineInterval {
+		return c.lines
+	}
+
+	c.traced, c.step, c.version, c.opts = true, step, version, opts
+	c.lines = electrostatics.TraceFieldLines(particles, opts)
+	return c.lines
+}
+
+// sameOptions reports whether two sets of options trace the same lines, leaving out the
+// external field, which only changes with the step or an edit.
+func sameOptions(a, b electrostatics.FieldLineOptions) bool {
+	return a.LinesPerCharge == b.LinesPerCharge && a.MaxLines == b.MaxLines &&
+		a.StepSize == b.StepSize && a.MaxSteps == b.MaxSteps &&
+		a.MinX == b.MinX && a.MinY == b.MinY && a.MaxX == b.MaxX && a.MaxY == b.MaxY
+}
@@ -0,0 +1,60 @@
+package renderer
+
+import (
+	"image/color"
+	"math"
+	"particle-physics-simulator/internal/colormap"
+	"particle-physics-simulator/internal/electrostatics"
+)
+
+// FieldGridSpacing is the distance in pixels between field overlay samples.
+const FieldGridSpacing = 40
+
+// DrawElectricFieldOverlay draws the sampled field as arrows centred on the grid points.
+// Field strengths span many orders of magnitude near point charges, so arrow length and
+// colour follow the logarithm of the magnitude relative to the grid's mean.
+func DrawElectricFieldOverlay(r Renderer, grid *electrostatics.FieldGrid) {
+	if grid == nil || len(grid.Ex) == 0 {
+		return
+	}
+
+	meanMag := 0.0
+	for i := range grid.Ex {
+		meanMag += math.Hypot(grid.Ex[i], grid.Ey[i])
+	}
+	meanMag /= float64(len(grid.Ex))
+	if meanMag == 0 {
+		return
+	}
+	logMax := math.Log1p(grid.MaxMagnitude() / meanMag)
+
+	maxLength := 0.45 * math.Min(grid.CellWidth, grid.CellHeight)
+	for row := 0; row < grid.Rows; row++ {
+		for col := 0; col < grid.Cols; col++ {
+			ex, ey := grid.At(col, row)
+			mag := math.Hypot(ex, ey)
+			if mag == 0 {
+				continue
+			}
+
+			strength := math.Log1p(mag/meanMag) / logMax
+			length := maxLength * math.Max(strength, 0.15)
+			x, y := grid.Point(col, row)
+			drawArrow(r, x, y, ex/mag*length, ey/mag*length, colormap.Field(strength))
+		}
+	}
+}
+
+// drawArrow draws an arrow centred on (x, y) with the given extent.
+func drawArrow(r Renderer, x, y, dx, dy float64, c color.RGBA) {
+	tipX, tipY := x+dx, y+dy
+	r.Line(x-dx, y-dy, tipX, tipY, 1.5, c)
+
+	// Arrow head: two short lines swept back from the tip
+	headLength := 0.4 * math.Hypot(dx, dy)
+	angle := math.Atan2(dy, dx)
+	for _, side := range []float64{-1, 1} {
+		a := angle + math.Pi - side*math.Pi/6
+		r.Line(tipX, tipY, tipX+headLength*math.Cos(a), tipY+headLength*math.Sin(a), 1.5, c)
+	}
+}
@@ -0,0 +1,43 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"particle-physics-simulator/internal/force"
+)
+
+// forceToolInstructions lists the force tool controls under the other instructions.
+const forceToolInstructions = "Force Tool: [G] Attract/Repel/Spring/Off | [Left Drag] Apply | [Left/Right Bracket] Radius | [-/=] Strength"
+
+// Colours of the force tool's outline by mode, brighter while it acts.
+var forceToolColors = map[force.PointForceMode]color.RGBA{
+	force.Attract: {R: 102, G: 191, B: 255, A: 255},
+	force.Repel:   {R: 255, G: 120, B: 90, A: 255},
+	force.Spring:  {R: 120, G: 230, B: 120, A: 255},
+}
+
+// DrawForceTool outlines the area the force tool acts on, dashed while the mouse button is
+// up, and labels it with the mode and strength.
+func DrawForceTool(r Renderer, f *force.PointForce) {
+	c := forceToolColors[f.Mode]
+	if !f.Active {
+		c.A = 120
+	}
+
+	const segments = 48
+	for i := 0; i < segments; i++ {
+		if !f.Active && i%2 == 1 {
+			continue
+		}
+		a0 := 2 * math.Pi * float64(i) / segments
+		a1 := 2 * math.Pi * float64(i+1) / segments
+		r.Line(f.X+f.Radius*math.Cos(a0), f.Y+f.Radius*math.Sin(a0), f.X+f.Radius*math.Cos(a1), f.Y+f.Radius*math.Sin(a1), 1.5, c)
+	}
+
+	label := fmt.Sprintf("%v %.3g", f.Mode, f.Strength)
+	if f.Mode == force.Spring {
+		label = fmt.Sprintf("%v %.3g", f.Mode, f.Stiffness)
+	}
+	r.Text(label, f.X+f.Radius*0.71, f.Y-f.Radius*0.71, 15, c)
+}
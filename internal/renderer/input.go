@@ -0,0 +1,216 @@
+package renderer
+
+import "slices"
+
+// Key identifies a keyboard key. The values are the GLFW key codes raylib uses.
+type Key int32
+
+const (
+	KeySpace        Key = 32
+	KeyApostrophe   Key = 39
+	KeyComma        Key = 44
+	KeyMinus        Key = 45
+	KeyPeriod       Key = 46
+	KeySlash        Key = 47
+	KeySemicolon    Key = 59
+	KeyEqual        Key = 61
+	KeyLeftBracket  Key = 91
+	KeyBackslash    Key = 92
+	KeyRightBracket Key = 93
+	KeyGrave        Key = 96
+
+	KeyEscape       Key = 256
+	KeyEnter        Key = 257
+	KeyTab          Key = 258
+	KeyBackspace    Key = 259
+	KeyInsert       Key = 260
+	KeyDelete       Key = 261
+	KeyRight        Key = 262
+	KeyLeft         Key = 263
+	KeyDown         Key = 264
+	KeyUp           Key = 265
+	KeyPageUp       Key = 266
+	KeyPageDown     Key = 267
+	KeyHome         Key = 268
+	KeyEnd          Key = 269
+	KeyF1           Key = 290
+	KeyF12          Key = 301
+	KeyLeftShift    Key = 340
+	KeyLeftControl  Key = 341
+	KeyLeftAlt      Key = 342
+	KeyLeftSuper    Key = 343
+	KeyRightShift   Key = 344
+	KeyRightControl Key = 345
+	KeyRightAlt     Key = 346
+	KeyRightSuper   Key = 347
+)
+
+// Digit and letter keys have their ASCII codes.
+const (
+	Key0 Key = '0' + iota
+	Key1
+	Key2
+	Key3
+	Key4
+	Key5
+	Key6
+	Key7
+	Key8
+	Key9
+)
+
+const (
+	KeyA Key = 'A' + iota
+	KeyB
+	KeyC
+	KeyD
+	KeyE
+	KeyF
+	KeyG
+	KeyH
+	KeyI
+	KeyJ
+	KeyK
+	KeyL
+	KeyM
+	KeyN
+	KeyO
+	KeyP
+	KeyQ
+	KeyR
+	KeyS
+	KeyT
+	KeyU
+	KeyV
+	KeyW
+	KeyX
+	KeyY
+	KeyZ
+)
+
+// Keys returns every key a backend should report.
+func Keys() []Key {
+	keys := []Key{
+		KeySpace, KeyApostrophe, KeyComma, KeyMinus, KeyPeriod, KeySlash, KeySemicolon,
+		KeyEqual, KeyLeftBracket, KeyBackslash, KeyRightBracket, KeyGrave,
+	}
+	for k := Key0; k <= Key9; k++ {
+		keys = append(keys, k)
+	}
+	for k := KeyA; k <= KeyZ; k++ {
+		keys = append(keys, k)
+	}
+	for k := KeyEscape; k <= KeyEnd; k++ {
+		keys = append(keys, k)
+	}
+	for k := KeyF1; k <= KeyF12; k++ {
+		keys = append(keys, k)
+	}
+	for k := KeyLeftShift; k <= KeyRightSuper; k++ {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// MouseButton identifies a mouse button, with raylib's numbering.
+type MouseButton int32
+
+const (
+	MouseLeft   MouseButton = 0
+	MouseRight  MouseButton = 1
+	MouseMiddle MouseButton = 2
+)
+
+// MouseButtons returns every button a backend should report.
+func MouseButtons() []MouseButton {
+	return []MouseButton{MouseLeft, MouseRight, MouseMiddle}
+}
+
+// Input is the keyboard and mouse state for one frame.
+type Input struct {
+	MouseX, MouseY float64
+	MouseDX        float64 // Mouse movement since the last frame
+	MouseDY        float64
+	Wheel          float64 // Mouse wheel movement, positive away from the user
+
+	Pressed  []Key // Keys that went down this frame
+	Repeated []Key // Keys held long enough to auto-repeat this frame
+	Down     []Key // Keys held down
+
+	ButtonsPressed  []MouseButton
+	ButtonsDown     []MouseButton
+	ButtonsReleased []MouseButton
+}
+
+// KeyPressed reports whether k went down this frame.
+func (in Input) KeyPressed(k Key) bool {
+	return slices.Contains(in.Pressed, k)
+}
+
+// KeyPressedOrRepeated reports whether k went down or auto-repeated this frame.
+func (in Input) KeyPressedOrRepeated(k Key) bool {
+	return in.KeyPressed(k) || slices.Contains(in.Repeated, k)
+}
+
+// KeyDown reports whether k is held down.
+func (in Input) KeyDown(k Key) bool {
+	return slices.Contains(in.Down, k)
+}
+
+// Shift reports whether either shift key is held down.
+func (in Input) Shift() bool {
+	return in.KeyDown(KeyLeftShift) || in.KeyDown(KeyRightShift)
+}
+
+// Control reports whether either control key is held down.
+func (in Input) Control() bool {
+	return in.KeyDown(KeyLeftControl) || in.KeyDown(KeyRightControl)
+}
+
+// ButtonPressed reports whether b went down this frame.
+func (in Input) ButtonPressed(b MouseButton) bool {
+	return slices.Contains(in.ButtonsPressed, b)
+}
+
+// ButtonDown reports whether b is held down.
+func (in Input) ButtonDown(b MouseButton) bool {
+	return slices.Contains(in.ButtonsDown, b)
+}
+
+// ButtonReleased reports whether b was let go this frame.
+func (in Input) ButtonReleased(b MouseButton) bool {
+	return slices.Contains(in.ButtonsReleased, b)
+}
+
+// WithoutMouse returns the input with the mouse buttons and wheel left out, for when they are
+// used by a panel under the mouse.
+func (in Input) WithoutMouse() Input {
+	in.Wheel = 0
+	in.ButtonsPressed, in.ButtonsDown, in.ButtonsReleased = nil, nil, nil
+	return in
+}
+
+// Click returns the input for a frame where button b is pressed at (x, y).
+func Click(x, y float64, b MouseButton) Input {
+	return Input{
+		MouseX:         x,
+		MouseY:         y,
+		ButtonsPressed: []MouseButton{b},
+		ButtonsDown:    []MouseButton{b},
+	}
+}
+
+// Release returns the input for a frame where button b is let go at (x, y).
+func Release(x, y float64, b MouseButton) Input {
+	return Input{MouseX: x, MouseY: y, ButtonsReleased: []MouseButton{b}}
+}
+
+// Drag returns the input for a frame where the mouse is at (x, y) with button b held.
+func Drag(x, y float64, b MouseButton) Input {
+	return Input{MouseX: x, MouseY: y, ButtonsDown: []MouseButton{b}}
+}
+
+// Press returns the input for a frame where the keys go down.
+func Press(keys ...Key) Input {
+	return Input{Pressed: keys, Down: keys}
+}
@@ -0,0 +1,28 @@
+package renderer
+
+import (
+	"image/color"
+	"particle-physics-simulator/internal/particle"
+)
+
+// launchBandColor is the colour of the band from a particle being launched to the mouse.
+var launchBandColor = color.RGBA{R: 200, G: 200, B: 200, A: 160}
+
+// DrawLaunch draws a particle being launched by dragging back to (toX, toY): the particle
+// half transparent, a band to the mouse and its predicted path, fading out ahead.
+func DrawLaunch(r Renderer, p *particle.Particle, toX, toY float64, path []Point) {
+	c := p.Color.RGBA8()
+	for i := 1; i < len(path); i++ {
+		// Dashed, so the path reads as a prediction rather than a trail
+		if i/4%2 == 1 {
+			continue
+		}
+		faded := c
+		faded.A = uint8(float64(c.A) * (1 - float64(i)/float64(len(path))))
+		r.Line(path[i-1].X, path[i-1].Y, path[i].X, path[i].Y, 1.5, faded)
+	}
+
+	r.Line(p.X, p.Y, toX, toY, 1, launchBandColor)
+	c.A /= 2
+	r.Circle(p.X, p.Y, p.Radius, c)
+}
@@ -0,0 +1,127 @@
+package renderer
+
+import "image/color"
+
+// Null is a backend that draws nothing. It replays scripted input one frame at a time and
+// counts what each frame draws, so the simulation loop can be driven from tests.
+type Null struct {
+	Width, Height int
+	Frames        []Input // Input for each frame in turn; frames after these have no input
+	MaxFrames     int     // ShouldClose reports true after this many frames; zero stops when Frames runs out
+
+	Last      FrameStats // What the last completed frame drew
+	Maximized bool
+	Minimized bool
+
+	// ScreenWidth and ScreenHeight, if set, are the size the backend takes while maximised.
+	ScreenWidth, ScreenHeight int
+
+	frame                         int
+	current                       FrameStats
+	closed                        bool
+	restoredWidth, restoredHeight int // Size to return to from maximised
+}
+
+// FrameStats counts the drawing calls of one frame.
+type FrameStats struct {
+	Background color.RGBA
+	Circles    int
+	Lines      int
+	Polygons   int
+	Rects      int
+	Texts      []string
+}
+
+// NewNull creates a null backend of the given size.
+func NewNull(width, height int) *Null {
+	return &Null{Width: width, Height: height}
+}
+
+// Frame returns the number of completed frames.
+func (n *Null) Frame() int {
+	return n.frame
+}
+
+func (n *Null) BeginFrame(background color.RGBA) {
+	n.current = FrameStats{Background: background}
+}
+
+func (n *Null) EndFrame() {
+	n.Last = n.current
+	n.frame++
+}
+
+func (n *Null) Size() (int, int) {
+	return n.Width, n.Height
+}
+
+func (n *Null) Circle(x, y, radius float64, c color.RGBA) {
+	n.current.Circles++
+}
+
+func (n *Null) Line(x0, y0, x1, y1, width float64, c color.RGBA) {
+	n.current.Lines++
+}
+
+func (n *Null) Polygon(points []Point, c color.RGBA) {
+	n.current.Polygons++
+}
+
+func (n *Null) Rect(x, y, width, height float64, c color.RGBA) {
+	n.current.Rects++
+}
+
+func (n *Null) Text(s string, x, y, size float64, c color.RGBA) {
+	n.current.Texts = append(n.current.Texts, s)
+}
+
+// TextWidth assumes every character is 0.6 times as wide as the text is tall.
+func (n *Null) TextWidth(s string, size float64) float64 {
+	return 0.6 * size * float64(len(s))
+}
+
+func (n *Null) Input() Input {
+	if n.frame < len(n.Frames) {
+		return n.Frames[n.frame]
+	}
+	return Input{}
+}
+
+func (n *Null) FPS() int {
+	return 0
+}
+
+func (n *Null) ShouldClose() bool {
+	limit := n.MaxFrames
+	if limit == 0 {
+		limit = len(n.Frames)
+	}
+	return n.closed || n.frame >= limit
+}
+
+func (n *Null) Close() error {
+	n.closed = true
+	return nil
+}
+
+func (n *Null) RequestClose() {
+	n.closed = true
+}
+
+// ToggleMaximized switches to the screen size and back if one is set.
+func (n *Null) ToggleMaximized() {
+	n.Maximized = !n.Maximized
+	if n.ScreenWidth == 0 || n.ScreenHeight == 0 {
+		return
+	}
+	if n.Maximized {
+		n.restoredWidth, n.restoredHeight = n.Width, n.Height
+		n.Width, n.Height = n.ScreenWidth, n.ScreenHeight
+	} else {
+		n.Width, n.Height = n.restoredWidth, n.restoredHeight
+	}
+}
+
+func (n *Null) Minimize() {
+	n.Minimized = true
+}
@@ -0,0 +1,8 @@
+package renderer
+
+// Overlays holds which optional visualisations are drawn on top of the particles.
+type Overlays struct {
+	ElectricField bool // Arrow grid of the total electric field
+	FieldLines    bool // Field lines traced from the charges
+	Potential     bool // Potential heatmap with equipotential contours
+}
@@ -0,0 +1,54 @@
+package renderer
+
+import (
+	"fmt"
+	"particle-physics-simulator/internal/playback"
+	"particle-physics-simulator/internal/trajectory"
+)
+
+const (
+	timelineMargin = 20
+	timelineHeight = 12
+)
+
+// TimelineBounds returns the area of the playback timeline bar along the bottom of a window
+// of the given size.
+func TimelineBounds(width, height int) Rectangle {
+	return Rectangle{
+		X:      timelineMargin,
+		Y:      float64(height - timelineMargin - timelineHeight),
+		Width:  float64(width - 2*timelineMargin),
+		Height: timelineHeight,
+	}
+}
+
+// DrawTimeline draws the timeline bar with the played part filled in and a handle at the
+// current position.
+func DrawTimeline(r Renderer, progress float64) {
+	bar := TimelineBounds(r.Size())
+	played := bar
+	played.Width = bar.Width * progress
+
+	drawRectangle(r, bar, darkGray)
+	drawRectangle(r, played, skyBlue)
+	r.Circle(bar.X+played.Width, bar.Y+bar.Height/2, timelineHeight, white)
+}
+
+// DrawPlaybackUI shows the playback status in place of the simulation status of DrawUI.
+func DrawPlaybackUI(r Renderer, player *playback.Player, frame trajectory.Frame) {
+	status := "Paused"
+	if player.Playing {
+		status = "Playing"
+	}
+	_, end := player.Range()
+
+	r.Text(fmt.Sprintf("FPS: %d", r.FPS()), 10, 10, 20, white)
+	r.Text(fmt.Sprintf("Particles: %d", len(frame.Particles)), 10, 30, 20, white)
+	r.Text(fmt.Sprintf("Status: %s at %gx", status, player.Speed), 10, 50, 20, white)
+	info := fmt.Sprintf("Frame %d/%d | Step %d | Time %.3f / %.3f s",
+		player.Index()+1, player.Source.NumFrames(), frame.Step, player.Time(), end)
+	r.Text(info, 10, 70, 20, white)
+
+	instructions := "Controls: [Space] Play/Pause | [Left/Right] Step Frame (Shift x10) | [Up/Down] Speed | [Home/End] Jump | [Click Timeline] Scrub | [F] Field Overlay | [L] Field Lines | [P] Potential | [T] Trails"
+	drawInstructions(r, instructions, viewInstructions)
+}
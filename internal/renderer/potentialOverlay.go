@@ -0,0 +1,45 @@
+package renderer
+
+import (
+	"image/color"
+	"math"
+	"particle-physics-simulator/internal/colormap"
+	"particle-physics-simulator/internal/electrostatics"
+)
+
+const (
+	PotentialCellSize     = 10 // Size in pixels of each heatmap cell
+	EquipotentialLevels   = 12 // Number of equipotential contours drawn
+	potentialHeatmapAlpha = 140
+)
+
+var equipotentialColor = color.RGBA{R: 255, G: 255, B: 255, A: 120}
+
+// DrawPotentialOverlay draws the potential as a heatmap, blue for negative and red for
+// positive, with equipotential contour lines on top.
+func DrawPotentialOverlay(r Renderer, grid *electrostatics.PotentialGrid) {
+	if grid == nil || len(grid.Values) == 0 {
+		return
+	}
+
+	// Symmetric scale so zero potential is always the neutral colour
+	low, high := grid.Range(2, 98)
+	scale := math.Max(math.Abs(low), math.Abs(high))
+	if scale == 0 {
+		return
+	}
+
+	for row := 0; row < grid.Rows; row++ {
+		for col := 0; col < grid.Cols; col++ {
+			x := grid.MinX + float64(col)*grid.CellWidth
+			y := grid.MinY + float64(row)*grid.CellHeight
+			r.Rect(x, y, grid.CellWidth, grid.CellHeight, colormap.Potential(grid.At(col, row)/scale, potentialHeatmapAlpha))
+		}
+	}
+
+	for _, level := range grid.EquipotentialLevels(EquipotentialLevels) {
+		for _, segment := range grid.Contour(level) {
+			r.Line(segment.A.X, segment.A.Y, segment.B.X, segment.B.Y, 1, equipotentialColor)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package raylib is the renderer backend that draws into a raylib window.
+package raylib
+
+import (
+	"image/color"
+	"particle-physics-simulator/internal/renderer"
+
+	"github.com/gen2brain/raylib-go/raylib"
+)
+
+// TargetFPS is the frame rate EndFrame waits for.
+const TargetFPS = 120
+
+// Window draws into a raylib window and reads its keyboard and mouse.
+type Window struct {
+	input   renderer.Input
+	polled  bool
+	closing bool
+	points  []rl.Vector2
+}
+
+// Smallest size the window can be resized to.
+const (
+	minWidth  = 640
+	minHeight = 360
+)
+
+// New opens a resizable window of the given size. Only one window can be open at a time.
+func New(width, height int, title string) *Window {
+	rl.SetConfigFlags(rl.FlagWindowResizable)
+	rl.InitWindow(int32(width), int32(height), title)
+	rl.SetWindowMinSize(minWidth, minHeight)
+	rl.SetTargetFPS(TargetFPS)
+	return &Window{}
+}
+
+func (w *Window) BeginFrame(background color.RGBA) {
+	rl.BeginDrawing()
+	rl.ClearBackground(background)
+}
+
+// EndFrame presents the frame and waits for the next one. raylib polls input here, so the
+// next call to Input reads it afresh.
+func (w *Window) EndFrame() {
+	rl.EndDrawing()
+	w.polled = false
+}
+
+func (w *Window) Size() (int, int) {
+	return rl.GetScreenWidth(), rl.GetScreenHeight()
+}
+
+func (w *Window) Circle(x, y, radius float64, c color.RGBA) {
+	rl.DrawCircleV(vector(x, y), float32(radius), c)
+}
+
+func (w *Window) Line(x0, y0, x1, y1, width float64, c color.RGBA) {
+	rl.DrawLineEx(vector(x0, y0), vector(x1, y1), float32(width), c)
+}
+
+// Polygon draws a triangle fan, which raylib only fills when the points run
+// counter-clockwise on screen, so clockwise polygons are reversed.
+func (w *Window) Polygon(points []renderer.Point, c color.RGBA) {
+	if len(points) < 3 {
+		return
+	}
+	// Twice the signed area; negative is counter-clockwise with y pointing down
+	area := 0.0
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		area += p.X*q.Y - q.X*p.Y
+	}
+
+	w.points = w.points[:0]
+	for i := range points {
+		p := points[i]
+		if area > 0 {
+			p = points[len(points)-1-i]
+		}
+		w.points = append(w.points, vector(p.X, p.Y))
+	}
+	rl.DrawTriangleFan(w.points, c)
+}
+
+func (w *Window) Rect(x, y, width, height float64, c color.RGBA) {
+	rl.DrawRectangleV(vector(x, y), vector(width, height), c)
+}
+
+func (w *Window) Text(s string, x, y, size float64, c color.RGBA) {
+	rl.DrawText(s, int32(x), int32(y), int32(size), c)
+}
+
+func (w *Window) TextWidth(s string, size float64) float64 {
+	return float64(rl.MeasureText(s, int32(size)))
+}
+
+// Input returns the keyboard and mouse state polled at the end of the last frame.
+func (w *Window) Input() renderer.Input {
+	if w.polled {
+		return w.input
+	}
+
+	delta := rl.GetMouseDelta()
+	in := renderer.Input{
+		MouseX:  float64(rl.GetMouseX()),
+		MouseY:  float64(rl.GetMouseY()),
+		MouseDX: float64(delta.X),
+		MouseDY: float64(delta.Y),
+		Wheel:   float64(rl.GetMouseWheelMove()),
+	}
+	for _, k := range renderer.Keys() {
+		if rl.IsKeyPressed(int32(k)) {
+			in.Pressed = append(in.Pressed, k)
+		}
+		if rl.IsKeyPressedRepeat(int32(k)) {
+			in.Repeated = append(in.Repeated, k)
+		}
+		if rl.IsKeyDown(int32(k)) {
+			in.Down = append(in.Down, k)
+		}
+	}
+	for _, b := range renderer.MouseButtons() {
+		if rl.IsMouseButtonPressed(rl.MouseButton(b)) {
+			in.ButtonsPressed = append(in.ButtonsPressed, b)
+		}
+		if rl.IsMouseButtonDown(rl.MouseButton(b)) {
+			in.ButtonsDown = append(in.ButtonsDown, b)
+		}
+		if rl.IsMouseButtonReleased(rl.MouseButton(b)) {
+			in.ButtonsReleased = append(in.ButtonsReleased, b)
+		}
+	}
+
+	w.input = in
+	w.polled = true
+	return in
+}
+
+func (w *Window) FPS() int {
+	return int(rl.GetFPS())
+}
+
+func (w *Window) ShouldClose() bool {
+	return w.closing || rl.WindowShouldClose()
+}
+
+func (w *Window) Close() error {
+	rl.CloseWindow()
+	return nil
+}
+
+// RequestClose makes ShouldClose report true, so the loop finishes its frame before the
+// window is closed.
+func (w *Window) RequestClose() {
+	w.closing = true
+}
+
+func (w *Window) ToggleMaximized() {
+	if rl.IsWindowMaximized() {
+		rl.RestoreWindow()
+	} else {
+		rl.MaximizeWindow()
+	}
+}
+
+func (w *Window) Minimize() {
+	rl.MinimizeWindow()
+}
+
+func vector(x, y float64) rl.Vector2 {
+	return rl.Vector2{X: float32(x), Y: float32(y)}
+}
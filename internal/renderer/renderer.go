@@ -2,11 +2,11 @@ package renderer
 
 import (
 	"fmt"
+	"image/color"
 	"math"
+	"particle-physics-simulator/internal/analytics"
 	"particle-physics-simulator/internal/particle"
-	"particle-physics-simulator/internal/physics"
-
-	"github.com/gen2brain/raylib-go/raylib"
+	"strings"
 )
 
 const (
@@ -17,31 +17,39 @@ const (
 	buttonSize    int     = 20 
 )
 
-func InitWindow() {
-	rl.InitWindow(int32(screenWidth), int32(screenHeight), "Particle Physics Simulator")
-	rl.SetTargetFPS(120) 
+// ScreenSize returns the size the window opens at in pixels.
+func ScreenSize() (int, int) {
+	return screenWidth, screenHeight
 }
 
-func DrawParticle(p *particle.Particle) {
-	drawParticleCircle(p)
-	physics.ApplyBoundaryConditions(p, screenWidth, screenHeight)
+// WindowTitle is the title of the interactive window.
+const WindowTitle = "Particle Physics Simulator"
+
+func DrawParticle(r Renderer, p *particle.Particle) {
+	drawParticleCircle(r, p)
 }
 
 // drawParticleCircle draws a particle as a circle on the screen using the particle's color and position.
-func drawParticleCircle(p *particle.Particle) {
-	color := rl.Color{
-		R: uint8(p.Color.R * 255),
-		G: uint8(p.Color.G * 255),
-		B: uint8(p.Color.B * 255),
-		A: uint8(p.Color.A * 255),
+func drawParticleCircle(r Renderer, p *particle.Particle) {
+	r.Circle(p.X, p.Y, p.Radius, p.Color.RGBA8())
+}
+
+// DrawSelection outlines a selected particle with a ring just outside it.
+func DrawSelection(r Renderer, p *particle.Particle) {
+	const segments = 32
+	radius := p.Radius + 4
+	for i := 0; i < segments; i++ {
+		a0 := 2 * math.Pi * float64(i) / segments
+		a1 := 2 * math.Pi * float64(i+1) / segments
+		r.Line(p.X+radius*math.Cos(a0), p.Y+radius*math.Sin(a0), p.X+radius*math.Cos(a1), p.Y+radius*math.Sin(a1), 2, selectionColor)
 	}
-	rl.DrawCircle(int32(p.X), int32(p.Y), float32(p.Radius), color)
 }
 
 // DrawParticleInfo shows particle info (e.g., mass, velocity) when the mouse hovers over a particle.
-func DrawParticleInfo(particles []*particle.Particle) {
-	mouseX := float64(rl.GetMouseX())
-	mouseY := float64(rl.GetMouseY())
+func DrawParticleInfo(r Renderer, particles []*particle.Particle) {
+	input := r.Input()
+	mouseX := input.MouseX
+	mouseY := input.MouseY
 	var nearestParticle *particle.Particle
 	var minDistance float64 = 1000000 // Set a high initial value
 
@@ -58,16 +66,16 @@ func DrawParticleInfo(particles []*particle.Particle) {
 
 	if nearestParticle != nil {
 		info := fmt.Sprintf("Mass: %.2f, Velocity: (%.2f, %.2f)", nearestParticle.Mass, nearestParticle.Vx, nearestParticle.Vy)
-		textHeight := 10
-		xPos := int32(nearestParticle.X) + 10
-		yPos := int32(nearestParticle.Y) - int32(textHeight) - int32(5)
-		rl.DrawText(info, xPos, yPos, 10, rl.Yellow)
+		textHeight := 10.0
+		xPos := nearestParticle.X + 10
+		yPos := nearestParticle.Y - textHeight - 5
+		r.Text(info, xPos, yPos, textHeight, yellow)
 	}
 }
 
 
-func DrawUI(particles []*particle.Particle, paused bool) {
-	fps := rl.GetFPS()
+func DrawUI(r Renderer, particles []*particle.Particle, paused bool) {
+	fps := r.FPS()
 	particleCount := len(particles)
 	pauseStatus := "Running"
 	if paused {
@@ -75,57 +83,95 @@ func DrawUI(particles []*particle.Particle, paused bool) {
 	}
 
 	// Display FPS, particle count, and status
-	rl.DrawText(fmt.Sprintf("FPS: %d", fps), 10, 10, 20, rl.RayWhite)
-	rl.DrawText(fmt.Sprintf("Particles: %d", particleCount), 10, 30, 20, rl.RayWhite)
-	rl.DrawText(fmt.Sprintf("Status: %s", pauseStatus), 10, 50, 20, rl.RayWhite)
+	r.Text(fmt.Sprintf("FPS: %d", fps), 10, 10, 20, white)
+	r.Text(fmt.Sprintf("Particles: %d", particleCount), 10, 30, 20, white)
+	r.Text(fmt.Sprintf("Status: %s", pauseStatus), 10, 50, 20, white)
 
 	// Display instructions for controls
-	instructions := "Controls: [Space] Pause/Resume | [Left Click] Add Particle | [Right Click] Remove Particle"
-	rl.DrawText(instructions, 10, int32(screenHeight)-870, 15, rl.Gray)
+	instructions := "Controls: [Space] Pause/Resume | [Left Click] Select/Add Particle (Drag: Launch) | [Right Click] Remove Particle | [F] Field Overlay | [L] Field Lines | [P] Potential | [T] Trails (Shift: Selected) | [E] Editor | [Ctrl+Z/Y] Undo/Redo | [S] Save SVG"
+	drawInstructions(r, instructions, viewInstructions, selectionInstructions, forceToolInstructions, rewindInstructions)
+}
+
+const (
+	instructionsTop     = 100 // Below the four lines of status
+	instructionsSize    = 15
+	instructionsLeading = 18
+)
+
+// drawInstructions draws lines of controls under the status, wrapping each at its " | "
+// separators where it is wider than the window.
+func drawInstructions(r Renderer, lines ...string) {
+	width, _ := r.Size()
+	maxWidth := float64(width) - 20
+	y := float64(instructionsTop)
+	for _, line := range lines {
+		row := ""
+		for _, item := range strings.Split(line, " | ") {
+			next := item
+			if row != "" {
+				next = row + " | " + item
+			}
+			if row != "" && r.TextWidth(next, instructionsSize) > maxWidth {
+				r.Text(row, 10, y, instructionsSize, gray)
+				y += instructionsLeading
+				next = "  " + item
+			}
+			row = next
+		}
+		r.Text(row, 10, y, instructionsSize, gray)
+		y += instructionsLeading
+	}
 }
 
-func CloseWindow() {
-	rl.CloseWindow()
+// DrawEnergyInfo shows the total energy of the latest step and how far it has drifted.
+func DrawEnergyInfo(r Renderer, entry analytics.LedgerEntry) {
+	energy := fmt.Sprintf("Energy: %.4g (drift %.3g, dissipated %.3g)", entry.Energy.Total(), entry.Drift, entry.Dissipated())
+	r.Text(energy, 10, 70, 20, white)
 }
 
 // DrawWindowButtons draws and handles actions for window control buttons like close, minimize, and maximize.
-func DrawWindowButtons() {
-	closeButtonColor := rl.Red
-	maximizeButtonColor := rl.Gray
-	minimizeButtonColor := rl.Yellow
+// Backends without a window draw the buttons but ignore clicks on them.
+func DrawWindowButtons(r Renderer) {
+	closeButtonColor := red
+	maximizeButtonColor := gray
+	minimizeButtonColor := yellow
 
 	// Button positions (top-right corner of the window)
-	closeButtonPos := rl.Rectangle{X: float32(screenWidth - 3*buttonSize), Y: 0, Width: float32(buttonSize), Height: float32(buttonSize)}
-	maximizeButtonPos := rl.Rectangle{X: float32(screenWidth - 2*buttonSize), Y: 0, Width: float32(buttonSize), Height: float32(buttonSize)}
-	minimizeButtonPos := rl.Rectangle{X: float32(screenWidth - buttonSize), Y: 0, Width: float32(buttonSize), Height: float32(buttonSize)}
+	width, _ := r.Size()
+	size := float64(buttonSize)
+	closeButtonPos := Rectangle{X: float64(width - 3*buttonSize), Y: 0, Width: size, Height: size}
+	maximizeButtonPos := Rectangle{X: float64(width - 2*buttonSize), Y: 0, Width: size, Height: size}
+	minimizeButtonPos := Rectangle{X: float64(width - buttonSize), Y: 0, Width: size, Height: size}
 
 	// Draw buttons
-	rl.DrawRectangleRec(closeButtonPos, closeButtonColor)
-	rl.DrawRectangleRec(maximizeButtonPos, maximizeButtonColor)
-	rl.DrawRectangleRec(minimizeButtonPos, minimizeButtonColor)
+	drawRectangle(r, closeButtonPos, closeButtonColor)
+	drawRectangle(r, maximizeButtonPos, maximizeButtonColor)
+	drawRectangle(r, minimizeButtonPos, minimizeButtonColor)
 
 	// Handle button clicks
-	if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
-		mouseX := float32(rl.GetMouseX())
-		mouseY := float32(rl.GetMouseY())
+	input := r.Input()
+	window, ok := r.(Window)
+	if ok && input.ButtonPressed(MouseLeft) {
+		mouseX := input.MouseX
+		mouseY := input.MouseY
 
 		// Close button action
-		if rl.CheckCollisionPointRec(rl.Vector2{X: mouseX, Y: mouseY}, closeButtonPos) {
-			rl.CloseWindow() // Close the window
+		if closeButtonPos.Contains(mouseX, mouseY) {
+			window.RequestClose() // Close the window at the end of the frame
 		}
 		// Maximize button action
-		if rl.CheckCollisionPointRec(rl.Vector2{X: mouseX, Y: mouseY}, maximizeButtonPos) {
-			isMaximized := rl.IsWindowMaximized()
-			if isMaximized {
-				rl.RestoreWindow()
-			} else {
-				rl.MaximizeWindow()
-			}
+		if maximizeButtonPos.Contains(mouseX, mouseY) {
+			window.ToggleMaximized()
 		}
 		// Minimize button action
-		if rl.CheckCollisionPointRec(rl.Vector2{X: mouseX, Y: mouseY}, minimizeButtonPos) {
-			rl.MinimizeWindow()
+		if minimizeButtonPos.Contains(mouseX, mouseY) {
+			window.Minimize()
 		}
 	}
 }
 
+// drawRectangle fills a rectangle.
+func drawRectangle(r Renderer, rect Rectangle, c color.RGBA) {
+	r.Rect(rect.X, rect.Y, rect.Width, rect.Height, c)
+}
+
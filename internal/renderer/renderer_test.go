@@ -0,0 +1,164 @@
+package renderer
+
+import (
+	"particle-physics-simulator/internal/electrostatics"
+	"particle-physics-simulator/internal/particle"
+	"strings"
+	"testing"
+)
+
+func TestInput(t *testing.T) {
+	in := Input{
+		Pressed:        []Key{KeyA},
+		Repeated:       []Key{KeyRight},
+		Down:           []Key{KeyA, KeyRightShift},
+		ButtonsPressed: []MouseButton{MouseRight},
+	}
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"KeyPressed(A)", in.KeyPressed(KeyA), true},
+		{"KeyPressed(B)", in.KeyPressed(KeyB), false},
+		{"KeyPressedOrRepeated(Right)", in.KeyPressedOrRepeated(KeyRight), true},
+		{"KeyPressed(Right)", in.KeyPressed(KeyRight), false},
+		{"Shift", in.Shift(), true},
+		{"Control", in.Control(), false},
+		{"ButtonPressed(Right)", in.ButtonPressed(MouseRight), true},
+		{"ButtonDown(Right)", in.ButtonDown(MouseRight), false},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// Letters are polled with their ASCII codes
+	if KeyZ != 'Z' || Key9 != '9' {
+		t.Errorf("KeyZ = %d, Key9 = %d", KeyZ, Key9)
+	}
+}
+
+func TestNullFrames(t *testing.T) {
+	n := NewNull(100, 100)
+	n.Frames = []Input{Press(KeySpace), {}}
+
+	if !n.Input().KeyPressed(KeySpace) {
+		t.Error("first frame lost its input")
+	}
+	n.BeginFrame(black)
+	DrawScene(n, Scene{
+		Particles: []*particle.Particle{{X: 10, Y: 10, Radius: 2}},
+		Width:     100,
+		Height:    100,
+		Walls:     true,
+	})
+	n.EndFrame()
+	if n.Last.Circles != 1 || n.Last.Lines != 4 {
+		t.Errorf("frame drew %d circles and %d lines, want 1 and 4", n.Last.Circles, n.Last.Lines)
+	}
+	if n.Input().KeyPressed(KeySpace) || n.ShouldClose() {
+		t.Error("second frame should have no input and stay open")
+	}
+	n.EndFrame()
+	if !n.ShouldClose() {
+		t.Error("still open after the scripted frames")
+	}
+}
+
+func TestDrawWindowButtons(t *testing.T) {
+	n := NewNull(screenWidth, screenHeight)
+	n.Frames = []Input{Click(float64(screenWidth-2*buttonSize)+5, 5, MouseLeft)}
+	DrawWindowButtons(n)
+	if !n.Maximized || n.ShouldClose() {
+		t.Errorf("maximize button: maximized %v, closing %v", n.Maximized, n.ShouldClose())
+	}
+}
+
+func TestDrawParticleInfo(t *testing.T) {
+	n := NewNull(100, 100)
+	n.Frames = []Input{{MouseX: 52, MouseY: 50}}
+	particles := []*particle.Particle{{X: 50, Y: 50, Radius: 5, Mass: 3}}
+
+	n.BeginFrame(black)
+	DrawParticleInfo(n, particles)
+	n.EndFrame()
+	if len(n.Last.Texts) != 1 || !strings.HasPrefix(n.Last.Texts[0], "Mass: 3.00") {
+		t.Errorf("hover texts = %q", n.Last.Texts)
+	}
+}
+
+func TestDrawUIWraps(t *testing.T) {
+	wide := NewNull(4000, 900)
+	wide.BeginFrame(black)
+	DrawUI(wide, nil, false)
+	wide.EndFrame()
+
+	narrow := NewNull(640, 480)
+	narrow.BeginFrame(black)
+	DrawUI(narrow, nil, false)
+	narrow.EndFrame()
+
+	if len(narrow.Last.Texts) <= len(wide.Last.Texts) {
+		t.Fatalf("narrow window drew %d lines, wide %d", len(narrow.Last.Texts), len(wide.Last.Texts))
+	}
+	for _, s := range narrow.Last.Texts {
+		if width := narrow.TextWidth(s, instructionsSize); width > 620 {
+			t.Errorf("%q is %v wide, more than the window", s, width)
+		}
+	}
+}
+
+func TestMaximizeResizes(t *testing.T) {
+	n := NewNull(800, 600)
+	n.ScreenWidth, n.ScreenHeight = 1920, 1080
+	n.Frames = []Input{Click(float64(800-2*buttonSize)+5, 5, MouseLeft), Click(float64(1920-2*buttonSize)+5, 5, MouseLeft)}
+
+	DrawWindowButtons(n)
+	if w, h := n.Size(); w != 1920 || h != 1080 {
+		t.Errorf("maximised size %dx%d", w, h)
+	}
+	n.EndFrame()
+	DrawWindowButtons(n)
+	if w, h := n.Size(); w != 800 || h != 600 || n.Maximized {
+		t.Errorf("restored size %dx%d, maximised %v", w, h, n.Maximized)
+	}
+}
+
+func TestFieldLineCache(t *testing.T) {
+	particles := []*particle.Particle{
+		particle.NewCoulombParticle(100, 100, 0, 0, 0, 0, 1, 5, particle.Color{}, 1e-3, true),
+		particle.NewCoulombParticle(300, 100, 0, 0, 0, 0, 1, 5, particle.Color{}, -1e-3, true),
+	}
+	opts := electrostatics.DefaultFieldLineOptions(400, 200)
+	c := NewFieldLineCache()
+	lines := c.Trace(particles, opts, 0, 0)
+	if len(lines) == 0 {
+		t.Fatal("no field lines traced")
+	}
+
+	// The charges move while the simulation runs, but the lines are kept for a few steps
+	particles[0].X += 10
+	if again := c.Trace(particles, opts, FieldLineInterval-1, 0); &again[0] != &lines[0] {
+		t.Error("lines traced again within the interval")
+	}
+
+	wider := opts
+	wider.MaxX *= 2
+	for _, tc := range []struct {
+		name          string
+		step, version int
+		opts          electrostatics.FieldLineOptions
+	}{
+		{"interval passing", FieldLineInterval, 0, opts},
+		{"edit", FieldLineInterval, 1, opts},
+		{"step going back", 0, 1, opts},
+		{"options", 0, 1, wider},
+	} {
+		before := c.lines
+		if after := c.Trace(particles, tc.opts, tc.step, tc.version); &after[0] == &before[0] {
+			t.Errorf("lines not traced again after the %s", tc.name)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package renderer
+
+import "fmt"
+
+// rewindInstructions lists the rewind controls under the other instructions.
+const rewindInstructions = "Rewind: [R] Rewind/Resume | [Comma/Period] Step Back/Forward (Shift x10) | [Click Timeline] Scrub | [Space] Resume From Here"
+
+// DrawRewind draws the timeline of the recorded steps while rewinding, with progress from the
+// oldest step to the latest, and labels it with how far back the world has been taken.
+func DrawRewind(r Renderer, progress, back, held float64) {
+	DrawTimeline(r, progress)
+	bar := TimelineBounds(r.Size())
+	label := fmt.Sprintf("Rewind: -%.2f s of %.2f s held", back, held)
+	r.Text(label, bar.X, bar.Y-30, 20, yellow)
+}
@@ -0,0 +1,66 @@
+package renderer
+
+import (
+	"image/color"
+	"particle-physics-simulator/internal/electrostatics"
+	"particle-physics-simulator/internal/particle"
+)
+
+var wallColor = color.RGBA{R: 130, G: 130, B: 130, A: 255}
+
+// Scene is the simulated area: the particles with the selected overlays underneath.
+type Scene struct {
+	Particles []*particle.Particle
+	Width     float64
+	Height    float64
+	Overlays  Overlays
+	External  electrostatics.ExternalField // External electric field included in the overlays, or nil
+	Walls     bool                         // Outline the walled area
+	Trails    *Trails                      // Trails drawn under the particles, or nil
+
+	// FieldLines keeps the field lines between frames, or is nil to trace them every time.
+	// Step is the number of steps taken and Version counts the edits to the charges and
+	// fields, so the lines are traced again as time moves on or the scene is edited.
+	FieldLines *FieldLineCache
+	Step       int
+	Version    int
+
+	// ParticleColor returns the colour to draw each particle in, or is nil for its own Color.
+	ParticleColor func(p *particle.Particle) color.RGBA
+}
+
+// DrawScene draws the overlays, particles and walls of the scene.
+func DrawScene(r Renderer, s Scene) {
+	if s.Overlays.Potential {
+		cols, rows := int(s.Width)/PotentialCellSize, int(s.Height)/PotentialCellSize
+		DrawPotentialOverlay(r, electrostatics.SamplePotential(s.Particles, 0, 0, s.Width, s.Height, cols, rows))
+	}
+	if s.Overlays.ElectricField {
+		cols, rows := int(s.Width)/FieldGridSpacing, int(s.Height)/FieldGridSpacing
+		DrawElectricFieldOverlay(r, electrostatics.SampleElectricField(s.Particles, s.External, 0, 0, s.Width, s.Height, cols, rows))
+	}
+	if s.Overlays.FieldLines {
+		opts := electrostatics.DefaultFieldLineOptions(s.Width, s.Height)
+		opts.External = s.External
+		DrawFieldLines(r, s.FieldLines.Trace(s.Particles, opts, s.Step, s.Version))
+	}
+
+	if s.Trails != nil {
+		DrawTrails(r, s.Trails)
+	}
+
+	for _, p := range s.Particles {
+		if s.ParticleColor != nil {
+			r.Circle(p.X, p.Y, p.Radius, s.ParticleColor(p))
+		} else {
+			DrawParticle(r, p)
+		}
+	}
+
+	if s.Walls {
+		r.Line(0, 0, s.Width, 0, 2, wallColor)
+		r.Line(s.Width, 0, s.Width, s.Height, 2, wallColor)
+		r.Line(s.Width, s.Height, 0, s.Height, 2, wallColor)
+		r.Line(0, s.Height, 0, 0, 2, wallColor)
+	}
+}
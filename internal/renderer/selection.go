@@ -0,0 +1,52 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"particle-physics-simulator/internal/particle"
+)
+
+// selectionInstructions lists the selection controls under the other instructions.
+const selectionInstructions = "Selection: [Shift Click/Drag] Add | [Ctrl+A] All | [Esc] None | [Delete] Remove | [M] Pin/Unpin | [Z] Stop | [I] Kick to Mouse | [Ctrl+C/V] Copy/Paste | [E] Edit"
+
+// selectionBoxColor fills the box dragged out to select particles.
+var selectionBoxColor = color.RGBA{R: 102, G: 191, B: 255, A: 40}
+
+// DrawSelectionBox draws the box being dragged out to select particles.
+func DrawSelectionBox(r Renderer, box Rectangle) {
+	drawRectangle(r, box, selectionBoxColor)
+	drawOutline(r, box, skyBlue)
+}
+
+// DrawSelectionInfo shows the number of selected particles and their totals in the bottom
+// left corner.
+func DrawSelectionInfo(r Renderer, selected []*particle.Particle) {
+	if len(selected) == 0 {
+		return
+	}
+
+	pinned := 0
+	var mass, charge, energy, px, py, cx, cy float64
+	for _, p := range selected {
+		if !p.Movable {
+			pinned++
+		}
+		mass += p.Mass
+		charge += p.Charge
+		energy += p.KineticEnergy()
+		px += p.Mass * p.Vx
+		py += p.Mass * p.Vy
+		cx += p.Mass * p.X
+		cy += p.Mass * p.Y
+	}
+
+	_, height := r.Size()
+	count := fmt.Sprintf("Selected: %d (%d pinned)", len(selected), pinned)
+	totals := fmt.Sprintf("Mass %.3g | Charge %.3g | Kinetic Energy %.4g | Momentum (%.3g, %.3g)", mass, charge, energy, px, py)
+	if mass > 0 {
+		totals += fmt.Sprintf(" | Centre of Mass (%.0f, %.0f)", cx/mass, cy/mass)
+	}
+	// Above the rewind timeline and its label
+	r.Text(count, 10, float64(height)-110, 20, white)
+	r.Text(totals, 10, float64(height)-88, 15, white)
+}
@@ -0,0 +1,189 @@
+package renderer
+
+import (
+	"image/color"
+	"maps"
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"slices"
+)
+
+// DefaultTrailLength is the number of positions kept for each trail, two seconds at the
+// interactive frame rate.
+const DefaultTrailLength = 240
+
+const (
+	trailWidth    = 1.5 // Width of trails not drawn by speed
+	maxTrailWidth = 5.0 // Width of the fastest segment when drawn by speed
+)
+
+// Trail is a fixed-length history of a particle's positions. Once full, each new position
+// overwrites the oldest.
+type Trail struct {
+	Color color.RGBA // Colour of the particle when last recorded
+
+	points []trailPoint
+	next   int // Index the next point is written to
+	full   bool
+	seen   int // Generation of Trails.Record that last saw the particle
+}
+
+type trailPoint struct {
+	x, y, speed float64
+}
+
+// NewTrail creates an empty trail holding up to length positions.
+func NewTrail(length int) *Trail {
+	return &Trail{points: make([]trailPoint, max(length, 2))}
+}
+
+// Add appends a position, dropping the oldest if the trail is full.
+func (t *Trail) Add(x, y, speed float64) {
+	t.points[t.next] = trailPoint{x, y, speed}
+	t.next++
+	if t.next == len(t.points) {
+		t.next = 0
+		t.full = true
+	}
+}
+
+// Len returns the number of positions held.
+func (t *Trail) Len() int {
+	if t.full {
+		return len(t.points)
+	}
+	return t.next
+}
+
+// At returns the i-th position held, oldest first, and the speed there.
+func (t *Trail) At(i int) (x, y, speed float64) {
+	if t.full {
+		i = (t.next + i) % len(t.points)
+	}
+	p := t.points[i]
+	return p.x, p.y, p.speed
+}
+
+// Points returns the positions held, oldest first.
+func (t *Trail) Points() []Point {
+	points := make([]Point, t.Len())
+	for i := range points {
+		points[i].X, points[i].Y, _ = t.At(i)
+	}
+	return points
+}
+
+// Trails records the trail of every particle and chooses which are drawn: all of them, or
+// only those of particles switched on one at a time.
+type Trails struct {
+	Length       int          // Positions kept per trail
+	All          bool         // Show every particle's trail
+	Shown        map[int]bool // IDs of particles whose trail is shown when All is off
+	Fade         bool         // Fade older positions out
+	WidthBySpeed bool         // Draw faster segments wider
+
+	trails     map[int]*Trail
+	generation int
+}
+
+// NewTrails creates trails of the given length that fade out, with none shown yet.
+func NewTrails(length int) *Trails {
+	return &Trails{
+		Length: length,
+		Shown:  map[int]bool{},
+		Fade:   true,
+		trails: map[int]*Trail{},
+	}
+}
+
+// Record adds the current position of every particle to its trail, assigning IDs to
+// particles without one. Trails of particles that are gone are dropped.
+func (t *Trails) Record(particles []*particle.Particle) {
+	particle.AssignIDs(particles)
+	t.generation++
+	for _, p := range particles {
+		trail, ok := t.trails[p.ID]
+		if !ok {
+			trail = NewTrail(t.Length)
+			t.trails[p.ID] = trail
+		}
+		trail.Add(p.X, p.Y, math.Hypot(p.Vx, p.Vy))
+		trail.Color = p.Color.RGBA8()
+		trail.seen = t.generation
+	}
+
+	for id, trail := range t.trails {
+		if trail.seen != t.generation {
+			delete(t.trails, id)
+			delete(t.Shown, id)
+		}
+	}
+}
+
+// Clear empties every trail, e.g. after particles jump.
+func (t *Trails) Clear() {
+	clear(t.trails)
+}
+
+// Trail returns the trail of the particle with the given ID, or nil.
+func (t *Trails) Trail(id int) *Trail {
+	return t.trails[id]
+}
+
+// IsShown reports whether the trail of the particle with the given ID is drawn.
+func (t *Trails) IsShown(id int) bool {
+	return t.All || t.Shown[id]
+}
+
+// Toggle switches the trail of one particle on or off.
+func (t *Trails) Toggle(id int) {
+	if t.Shown[id] {
+		delete(t.Shown, id)
+	} else {
+		t.Shown[id] = true
+	}
+}
+
+// Visible returns the trails that are drawn, in order of particle ID.
+func (t *Trails) Visible() []*Trail {
+	var visible []*Trail
+	for _, id := range slices.Sorted(maps.Keys(t.trails)) {
+		if trail := t.trails[id]; t.IsShown(id) && trail.Len() > 1 {
+			visible = append(visible, trail)
+		}
+	}
+	return visible
+}
+
+// DrawTrails draws the visible trails in their particles' colours.
+func DrawTrails(r Renderer, t *Trails) {
+	visible := t.Visible()
+
+	maxSpeed := 0.0
+	if t.WidthBySpeed {
+		for _, trail := range visible {
+			for i := 0; i < trail.Len(); i++ {
+				_, _, speed := trail.At(i)
+				maxSpeed = math.Max(maxSpeed, speed)
+			}
+		}
+	}
+
+	for _, trail := range visible {
+		n := trail.Len()
+		x0, y0, _ := trail.At(0)
+		for i := 1; i < n; i++ {
+			x1, y1, speed := trail.At(i)
+			c := trail.Color
+			if t.Fade {
+				c.A = uint8(float64(c.A) * float64(i) / float64(n-1))
+			}
+			width := trailWidth
+			if maxSpeed > 0 {
+				width = 1 + (maxTrailWidth-1)*speed/maxSpeed
+			}
+			r.Line(x0, y0, x1, y1, width, c)
+			x0, y0 = x1, y1
+		}
+	}
+}
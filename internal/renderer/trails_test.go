@@ -0,0 +1,81 @@
+package renderer
+
+import (
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+func TestTrailWraps(t *testing.T) {
+	trail := NewTrail(3)
+	for i := 0; i < 5; i++ {
+		trail.Add(float64(i), 0, 0)
+	}
+	if trail.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", trail.Len())
+	}
+	points := trail.Points()
+	for i, want := range []float64{2, 3, 4} {
+		if points[i].X != want {
+			t.Errorf("point %d = %v, want x %v", i, points[i], want)
+		}
+	}
+}
+
+func TestTrailsRecord(t *testing.T) {
+	a := &particle.Particle{ID: 1, X: 0, Vx: 3, Vy: 4}
+	b := &particle.Particle{ID: 2, X: 10}
+	trails := NewTrails(10)
+	trails.Toggle(2)
+
+	trails.Record([]*particle.Particle{a, b})
+	a.X, b.X = 1, 11
+	trails.Record([]*particle.Particle{a, b})
+
+	if got := trails.Trail(1).Len(); got != 2 {
+		t.Errorf("trail of a holds %d points, want 2", got)
+	}
+	if _, _, speed := trails.Trail(1).At(1); speed != 5 {
+		t.Errorf("speed = %v, want 5", speed)
+	}
+	// Only b's trail is switched on
+	if visible := trails.Visible(); len(visible) != 1 || visible[0] != trails.Trail(2) {
+		t.Errorf("visible trails = %v, want b's", visible)
+	}
+	trails.All = true
+	if got := len(trails.Visible()); got != 2 {
+		t.Errorf("%d visible trails with All, want 2", got)
+	}
+
+	// Removing b drops its trail and its toggle
+	trails.Record([]*particle.Particle{a})
+	if trails.Trail(2) != nil || trails.Shown[2] {
+		t.Error("trail of the removed particle kept")
+	}
+
+	// Particles without an ID are given one
+	c := &particle.Particle{}
+	trails.Record([]*particle.Particle{a, c})
+	if c.ID == 0 || trails.Trail(c.ID) == nil {
+		t.Error("particle without an ID has no trail")
+	}
+}
+
+func TestDrawTrails(t *testing.T) {
+	p := &particle.Particle{ID: 1}
+	trails := NewTrails(5)
+	trails.All = true
+	trails.WidthBySpeed = true
+	for i := 0; i < 8; i++ {
+		p.X = float64(i)
+		trails.Record([]*particle.Particle{p})
+	}
+
+	n := NewNull(100, 100)
+	n.BeginFrame(black)
+	DrawTrails(n, trails)
+	n.EndFrame()
+	// Five points make four segments
+	if n.Last.Lines != 4 {
+		t.Errorf("drew %d lines, want 4", n.Last.Lines)
+	}
+}
@@ -0,0 +1,107 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Immediate-mode widgets: each call draws the widget and handles this frame's input for it,
+// so panels are laid out and updated by the same code every frame.
+
+const (
+	widgetTextSize  = 15
+	widgetLabelSize = 80 // Width of the label left of a slider
+)
+
+var (
+	panelColor       = color.RGBA{R: 20, G: 20, B: 30, A: 220}
+	widgetColor      = color.RGBA{R: 55, G: 55, B: 70, A: 255}
+	widgetHoverColor = color.RGBA{R: 80, G: 80, B: 100, A: 255}
+)
+
+// DrawPanel fills the background of a panel and outlines it.
+func DrawPanel(r Renderer, bounds Rectangle) {
+	drawRectangle(r, bounds, panelColor)
+	drawOutline(r, bounds, darkGray)
+}
+
+// Button draws a button with a centred label and reports whether it was clicked.
+func Button(r Renderer, in Input, bounds Rectangle, label string) bool {
+	hover := bounds.Contains(in.MouseX, in.MouseY)
+	c := widgetColor
+	if hover {
+		c = widgetHoverColor
+	}
+	drawRectangle(r, bounds, c)
+	width := r.TextWidth(label, widgetTextSize)
+	r.Text(label, bounds.X+(bounds.Width-width)/2, bounds.Y+(bounds.Height-widgetTextSize)/2, widgetTextSize, white)
+	return hover && in.ButtonPressed(MouseLeft)
+}
+
+// Slider draws a labelled slider for value between lo and hi and returns the value, set from
+// the mouse while the left button is held on the slider and rounded to a multiple of step.
+func Slider(r Renderer, in Input, bounds Rectangle, label string, value, lo, hi, step float64) float64 {
+	track := bounds
+	track.X += widgetLabelSize
+	track.Width -= widgetLabelSize
+
+	if track.Contains(in.MouseX, in.MouseY) && in.ButtonDown(MouseLeft) {
+		value = lo + (hi-lo)*(in.MouseX-track.X)/track.Width
+		if step > 0 {
+			value = math.Round(value/step) * step
+		}
+		value = math.Max(lo, math.Min(hi, value))
+	}
+
+	r.Text(label, bounds.X, bounds.Y+(bounds.Height-widgetTextSize)/2, widgetTextSize, white)
+	drawRectangle(r, track, widgetColor)
+	filled := track
+	filled.Width = track.Width * math.Max(0, math.Min(1, (value-lo)/(hi-lo)))
+	drawRectangle(r, filled, skyBlue)
+	text := fmt.Sprintf("%.3g", value)
+	r.Text(text, track.X+track.Width-r.TextWidth(text, widgetTextSize)-4, track.Y+(track.Height-widgetTextSize)/2, widgetTextSize, white)
+	return value
+}
+
+// Checkbox draws a labelled box, ticked when checked, and returns the state after a click.
+func Checkbox(r Renderer, in Input, bounds Rectangle, label string, checked bool) bool {
+	if bounds.Contains(in.MouseX, in.MouseY) && in.ButtonPressed(MouseLeft) {
+		checked = !checked
+	}
+	box := Rectangle{X: bounds.X, Y: bounds.Y + (bounds.Height-16)/2, Width: 16, Height: 16}
+	drawRectangle(r, box, widgetColor)
+	if checked {
+		inner := Rectangle{X: box.X + 4, Y: box.Y + 4, Width: box.Width - 8, Height: box.Height - 8}
+		drawRectangle(r, inner, skyBlue)
+	}
+	r.Text(label, box.X+box.Width+8, bounds.Y+(bounds.Height-widgetTextSize)/2, widgetTextSize, white)
+	return checked
+}
+
+// Swatches draws a row of colour swatches, outlining the one equal to current, and returns
+// the index of the one clicked, or -1.
+func Swatches(r Renderer, in Input, bounds Rectangle, colors []color.RGBA, current color.RGBA) int {
+	clicked := -1
+	size := bounds.Width / float64(len(colors))
+	for i, c := range colors {
+		swatch := Rectangle{X: bounds.X + float64(i)*size + 2, Y: bounds.Y + 2, Width: size - 4, Height: bounds.Height - 4}
+		drawRectangle(r, swatch, c)
+		if c == current {
+			drawOutline(r, swatch, white)
+		}
+		if swatch.Contains(in.MouseX, in.MouseY) && in.ButtonPressed(MouseLeft) {
+			clicked = i
+		}
+	}
+	return clicked
+}
+
+// drawOutline draws the edges of a rectangle.
+func drawOutline(r Renderer, rect Rectangle, c color.RGBA) {
+	x0, y0, x1, y1 := rect.X, rect.Y, rect.X+rect.Width, rect.Y+rect.Height
+	r.Line(x0, y0, x1, y0, 1, c)
+	r.Line(x1, y0, x1, y1, 1, c)
+	r.Line(x1, y1, x0, y1, 1, c)
+	r.Line(x0, y1, x0, y0, 1, c)
+}
@@ -0,0 +1,328 @@
+// Package rewind keeps the recent history of a world in memory so the interactive
+// simulation can go back to an earlier step and carry on from there.
+package rewind
+
+import (
+	"encoding/binary"
+	"math"
+	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/world"
+	"unsafe"
+)
+
+const (
+	// DefaultBudget is the memory kept for snapshots. A moving particle takes about 15 bytes
+	// a step between keyframes and 150 in a keyframe, so this holds over two minutes of the
+	// demo scene's 201 particles, or about three seconds of ten thousand.
+	DefaultBudget = 64 << 20
+
+	// DefaultKeyframeInterval is the number of steps between full snapshots.
+	DefaultKeyframeInterval = 60
+
+	// Resolution is the step that positions in pixels and velocities in pixels per second are
+	// rounded to between keyframes.
+	Resolution = 1e-6
+)
+
+// channels are the quantities stored in deltas: x, y, z, vx, vy, vz.
+const channels = 6
+
+// Buffer is a rolling buffer of world snapshots within a memory budget. Each step is recorded
+// as a keyframe holding every particle in full, or as a delta holding how the positions and
+// velocities changed since the step before, rounded to Resolution and varint-encoded, and
+// which particles landed on or left the floor. Accelerations and forces aren't stored in
+// deltas: a step only changes them through gravity, which is set again from whether a
+// particle is on the floor. A keyframe is taken every KeyframeInterval steps and whenever
+// particles are added, removed or changed. Once over budget the oldest keyframe and its
+// deltas are dropped.
+type Buffer struct {
+	Budget           int // Bytes of snapshots kept
+	KeyframeInterval int
+
+	groups []group // Oldest first
+	frames int
+	size   int
+}
+
+// group is a keyframe and the deltas recorded after it.
+type group struct {
+	key    keyframe
+	deltas []delta
+
+	// State of the newest frame, which the next delta is taken from. Only the newest
+	// group keeps it.
+	last *state
+}
+
+type keyframe struct {
+	particles []particle.Particle
+	time      float64
+	steps     int
+}
+
+type delta struct {
+	data  []byte
+	time  float64
+	steps int
+}
+
+// state is a frame with its positions and velocities rounded to Resolution.
+type state struct {
+	values   [channels][]int64
+	grounded []bool
+}
+
+var (
+	particleSize = int(unsafe.Sizeof(particle.Particle{}))
+	deltaSize    = int(unsafe.Sizeof(delta{}))
+)
+
+// New creates an empty buffer keeping up to budget bytes of snapshots.
+func New(budget int) *Buffer {
+	return &Buffer{Budget: budget, KeyframeInterval: DefaultKeyframeInterval}
+}
+
+// Len returns the number of steps held.
+func (b *Buffer) Len() int {
+	return b.frames
+}
+
+// Size returns the memory used by the snapshots in bytes.
+func (b *Buffer) Size() int {
+	return b.size
+}
+
+// Record adds the current state of w as the newest step.
+func (b *Buffer) Record(w *world.World) {
+	if n := len(b.groups); n > 0 && len(b.groups[n-1].deltas)+1 < max(b.KeyframeInterval, 1) && b.groups[n-1].key.matches(w.Particles) {
+		g := &b.groups[n-1]
+		next := stateOf(w.Particles)
+		d := delta{data: g.last.diff(next), time: w.Time, steps: w.Steps}
+		g.deltas = append(g.deltas, d)
+		g.last = next
+		b.size += d.size()
+	} else {
+		if n > 0 {
+			b.groups[n-1].last = nil
+		}
+		key := keyframe{particles: make([]particle.Particle, len(w.Particles)), time: w.Time, steps: w.Steps}
+		for i, p := range w.Particles {
+			key.particles[i] = *p
+		}
+		b.groups = append(b.groups, group{key: key, last: stateOf(w.Particles)})
+		b.size += len(key.particles) * particleSize
+	}
+	b.frames++
+
+	// Keep at least the newest keyframe, however large
+	for b.size > b.Budget && len(b.groups) > 1 {
+		b.size -= b.groups[0].size()
+		b.frames -= b.groups[0].len()
+		b.groups = b.groups[1:]
+	}
+}
+
+// Time returns the simulation time of step i, counting from the oldest held.
+func (b *Buffer) Time(i int) float64 {
+	g, j := b.find(i)
+	if j == 0 {
+		return g.key.time
+	}
+	return g.deltas[j-1].time
+}
+
+// Restore sets w to its state at step i, counting from the oldest held. The particles are
+// new copies, with the IDs they had then.
+func (b *Buffer) Restore(i int, w *world.World) {
+	g, j := b.find(i)
+	particles := make([]*particle.Particle, len(g.key.particles))
+	for k := range g.key.particles {
+		p := g.key.particles[k]
+		particles[k] = &p
+	}
+	w.Time, w.Steps = g.key.time, g.key.steps
+	if j > 0 {
+		g.state(j).apply(particles)
+		w.Time, w.Steps = g.deltas[j-1].time, g.deltas[j-1].steps
+	}
+	w.Particles = particles
+}
+
+// Truncate drops the steps after step i, so recording carries on from there.
+func (b *Buffer) Truncate(i int) {
+	if i >= b.frames-1 {
+		return
+	}
+	n, j := b.index(i)
+	g := &b.groups[n]
+	for _, later := range b.groups[n+1:] {
+		b.size -= later.size()
+	}
+	for _, d := range g.deltas[j:] {
+		b.size -= d.size()
+	}
+	g.deltas = g.deltas[:j]
+	g.last = g.state(j)
+	b.groups = b.groups[:n+1]
+	b.frames = i + 1
+}
+
+// Clear empties the buffer.
+func (b *Buffer) Clear() {
+	b.groups = nil
+	b.frames = 0
+	b.size = 0
+}
+
+// find returns the group holding step i and its position in the group, 0 for the keyframe.
+func (b *Buffer) find(i int) (*group, int) {
+	n, j := b.index(i)
+	return &b.groups[n], j
+}
+
+func (b *Buffer) index(i int) (int, int) {
+	i = max(0, min(i, b.frames-1))
+	for n, g := range b.groups {
+		if i < g.len() {
+			return n, i
+		}
+		i -= g.len()
+	}
+	panic("rewind: step out of range of an empty buffer")
+}
+
+func (g *group) len() int {
+	return 1 + len(g.deltas)
+}
+
+func (g *group) size() int {
+	size := len(g.key.particles) * particleSize
+	for _, d := range g.deltas {
+		size += d.size()
+	}
+	return size
+}
+
+// state decodes the state of frame j of the group, adding up the deltas before it.
+func (g *group) state(j int) *state {
+	particles := make([]*particle.Particle, len(g.key.particles))
+	for k := range g.key.particles {
+		particles[k] = &g.key.particles[k]
+	}
+	s := stateOf(particles)
+	for _, d := range g.deltas[:j] {
+		s.add(d.data)
+	}
+	return s
+}
+
+func (d delta) size() int {
+	return deltaSize + cap(d.data)
+}
+
+// matches reports whether particles are those of the keyframe with the same properties, so
+// a delta is enough to record them.
+func (k *keyframe) matches(particles []*particle.Particle) bool {
+	if len(particles) != len(k.particles) {
+		return false
+	}
+	for i, p := range particles {
+		if p.ID != k.particles[i].ID || p.Properties() != k.particles[i].Properties() {
+			return false
+		}
+	}
+	return true
+}
+
+func stateOf(particles []*particle.Particle) *state {
+	s := &state{grounded: make([]bool, len(particles))}
+	for c := range s.values {
+		s.values[c] = make([]int64, len(particles))
+	}
+	for i, p := range particles {
+		for c, v := range [channels]float64{p.X, p.Y, p.Z, p.Vx, p.Vy, p.Vz} {
+			s.values[c][i] = quantise(v)
+		}
+		s.grounded[i] = p.IsGrounded
+	}
+	return s
+}
+
+// diff encodes the change from s to next: the number of particles whose IsGrounded changed
+// and their indices, then the change of every value, one channel at a time so that similar
+// numbers sit together.
+func (s *state) diff(next *state) []byte {
+	var changed []int
+	for i := range s.grounded {
+		if s.grounded[i] != next.grounded[i] {
+			changed = append(changed, i)
+		}
+	}
+	buf := binary.AppendUvarint(nil, uint64(len(changed)))
+	for _, i := range changed {
+		buf = binary.AppendUvarint(buf, uint64(i))
+	}
+	for c := range s.values {
+		for i, v := range next.values[c] {
+			buf = binary.AppendVarint(buf, v-s.values[c][i])
+		}
+	}
+	// Deltas are kept for a while, so don't hold on to the spare capacity
+	return buf[:len(buf):len(buf)]
+}
+
+// add applies a change encoded by diff.
+func (s *state) add(data []byte) {
+	read := func() int64 {
+		v, n := binary.Varint(data)
+		data = data[n:]
+		return v
+	}
+	changed, n := binary.Uvarint(data)
+	data = data[n:]
+	for ; changed > 0; changed-- {
+		i, n := binary.Uvarint(data)
+		data = data[n:]
+		s.grounded[i] = !s.grounded[i]
+	}
+	for c := range s.values {
+		for i := range s.values[c] {
+			s.values[c][i] += read()
+		}
+	}
+}
+
+// apply sets the motion of the particles to s. Gravity's acceleration is set as a step
+// leaves it: none on the floor, Gravity in the air.
+func (s *state) apply(particles []*particle.Particle) {
+	for i, p := range particles {
+		p.X, p.Y, p.Z = dequantise(s.values[0][i]), dequantise(s.values[1][i]), dequantise(s.values[2][i])
+		p.Vx, p.Vy, p.Vz = dequantise(s.values[3][i]), dequantise(s.values[4][i]), dequantise(s.values[5][i])
+		p.IsGrounded = s.grounded[i]
+		if p.Movable {
+			p.Ay = constants.Gravity
+			if p.IsGrounded {
+				p.Ay = 0
+			}
+		}
+	}
+}
+
+// quantise rounds v to a multiple of Resolution, saturating far outside the int64 range.
+func quantise(v float64) int64 {
+	q := math.Round(v / Resolution)
+	switch {
+	case math.IsNaN(q):
+		return 0
+	case q >= math.MaxInt64:
+		return math.MaxInt64
+	case q <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(q)
+}
+
+func dequantise(q int64) float64 {
+	return float64(q) * Resolution
+}
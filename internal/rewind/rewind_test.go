@@ -0,0 +1,176 @@
+package rewind
+
+import (
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/world"
+	"testing"
+)
+
+const dt = 1.0 / 120
+
+func newWorld() *world.World {
+	return world.New([]*particle.Particle{
+		particle.NewParticle(100, 100, 50, 0, 0, 0, 1, 5, particle.Color{A: 1}, true),
+		particle.NewParticle(300, 200, -20, 10, 0, 0, 2, 8, particle.Color{A: 1}, true),
+	}, 800, 600)
+}
+
+func TestRestore(t *testing.T) {
+	w := newWorld()
+	b := New(DefaultBudget)
+	b.KeyframeInterval = 4
+	var states [][]particle.Particle
+	for i := 0; i < 10; i++ {
+		b.Record(w)
+		state := make([]particle.Particle, len(w.Particles))
+		for j, p := range w.Particles {
+			state[j] = *p
+		}
+		states = append(states, state)
+		w.Step(dt)
+	}
+	if b.Len() != 10 {
+		t.Fatalf("%d steps held, want 10", b.Len())
+	}
+
+	for _, i := range []int{0, 3, 4, 7, 9} {
+		got := world.New(nil, 800, 600)
+		b.Restore(i, got)
+		if want := float64(i) * dt; got.Time < want-1e-9 || got.Time > want+1e-9 || got.Steps != i {
+			t.Errorf("step %d restored at time %v after %d steps, want %v after %d", i, got.Time, got.Steps, want, i)
+		}
+		for j, p := range got.Particles {
+			if !same(*p, states[i][j]) {
+				t.Errorf("step %d particle %d restored as %+v, want %+v", i, j, *p, states[i][j])
+			}
+		}
+	}
+
+	// Restored particles are copies, so stepping them leaves the buffer as it was
+	b.Restore(9, w)
+	w.Step(dt)
+	b.Restore(9, w)
+	if !same(*w.Particles[0], states[9][0]) {
+		t.Error("stepping a restored world changed the step held")
+	}
+}
+
+// same reports whether a restored particle matches the recorded one, with its position and
+// velocity to within Resolution.
+func same(got, want particle.Particle) bool {
+	for _, v := range [][2]float64{{got.X, want.X}, {got.Y, want.Y}, {got.Z, want.Z}, {got.Vx, want.Vx}, {got.Vy, want.Vy}, {got.Vz, want.Vz}} {
+		if math.Abs(v[0]-v[1]) > Resolution {
+			return false
+		}
+	}
+	got.X, got.Y, got.Z, got.Vx, got.Vy, got.Vz = want.X, want.Y, want.Z, want.Vx, want.Vy, want.Vz
+	return got == want
+}
+
+func TestDeltasAreSmall(t *testing.T) {
+	particles := make([]*particle.Particle, 100)
+	for i := range particles {
+		particles[i] = particle.NewParticle(float64(50+7*i), 100, float64(i-50), 0, 0, 0, 1, 3, particle.Color{A: 1}, true)
+	}
+	w := world.New(particles, 800, 600)
+	b := New(DefaultBudget)
+	b.Record(w)
+	keyframe := b.Size()
+	for i := 0; i < 10; i++ {
+		w.Step(dt)
+		b.Record(w)
+	}
+
+	perParticle := float64(b.Size()-keyframe) / 10 / 100
+	if full := float64(keyframe) / 100; perParticle > full/4 {
+		t.Errorf("a step takes %.1f bytes a particle, want well under the %.0f of a keyframe", perParticle, full)
+	}
+}
+
+func TestRecordKeyframeOnEdit(t *testing.T) {
+	w := newWorld()
+	b := New(DefaultBudget)
+	b.Record(w)
+	w.Particles = append(w.Particles, particle.NewParticle(500, 100, 0, 0, 0, 0, 1, 5, particle.Color{A: 1}, true))
+	b.Record(w)
+	w.Particles[0].Mass = 10
+	b.Record(w)
+
+	if len(b.groups) != 3 {
+		t.Errorf("%d keyframes after adding and changing particles, want 3", len(b.groups))
+	}
+	got := world.New(nil, 800, 600)
+	b.Restore(0, got)
+	if len(got.Particles) != 2 {
+		t.Errorf("first step restored with %d particles, want 2", len(got.Particles))
+	}
+	b.Restore(1, got)
+	if len(got.Particles) != 3 || got.Particles[0].Mass != 1 {
+		t.Errorf("second step restored with %d particles and mass %v, want 3 and 1", len(got.Particles), got.Particles[0].Mass)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	w := newWorld()
+	// Measure a keyframe with its deltas, then allow three
+	b := New(DefaultBudget)
+	b.KeyframeInterval = 5
+	measured := newWorld()
+	for i := 0; i < 5; i++ {
+		b.Record(measured)
+		measured.Step(dt)
+	}
+	b = New(3 * b.Size())
+	b.KeyframeInterval = 5
+	for i := 0; i < 100; i++ {
+		b.Record(w)
+		w.Step(dt)
+		if b.Size() > b.Budget {
+			t.Fatalf("%d bytes held after %d steps, over the budget of %d", b.Size(), i+1, b.Budget)
+		}
+	}
+	if b.Len() == 0 || b.Len() >= 100 {
+		t.Errorf("%d steps held, want the latest few", b.Len())
+	}
+
+	// The newest step is always held, with the oldest kept step still restorable
+	got := world.New(nil, 800, 600)
+	b.Restore(b.Len()-1, got)
+	if got.Steps != 99 {
+		t.Errorf("latest step held is %d, want 99", got.Steps)
+	}
+	b.Restore(0, got)
+	if got.Steps != 100-b.Len() {
+		t.Errorf("oldest step held is %d, want %d", got.Steps, 100-b.Len())
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	w := newWorld()
+	b := New(DefaultBudget)
+	b.KeyframeInterval = 4
+	for i := 0; i < 10; i++ {
+		b.Record(w)
+		w.Step(dt)
+	}
+	full := b.Size()
+
+	b.Truncate(5)
+	if b.Len() != 6 {
+		t.Errorf("%d steps held after truncating at 5, want 6", b.Len())
+	}
+	if b.Size() >= full {
+		t.Errorf("%d bytes held after truncating, want fewer than %d", b.Size(), full)
+	}
+
+	// Recording carries on after the step truncated at
+	b.Restore(5, w)
+	w.Step(dt)
+	b.Record(w)
+	got := world.New(nil, 800, 600)
+	b.Restore(6, got)
+	if got.Steps != 6 {
+		t.Errorf("step after truncating is %d, want 6", got.Steps)
+	}
+}
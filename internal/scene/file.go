@@ -0,0 +1,137 @@
+package scene
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"particle-physics-simulator/internal/force"
+	"particle-physics-simulator/internal/particle"
+)
+
+// File is a scene as written in a JSON scene file: the particles to start with and the
+// fields and forces acting on them, with every field, force and spawner given as
+// expressions. For example:
+//
+//	{
+//	  "params":   {"E0": 200, "w": 3},
+//	  "electric": [{"ex": "E0 * sin(w * t)", "ey": "0"}],
+//	  "magnetic": [{"bz": "0.05"}],
+//	  "forces":   [{"fx": "-0.5 * vx", "fy": "-0.5 * vy"}],
+//	  "spawners": [{"count": 50, "x": "400 + 150 * cos(2 * pi * i / n)",
+//	                "y": "300 + 150 * sin(2 * pi * i / n)", "charge": "0.1 * g"}]
+//	}
+//
+// Field expressions use the variables in force.FieldVariables, forces those in
+// force.ForceVariables and spawners those in SpawnVariables. All may use the params.
+type File struct {
+	Params   map[string]float64 `json:"params"`
+	Seed     int64              `json:"seed"` // Seeds the spawners' random numbers
+	Demo     bool               `json:"demo"` // Start with the particles of Demo as well
+	Electric []ElectricSpec     `json:"electric"`
+	Magnetic []MagneticSpec     `json:"magnetic"`
+	Forces   []ForceSpec        `json:"forces"`
+	Spawners []SpawnerSpec      `json:"spawners"`
+}
+
+// ElectricSpec is an electric field given by expressions for its components.
+type ElectricSpec struct {
+	Ex string `json:"ex"`
+	Ey string `json:"ey"`
+}
+
+// MagneticSpec is a magnetic field given by expressions for its components. Bx and By may be
+// left out for a field along z only.
+type MagneticSpec struct {
+	Bx string `json:"bx"`
+	By string `json:"by"`
+	Bz string `json:"bz"`
+}
+
+// ForceSpec is a custom force given by expressions for its components.
+type ForceSpec struct {
+	Fx string `json:"fx"`
+	Fy string `json:"fy"`
+}
+
+// Scene is a loaded scene file.
+type Scene struct {
+	Particles []*particle.Particle
+	Fields    force.ExternalFields
+}
+
+// Load reads and builds the scene file at path.
+func Load(path string) (Scene, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scene{}, err
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return Scene{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse builds a scene from the contents of a scene file.
+func Parse(data []byte) (Scene, error) {
+	var f File
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&f); err != nil {
+		return Scene{}, err
+	}
+	return f.Build()
+}
+
+// Build compiles the expressions of f and spawns its particles.
+func (f File) Build() (Scene, error) {
+	var s Scene
+	for i, spec := range f.Electric {
+		field, err := force.NewExprElectricField(orZero(spec.Ex), orZero(spec.Ey), f.Params)
+		if err != nil {
+			return Scene{}, fmt.Errorf("electric[%d]: %w", i, err)
+		}
+		s.Fields.Electric = append(s.Fields.Electric, field)
+	}
+	for i, spec := range f.Magnetic {
+		field, err := force.NewExprMagneticField3D(orZero(spec.Bx), orZero(spec.By), orZero(spec.Bz), f.Params)
+		if err != nil {
+			return Scene{}, fmt.Errorf("magnetic[%d]: %w", i, err)
+		}
+		s.Fields.Magnetic = append(s.Fields.Magnetic, field)
+	}
+	for i, spec := range f.Forces {
+		custom, err := force.NewExprForce(orZero(spec.Fx), orZero(spec.Fy), f.Params)
+		if err != nil {
+			return Scene{}, fmt.Errorf("forces[%d]: %w", i, err)
+		}
+		s.Fields.Forces = append(s.Fields.Forces, custom)
+	}
+
+	if f.Demo {
+		s.Particles = Demo()
+	}
+	rng := rand.New(rand.NewSource(f.Seed))
+	for i, spec := range f.Spawners {
+		spawner, err := NewSpawner(spec, f.Params)
+		if err != nil {
+			return Scene{}, fmt.Errorf("spawners[%d]: %w", i, err)
+		}
+		particles, err := spawner.Spawn(rng)
+		if err != nil {
+			return Scene{}, fmt.Errorf("spawners[%d]: %w", i, err)
+		}
+		s.Particles = append(s.Particles, particles...)
+	}
+	return s, nil
+}
+
+// orZero returns src, or "0" for a component left out.
+func orZero(src string) string {
+	if src == "" {
+		return "0"
+	}
+	return src
+}
@@ -0,0 +1,105 @@
+package scene
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"particle-physics-simulator/internal/particle"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const ringScene = `{
+	"params":   {"E0": 200, "R": 150},
+	"seed":     7,
+	"electric": [{"ex": "E0 * t", "ey": "-E0"}],
+	"magnetic": [{"bz": "0.05"}],
+	"forces":   [{"fx": "-0.5 * vx"}],
+	"spawners": [{"count": 8, "x": "400 + R * cos(2 * pi * i / n)", "y": "300 + R * sin(2 * pi * i / n)",
+	              "vy": "10 * g", "charge": "u - 0.5", "color": {"R": 1, "A": 1}, "movable": false}]
+}`
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(ringScene))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ex, ey := s.Fields.ElectricFieldAt(0, 0, 2); ex != 400 || ey != -200 {
+		t.Errorf("electric field (%v, %v), want (400, -200)", ex, ey)
+	}
+	if bx, by, bz := s.Fields.MagneticFieldAt(0, 0, 0); bx != 0 || by != 0 || bz != 0.05 {
+		t.Errorf("magnetic field (%v, %v, %v), want (0, 0, 0.05)", bx, by, bz)
+	}
+	p := &particle.Particle{Vx: 4, Vy: 4}
+	if fx, fy := s.Fields.Forces[0].ForceOn(p, 0); fx != -2 || fy != 0 {
+		t.Errorf("force (%v, %v), want (-2, 0) with Fy left out", fx, fy)
+	}
+
+	if len(s.Particles) != 8 {
+		t.Fatalf("%d particles spawned, want 8", len(s.Particles))
+	}
+	for i, p := range s.Particles {
+		if r := math.Hypot(p.X-400, p.Y-300); math.Abs(r-150) > 1e-9 {
+			t.Errorf("particle %d at distance %v from the centre, want 150", i, r)
+		}
+		if p.Charge < -0.5 || p.Charge >= 0.5 || p.Mass != 1 || p.Radius != 5 || p.Movable || p.Color.R != 1 || p.ID == 0 {
+			t.Errorf("particle %d spawned as %+v", i, *p)
+		}
+	}
+
+	// The seed makes the random numbers repeatable
+	again, _ := Parse([]byte(ringScene))
+	if again.Particles[3].Vy != s.Particles[3].Vy || again.Particles[3].Charge != s.Particles[3].Charge {
+		t.Error("the same scene spawned different particles")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		scene, want string
+	}{
+		{`{"electric": [{"ex": "x +"}]}`, "electric[0]: electric field Ex: expr: column"},
+		{`{"forces": [{"fx": "z"}]}`, "forces[0]: force Fx: expr:"},
+		{`{"spawners": [{"count": 1, "x": "1"}]}`, "spawners[0]: spawner y: no expression given"},
+		{`{"spawners": [{"count": 1, "x": "1", "y": "t"}]}`, "spawners[0]: spawner y: expr:"},
+		{`{"electrc": []}`, "unknown field"},
+		{`{"spawners": [{"count": 5, "x": "1", "y": "1", "radius": "3 - i"}]}`, `spawners[0]: particle 3: spawner radius "3 - i" is 0, want a positive number`},
+	} {
+		_, err := Parse([]byte(tc.scene))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%s) error = %v, want %q", tc.scene, err, tc.want)
+		}
+	}
+}
+
+func TestSpawnerDefaults(t *testing.T) {
+	s, err := NewSpawner(SpawnerSpec{Count: 3, X: "10 * i", Y: "n"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	particles, err := s.Spawn(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range particles {
+		if p.X != float64(10*i) || p.Y != 3 || p.Vx != 0 || p.Vy != 0 || p.Mass != 1 || p.Radius != 5 || p.Charge != 0 || !p.Movable {
+			t.Errorf("particle %d spawned as %+v", i, *p)
+		}
+	}
+}
+
+func TestLoadBadSpawner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	scene := `{"spawners": [{"count": 1, "x": "1", "y": "1"}, {"count": 2, "x": "1", "y": "1", "mass": "0"}]}`
+	if err := os.WriteFile(path, []byte(scene), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	want := path + `: spawners[1]: spawner mass "0" is 0, want a positive number`
+	if err == nil || err.Error() != want {
+		t.Errorf("Load error = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,124 @@
+package scene
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"particle-physics-simulator/internal/expr"
+	"particle-physics-simulator/internal/particle"
+)
+
+// SpawnVariables are the variables available in spawner expressions, in evaluation order: the
+// particle's index i among the n spawned, two uniform random numbers u and v in [0, 1) and a
+// normally distributed random number g, all drawn afresh for every particle.
+var SpawnVariables = []string{"i", "n", "u", "v", "g"}
+
+// SpawnerSpec describes a group of particles whose position, velocity and properties are
+// expressions, as written in a scene file. Empty expressions take the defaults given below.
+type SpawnerSpec struct {
+	Count   int            `json:"count"`
+	X       string         `json:"x"`
+	Y       string         `json:"y"`
+	Vx      string         `json:"vx"`     // Defaults to 0
+	Vy      string         `json:"vy"`     // Defaults to 0
+	Mass    string         `json:"mass"`   // Defaults to 1
+	Radius  string         `json:"radius"` // Defaults to 5
+	Charge  string         `json:"charge"` // Defaults to 0
+	Color   particle.Color `json:"color"`  // Defaults to white
+	Movable *bool          `json:"movable"`
+}
+
+// Spawner makes the particles of a SpawnerSpec.
+type Spawner struct {
+	count                              int
+	x, y, vx, vy, mass, radius, charge *expr.Expr
+	color                              particle.Color
+	movable                            bool
+}
+
+// NewSpawner compiles the expressions of spec, which may use params.
+func NewSpawner(spec SpawnerSpec, params map[string]float64) (*Spawner, error) {
+	if spec.Count < 0 {
+		return nil, fmt.Errorf("spawner count %d is negative", spec.Count)
+	}
+	s := &Spawner{count: spec.Count, color: spec.Color, movable: true}
+	if s.color == (particle.Color{}) {
+		s.color = particle.Color{R: 1, G: 1, B: 1, A: 1}
+	}
+	if spec.Movable != nil {
+		s.movable = *spec.Movable
+	}
+
+	for _, field := range []struct {
+		name, src, fallback string
+		e                   **expr.Expr
+	}{
+		{"x", spec.X, "", &s.x},
+		{"y", spec.Y, "", &s.y},
+		{"vx", spec.Vx, "0", &s.vx},
+		{"vy", spec.Vy, "0", &s.vy},
+		{"mass", spec.Mass, "1", &s.mass},
+		{"radius", spec.Radius, "5", &s.radius},
+		{"charge", spec.Charge, "0", &s.charge},
+	} {
+		src := field.src
+		if src == "" {
+			src = field.fallback
+		}
+		if src == "" {
+			return nil, fmt.Errorf("spawner %s: no expression given", field.name)
+		}
+		e, err := expr.Compile(src, SpawnVariables, params)
+		if err != nil {
+			return nil, fmt.Errorf("spawner %s: %w", field.name, err)
+		}
+		*field.e = e
+	}
+
+	// Expressions that depend on the particle are checked as each one is spawned
+	for _, field := range []struct {
+		name string
+		e    *expr.Expr
+	}{{"mass", s.mass}, {"radius", s.radius}} {
+		if field.e.IsConstant() {
+			if err := checkPositive(field.name, field.e, field.e.Eval()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// checkPositive reports an error if value, evaluated from the expression e for the named
+// property, is not a positive number: particles divide by their mass, and collide by radius.
+func checkPositive(name string, e *expr.Expr, value float64) error {
+	if !(value > 0) || math.IsInf(value, 0) {
+		return fmt.Errorf("spawner %s %q is %v, want a positive number", name, e, value)
+	}
+	return nil
+}
+
+// Spawn makes the particles, drawing the random variables from rng. It fails if a particle's
+// mass or radius doesn't come out positive.
+func (s *Spawner) Spawn(rng *rand.Rand) ([]*particle.Particle, error) {
+	particles := make([]*particle.Particle, s.count)
+	n := float64(s.count)
+	for i := range particles {
+		vars := []float64{float64(i), n, rng.Float64(), rng.Float64(), rng.NormFloat64()}
+		mass, radius := s.mass.Eval(vars...), s.radius.Eval(vars...)
+		if err := checkPositive("mass", s.mass, mass); err != nil {
+			return nil, fmt.Errorf("particle %d: %w", i, err)
+		}
+		if err := checkPositive("radius", s.radius, radius); err != nil {
+			return nil, fmt.Errorf("particle %d: %w", i, err)
+		}
+		particles[i] = particle.NewCoulombParticle(
+			s.x.Eval(vars...), s.y.Eval(vars...),
+			s.vx.Eval(vars...), s.vy.Eval(vars...),
+			0, 0,
+			mass, radius,
+			s.color, s.charge.Eval(vars...), s.movable,
+		)
+	}
+	return particles, nil
+}
@@ -2,47 +2,242 @@ package simulation
 
 import (
 	"math"
+	"particle-physics-simulator/internal/analytics"
+	"particle-physics-simulator/internal/force"
 	"particle-physics-simulator/internal/particle"
-	"github.com/gen2brain/raylib-go/raylib"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/rewind"
 )
 
-// HandleUserInput handles user interactions for the simulation.
-func HandleUserInput(particles *[]*particle.Particle, paused *bool) {
+const (
+    zoomStep = 1.1 // Zoom factor per notch of the mouse wheel
+    panStep  = 10  // Screen pixels panned per frame while an arrow key is held
+)
+
+// Controls is the state the user changes between frames.
+type Controls struct {
+    Paused    bool
+    Overlays  renderer.Overlays
+    Selection *Selection
+    Trails    *renderer.Trails
+    Camera    *renderer.Camera
+    Editor    *renderer.Editor
+    History   *History
+    Resize    Resize // What happens to the world when the window changes size
+
+    // Force follows the mouse and acts while the left button is held, if ForceTool is on.
+    // It is added to the world's custom forces for the run.
+    Force     *force.PointForce
+    ForceTool bool
+
+    // Rewind holds the latest steps so the world can be taken back to one of them, or is nil
+    // to keep none.
+    Rewind *rewind.Buffer
+
+    width, height int                 // Window size handled by the last frame
+    launch        *launch             // Particle being placed by dragging, or nil
+    box           *box                // Selection box being dragged out, or nil
+    clipboard     []particle.Particle // Copies of the particles copied with Ctrl+C
+    rewinding     bool                // Showing a held step rather than running on
+    rewindIndex   int                 // Held step shown while rewinding
+}
+
+// NewControls returns the controls at start-up: running, with nothing selected, no overlays
+// or trails shown, the editor closed, the world following the window size and the latest
+// steps held for rewinding within rewind.DefaultBudget.
+func NewControls() *Controls {
+    return &Controls{
+        Selection: NewSelection(),
+        Trails:    renderer.NewTrails(renderer.DefaultTrailLength),
+        Camera:    renderer.NewCamera(),
+        Editor:    renderer.NewEditor(),
+        History:   NewHistory(),
+        Force:     force.NewPointForce(),
+        Rewind:    rewind.New(rewind.DefaultBudget),
+    }
+}
+
+// HandleUserInput handles user interactions for the simulation. The mouse position is in
+// world coordinates, as returned by a renderer.View.
+func HandleUserInput(input renderer.Input, particles *[]*particle.Particle, controls *Controls) {
     // Toggle pause with the space bar
-    if rl.IsKeyPressed(rl.KeySpace) {
-        *paused = !*paused
-    }
-
-    // Add particle at mouse position with left-click
-    if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
-        mouseX := float64(rl.GetMouseX())
-        mouseY := float64(rl.GetMouseY())
-        newParticle := particle.NewParticle(
-            mouseX, mouseY, 
-            0, 0,   // Starting velocity
-            0, 0,   // Starting acceleration
-            10.0,     // Mass
-            10,       // Radius
-            particle.Color{R: 0.5, G: 0.7, B: 1, A: 1}, // Color
-            true,
-        )
-        *particles = append(*particles, newParticle)
+    if input.KeyPressed(renderer.KeySpace) {
+        controls.Paused = !controls.Paused
+    }
+
+    handleOverlayKeys(input, &controls.Overlays)
+    handleTrailKeys(input, controls.Trails, controls.Selection.IDs())
+
+    // Open or close the particle editor with E
+    if input.KeyPressed(renderer.KeyE) {
+        controls.Editor.Open = !controls.Editor.Open
+    }
+
+    // With the force tool on, the left button applies the force instead of selecting and
+    // placing particles
+    handleForceTool(input, controls)
+
+    // Pan with the arrow keys
+    for _, pan := range []struct {
+        key    renderer.Key
+        dx, dy float64
+    }{
+        {renderer.KeyLeft, -panStep, 0},
+        {renderer.KeyRight, panStep, 0},
+        {renderer.KeyUp, 0, -panStep},
+        {renderer.KeyDown, 0, panStep},
+    } {
+        if input.KeyDown(pan.key) {
+            controls.Camera.Pan(pan.dx, pan.dy)
+            controls.Camera.Follow = renderer.FollowNone
+        }
+    }
+
+    // Clear the selection with escape
+    if input.KeyPressed(renderer.KeyEscape) {
+        controls.Selection.Clear()
     }
 
+    // Select the particle under the mouse with left-click, or add it to or take it out of
+    // the selection with shift
+    clicked := input.ButtonPressed(renderer.MouseLeft) && !controls.ForceTool
+    if p := particleAt(*particles, input.MouseX, input.MouseY); clicked && p != nil {
+        particle.AssignIDs([]*particle.Particle{p})
+        if input.Shift() {
+            controls.Selection.Toggle(p.ID)
+        } else {
+            controls.Selection.Set(p.ID)
+        }
+        clicked = false
+    }
+
+    // Drag out a box with shift to add the particles in it to the selection
+    if clicked && input.Shift() {
+        controls.box = &box{x0: input.MouseX, y0: input.MouseY, x1: input.MouseX, y1: input.MouseY}
+        clicked = false
+    }
+    handleBox(input, *particles, controls)
+
+    // Otherwise add a particle with the editor's properties at mouse position with left-click,
+    // launched the other way if the mouse is dragged before letting go
+    if clicked {
+        mouseX := input.MouseX
+        mouseY := input.MouseY
+        controls.launch = &launch{x: mouseX, y: mouseY, toX: mouseX, toY: mouseY}
+    }
+    handleLaunch(input, particles, controls)
+
     // Remove particle near mouse position with right-click
-    if rl.IsMouseButtonPressed(rl.MouseRightButton) {
-        mouseX := float64(rl.GetMouseX())
-        mouseY := float64(rl.GetMouseY())
-        *particles = removeParticleNear(*particles, mouseX, mouseY, 15.0) // Radius for selection
+    if input.ButtonPressed(renderer.MouseRight) {
+        mouseX := input.MouseX
+        mouseY := input.MouseY
+        *particles = removeParticleNear(*particles, mouseX, mouseY, 15.0, controls.History) // Radius for selection
+    }
+
+    handleSelectionKeys(input, particles, controls)
+}
+
+// handleCameraInput zooms around the cursor with the mouse wheel, pans by dragging with the
+// middle button, resets the view with 0 and cycles what the camera follows with C. The
+// mouse position is in screen coordinates.
+func handleCameraInput(input renderer.Input, camera *renderer.Camera) {
+    if input.Wheel != 0 {
+        camera.ZoomAt(input.MouseX, input.MouseY, math.Pow(zoomStep, input.Wheel))
+    }
+    if input.ButtonDown(renderer.MouseMiddle) && (input.MouseDX != 0 || input.MouseDY != 0) {
+        camera.Pan(-input.MouseDX, -input.MouseDY)
+        camera.Follow = renderer.FollowNone
+    }
+    if input.KeyPressed(renderer.Key0) {
+        camera.Reset()
+    }
+    if input.KeyPressed(renderer.KeyC) && !input.Control() {
+        camera.Follow = (camera.Follow + 1) % (renderer.FollowCenterOfMass + 1)
+    }
+}
+
+// updateFollow centres the camera on the selected particle or the centre of mass of the
+// movable particles, if it follows one.
+func updateFollow(camera *renderer.Camera, particles []*particle.Particle, selected int, screenWidth, screenHeight int) {
+    switch camera.Follow {
+    case renderer.FollowSelected:
+        if p := findParticle(particles, selected); p != nil {
+            camera.CenterOn(p.X, p.Y, float64(screenWidth), float64(screenHeight))
+        }
+    case renderer.FollowCenterOfMass:
+        if len(particles) > 0 {
+            x, y := analytics.CenterOfMass(particles)
+            camera.CenterOn(x, y, float64(screenWidth), float64(screenHeight))
+        }
+    }
+}
+
+// handleOverlayKeys toggles the field overlays.
+func handleOverlayKeys(input renderer.Input, overlays *renderer.Overlays) {
+    // Toggle the electric field overlay with F
+    if input.KeyPressed(renderer.KeyF) {
+        overlays.ElectricField = !overlays.ElectricField
+    }
+
+    // Toggle the field line overlay with L
+    if input.KeyPressed(renderer.KeyL) {
+        overlays.FieldLines = !overlays.FieldLines
+    }
+
+    // Toggle the potential heatmap with P
+    if input.KeyPressed(renderer.KeyP) {
+        overlays.Potential = !overlays.Potential
+    }
+}
+
+// handleTrailKeys toggles every trail with T, or only the selected particles' with shift.
+func handleTrailKeys(input renderer.Input, trails *renderer.Trails, selected []int) {
+    if !input.KeyPressed(renderer.KeyT) {
+        return
+    }
+    if input.Shift() {
+        for _, id := range selected {
+            trails.Toggle(id)
+        }
+        return
+    }
+    trails.All = !trails.All
+}
+
+// particleAt returns the particle drawn at (x, y), the nearest one if they overlap.
+func particleAt(particles []*particle.Particle, x, y float64) *particle.Particle {
+    var nearest *particle.Particle
+    minDistance := math.Inf(1)
+    for _, p := range particles {
+        distance := math.Hypot(p.X-x, p.Y-y)
+        if distance <= p.Radius && distance < minDistance {
+            nearest, minDistance = p, distance
+        }
+    }
+    return nearest
+}
+
+// findParticle returns the particle with the given ID, or nil.
+func findParticle(particles []*particle.Particle, id int) *particle.Particle {
+    if id == 0 {
+        return nil
+    }
+    for _, p := range particles {
+        if p.ID == id {
+            return p
+        }
     }
+    return nil
 }
 
-// removeParticleNear removes a particle within a certain distance from (x, y).
-func removeParticleNear(particles []*particle.Particle, x, y, radius float64) []*particle.Particle {
+// removeParticleNear removes a particle within a certain distance from (x, y), recording the
+// removal in history.
+func removeParticleNear(particles []*particle.Particle, x, y, radius float64, history *History) []*particle.Particle {
     for i, p := range particles {
         dx, dy := p.X - x, p.Y - y
         distance := math.Sqrt(dx*dx + dy*dy)
         if distance <= radius {
+            history.Removed([]*particle.Particle{p}, []int{i})
             return append(particles[:i], particles[i+1:]...)
         }
     }
@@ -0,0 +1,106 @@
+package simulation
+
+import (
+	"particle-physics-simulator/internal/force"
+	"particle-physics-simulator/internal/renderer"
+	"slices"
+)
+
+// Factors the force tool's radius and strength change by per key press.
+const (
+	toolRadiusStep   = 1.25
+	toolStrengthStep = 1.5
+)
+
+// toolSettings are the force tool's settings that the user changes with the keyboard.
+type toolSettings struct {
+	on        bool
+	mode      force.PointForceMode
+	radius    float64
+	strength  float64
+	stiffness float64
+}
+
+// forceToolSettings returns the current settings of the force tool.
+func forceToolSettings(controls *Controls) toolSettings {
+	f := controls.Force
+	return toolSettings{on: controls.ForceTool, mode: f.Mode, radius: f.Radius, strength: f.Strength, stiffness: f.Stiffness}
+}
+
+// apply sets the force tool to the settings, leaving where it is.
+func (s toolSettings) apply(controls *Controls) {
+	f := controls.Force
+	controls.ForceTool = s.on
+	f.Mode, f.Radius, f.Strength, f.Stiffness = s.mode, s.radius, s.strength, s.stiffness
+	if !s.on {
+		f.Active = false
+	}
+}
+
+// handleForceTool cycles the force tool through attract, repel, spring and off with G, sizes
+// it with [ and ], changes its strength with - and =, and moves it to the mouse, acting
+// while the left button is held. Changes to the settings are recorded in the history, with
+// a held key undone in one go.
+func handleForceTool(input renderer.Input, controls *Controls) {
+	before := forceToolSettings(controls)
+	adjustForceTool(input, controls)
+	if after := forceToolSettings(controls); after != before {
+		repeated := !slices.ContainsFunc(input.Pressed, func(k renderer.Key) bool {
+			return slices.Contains(forceToolKeys, k)
+		})
+		controls.History.toolChanged(controls, before, after, repeated)
+	}
+}
+
+// forceToolKeys are the keys that change the force tool's settings.
+var forceToolKeys = []renderer.Key{renderer.KeyG, renderer.KeyLeftBracket, renderer.KeyRightBracket, renderer.KeyMinus, renderer.KeyEqual}
+
+// adjustForceTool changes the force tool's settings from the keyboard and moves it to the
+// mouse.
+func adjustForceTool(input renderer.Input, controls *Controls) {
+	f := controls.Force
+	if input.KeyPressed(renderer.KeyG) {
+		switch {
+		case !controls.ForceTool:
+			controls.ForceTool = true
+			f.Mode = force.Attract
+		case f.Mode < force.Spring:
+			f.Mode++
+		default:
+			controls.ForceTool = false
+		}
+	}
+	if !controls.ForceTool {
+		f.Active = false
+		return
+	}
+
+	if input.KeyPressedOrRepeated(renderer.KeyRightBracket) {
+		f.Radius *= toolRadiusStep
+	}
+	if input.KeyPressedOrRepeated(renderer.KeyLeftBracket) {
+		f.Radius /= toolRadiusStep
+	}
+	if input.KeyPressedOrRepeated(renderer.KeyEqual) {
+		f.Strength *= toolStrengthStep
+		f.Stiffness *= toolStrengthStep
+	}
+	if input.KeyPressedOrRepeated(renderer.KeyMinus) {
+		f.Strength /= toolStrengthStep
+		f.Stiffness /= toolStrengthStep
+	}
+
+	f.X, f.Y = input.MouseX, input.MouseY
+	f.Active = input.ButtonDown(renderer.MouseLeft)
+}
+
+// addForceTool adds the force tool to the custom forces of fields, returning a function that
+// takes it out again.
+func addForceTool(fields *force.ExternalFields, tool *force.PointForce) (remove func()) {
+	fields.Forces = append(fields.Forces, tool)
+	return func() {
+		fields.Forces = slices.DeleteFunc(fields.Forces, func(f force.CustomForce) bool {
+			return f == force.CustomForce(tool)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package simulation
+
+import (
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/world"
+	"slices"
+)
+
+// maxHistory is the number of edits that can be undone.
+const maxHistory = 200
+
+// command is an edit that can be undone and redone.
+type command interface {
+	undo(particles *[]*particle.Particle)
+	redo(particles *[]*particle.Particle)
+}
+
+// History records the edits made to the scene so they can be undone and redone. Particles
+// come back as they were when edited, with the same IDs, so selections and trails follow.
+type History struct {
+	done   []command
+	undone []command
+	sealed bool // The last change can't be merged with the next
+	edits  int  // Number of changes made through the history, including undos and redos
+}
+
+// NewHistory returns an empty history.
+func NewHistory() *History {
+	return &History{}
+}
+
+// CanUndo reports whether there is an edit to undo.
+func (h *History) CanUndo() bool {
+	return len(h.done) > 0
+}
+
+// CanRedo reports whether there is an undone edit to redo.
+func (h *History) CanRedo() bool {
+	return len(h.undone) > 0
+}
+
+// Edits returns the number of changes made to the scene through the history so far, counting
+// undos, redos and continuous changes, so views can tell that the scene has been edited.
+func (h *History) Edits() int {
+	return h.edits
+}
+
+// Undo reverts the last edit, reporting whether there was one.
+func (h *History) Undo(particles *[]*particle.Particle) bool {
+	if !h.CanUndo() {
+		return false
+	}
+	c := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	c.undo(particles)
+	h.undone = append(h.undone, c)
+	h.sealed = true
+	h.edits++
+	return true
+}
+
+// Redo makes the last undone edit again, reporting whether there was one.
+func (h *History) Redo(particles *[]*particle.Particle) bool {
+	if !h.CanRedo() {
+		return false
+	}
+	c := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	c.redo(particles)
+	h.done = append(h.done, c)
+	h.sealed = true
+	h.edits++
+	return true
+}
+
+// Clear forgets every edit, for when the scene has been replaced in a way the edits can't be
+// replayed onto.
+func (h *History) Clear() {
+	h.done, h.undone = nil, nil
+	h.sealed = true
+	h.edits++
+}
+
+// record adds an edit that has been made, forgetting the edits undone before it.
+func (h *History) record(c command) {
+	h.done = append(h.done, c)
+	if len(h.done) > maxHistory {
+		h.done = slices.Delete(h.done, 0, len(h.done)-maxHistory)
+	}
+	h.undone = nil
+	h.sealed = false
+	h.edits++
+}
+
+// Added records particles added to the end of the scene.
+func (h *History) Added(added ...*particle.Particle) {
+	if len(added) > 0 {
+		particle.AssignIDs(added)
+		h.record(&addCommand{added: snapshot(added)})
+	}
+}
+
+// Removed records particles taken out of the scene from the given indices, in increasing
+// order.
+func (h *History) Removed(removed []*particle.Particle, indices []int) {
+	if len(removed) > 0 {
+		particle.AssignIDs(removed)
+		h.record(&removeCommand{removed: snapshot(removed), indices: slices.Clone(indices)})
+	}
+}
+
+// Changed records particles changed from the state before to the state after.
+func (h *History) Changed(before, after []particle.Particle) {
+	h.record(&changeCommand{before: before, after: after})
+}
+
+// changedContinuously records a change that carries on from the last one if that changed the
+// same particles and the history hasn't been sealed since, like dragging a slider. Undoing
+// it undoes the whole drag.
+func (h *History) changedContinuously(before, after []particle.Particle) {
+	if last, ok := h.last().(*changeCommand); ok && last.continuous && !h.sealed && sameParticles(last.after, after) {
+		last.after = after
+		h.edits++
+		return
+	}
+	h.record(&changeCommand{before: before, after: after, continuous: true})
+}
+
+// toolChanged records a change to the force tool's settings, which are part of the world's
+// custom forces. A change from a held key carries on from the last one, so the whole hold is
+// undone at once.
+func (h *History) toolChanged(controls *Controls, before, after toolSettings, repeated bool) {
+	if last, ok := h.last().(*toolCommand); ok && repeated && !h.sealed {
+		last.after = after
+		h.edits++
+		return
+	}
+	h.record(&toolCommand{controls: controls, before: before, after: after})
+}
+
+// seal ends a continuous change, so the next one is undone separately.
+func (h *History) seal() {
+	h.sealed = true
+}
+
+func (h *History) last() command {
+	if len(h.done) == 0 {
+		return nil
+	}
+	return h.done[len(h.done)-1]
+}
+
+// handleHistoryKeys undoes the last edit with Ctrl+Z and redoes it with Ctrl+Y or
+// Ctrl+Shift+Z.
+func handleHistoryKeys(input renderer.Input, w *world.World, controls *Controls) {
+	if !input.Control() {
+		return
+	}
+	switch {
+	case input.KeyPressedOrRepeated(renderer.KeyZ) && input.Shift(), input.KeyPressedOrRepeated(renderer.KeyY):
+		controls.History.Redo(&w.Particles)
+	case input.KeyPressedOrRepeated(renderer.KeyZ):
+		controls.History.Undo(&w.Particles)
+	}
+}
+
+type addCommand struct {
+	added []particle.Particle
+}
+
+func (c *addCommand) undo(particles *[]*particle.Particle) {
+	*particles = deleteParticles(*particles, c.added)
+}
+
+func (c *addCommand) redo(particles *[]*particle.Particle) {
+	for i := range c.added {
+		p := c.added[i]
+		*particles = append(*particles, &p)
+	}
+}
+
+type removeCommand struct {
+	removed []particle.Particle
+	indices []int
+}
+
+func (c *removeCommand) undo(particles *[]*particle.Particle) {
+	for i := range c.removed {
+		p := c.removed[i]
+		*particles = slices.Insert(*particles, min(c.indices[i], len(*particles)), &p)
+	}
+}
+
+func (c *removeCommand) redo(particles *[]*particle.Particle) {
+	*particles = deleteParticles(*particles, c.removed)
+}
+
+type changeCommand struct {
+	before, after []particle.Particle
+	continuous    bool
+}
+
+func (c *changeCommand) undo(particles *[]*particle.Particle) {
+	restore(*particles, c.before)
+}
+
+func (c *changeCommand) redo(particles *[]*particle.Particle) {
+	restore(*particles, c.after)
+}
+
+type toolCommand struct {
+	controls      *Controls
+	before, after toolSettings
+}
+
+func (c *toolCommand) undo(particles *[]*particle.Particle) {
+	c.before.apply(c.controls)
+}
+
+func (c *toolCommand) redo(particles *[]*particle.Particle) {
+	c.after.apply(c.controls)
+}
+
+// snapshot returns copies of the particles' current state.
+func snapshot(particles []*particle.Particle) []particle.Particle {
+	states := make([]particle.Particle, len(particles))
+	for i, p := range particles {
+		states[i] = *p
+	}
+	return states
+}
+
+// restore sets the particles with the IDs of states back to those states.
+func restore(particles []*particle.Particle, states []particle.Particle) {
+	for _, p := range particles {
+		for _, state := range states {
+			if p.ID == state.ID {
+				*p = state
+			}
+		}
+	}
+}
+
+// deleteParticles removes the particles with the IDs of states.
+func deleteParticles(particles []*particle.Particle, states []particle.Particle) []*particle.Particle {
+	return slices.DeleteFunc(particles, func(p *particle.Particle) bool {
+		return slices.ContainsFunc(states, func(state particle.Particle) bool { return state.ID == p.ID })
+	})
+}
+
+// sameParticles reports whether two sets of states are of the same particles.
+func sameParticles(a, b []particle.Particle) bool {
+	return slices.EqualFunc(a, b, func(x, y particle.Particle) bool { return x.ID == y.ID })
+}
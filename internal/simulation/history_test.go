@@ -0,0 +1,145 @@
+package simulation
+
+import (
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/world"
+	"slices"
+	"testing"
+)
+
+var (
+	undo = withKeys(renderer.Press(renderer.KeyZ), renderer.KeyLeftControl)
+	redo = withKeys(renderer.Press(renderer.KeyY), renderer.KeyLeftControl)
+)
+
+// ids returns the IDs of the particles in order.
+func ids(particles []*particle.Particle) []int {
+	var ids []int
+	for _, p := range particles {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestUndoRemove(t *testing.T) {
+	w := world.New(threeParticles(), 800, 600)
+	original := ids(w.Particles)
+	w.Particles[1].Vx = 30
+	controls := NewControls()
+
+	// Right-click removes the middle particle; it moves on before the undo
+	HandleUserInput(renderer.Click(200, 100, renderer.MouseRight), &w.Particles, controls)
+	if len(w.Particles) != 2 {
+		t.Fatalf("%d particles after right-click, want 2", len(w.Particles))
+	}
+	handleHistoryKeys(undo, w, controls)
+	if got := ids(w.Particles); !slices.Equal(got, original) {
+		t.Fatalf("after undo the particles are %v, want %v", got, original)
+	}
+	if p := w.Particles[1]; p.X != 200 || p.Vx != 30 {
+		t.Errorf("restored at x %v moving at %v, want as removed", p.X, p.Vx)
+	}
+
+	handleHistoryKeys(redo, w, controls)
+	if got := ids(w.Particles); !slices.Equal(got, []int{original[0], original[2]}) {
+		t.Errorf("after redo the particles are %v", got)
+	}
+}
+
+func TestUndoAddAndChange(t *testing.T) {
+	w := world.New(threeParticles(), 800, 600)
+	controls := NewControls()
+	frames := []renderer.Input{
+		renderer.Click(400, 300, renderer.MouseLeft), renderer.Release(400, 300, renderer.MouseLeft),
+		renderer.Click(100, 100, renderer.MouseLeft), renderer.Press(renderer.KeyM),
+	}
+	for _, in := range frames {
+		HandleUserInput(in, &w.Particles, controls)
+	}
+	if len(w.Particles) != 4 || w.Particles[0].Movable {
+		t.Fatalf("%d particles, first movable %v", len(w.Particles), w.Particles[0].Movable)
+	}
+
+	handleHistoryKeys(undo, w, controls)
+	if !w.Particles[0].Movable {
+		t.Error("undo didn't unpin the particle")
+	}
+	handleHistoryKeys(undo, w, controls)
+	if len(w.Particles) != 3 {
+		t.Errorf("undo left %d particles, want the added one gone", len(w.Particles))
+	}
+	if controls.History.CanUndo() {
+		t.Error("more to undo than was done")
+	}
+
+	handleHistoryKeys(redo, w, controls)
+	handleHistoryKeys(redo, w, controls)
+	if len(w.Particles) != 4 || w.Particles[0].Movable {
+		t.Error("redo didn't add and pin again")
+	}
+
+	// A new edit forgets what was undone
+	handleHistoryKeys(undo, w, controls)
+	HandleUserInput(renderer.Press(renderer.KeyM), &w.Particles, controls)
+	if controls.History.CanRedo() {
+		t.Error("can still redo after a new edit")
+	}
+}
+
+func TestUndoContinuousChange(t *testing.T) {
+	p := particle.SpeciesList()[0].New(0, 0)
+	h := NewHistory()
+	before := snapshot([]*particle.Particle{p})
+	for _, mass := range []float64{11, 12, 13} {
+		step := snapshot([]*particle.Particle{p})
+		p.Mass = mass
+		h.changedContinuously(step, snapshot([]*particle.Particle{p}))
+	}
+	h.seal()
+	p.Mass = 20
+	h.changedContinuously(snapshot([]*particle.Particle{p}), snapshot([]*particle.Particle{p}))
+
+	particles := []*particle.Particle{p}
+	h.Undo(&particles)
+	h.Undo(&particles)
+	if p.Mass != before[0].Mass || h.CanUndo() {
+		t.Errorf("mass %v after undoing the drag, want %v in one step", p.Mass, before[0].Mass)
+	}
+	// Every change counts as an edit, even when merged into the last one
+	if h.Edits() != 6 {
+		t.Errorf("%d edits, want 6", h.Edits())
+	}
+}
+
+func TestUndoForceTool(t *testing.T) {
+	w := world.New(nil, 800, 600)
+	controls := NewControls()
+	radius := controls.Force.Radius
+
+	// G turns the tool on, then ] is pressed and held
+	handleForceTool(renderer.Press(renderer.KeyG), controls)
+	handleForceTool(renderer.Press(renderer.KeyRightBracket), controls)
+	held := renderer.Input{Repeated: []renderer.Key{renderer.KeyRightBracket}, Down: []renderer.Key{renderer.KeyRightBracket}}
+	handleForceTool(held, controls)
+	handleForceTool(held, controls)
+	grown := controls.Force.Radius
+	if grown <= radius {
+		t.Fatalf("radius %v after holding ], want more than %v", grown, radius)
+	}
+
+	handleHistoryKeys(undo, w, controls)
+	if controls.Force.Radius != radius || !controls.ForceTool {
+		t.Errorf("radius %v with the tool on %v after undo, want %v and on", controls.Force.Radius, controls.ForceTool, radius)
+	}
+	handleHistoryKeys(undo, w, controls)
+	if controls.ForceTool || controls.History.CanUndo() {
+		t.Errorf("tool on %v after undoing twice, want off with nothing left to undo", controls.ForceTool)
+	}
+
+	handleHistoryKeys(redo, w, controls)
+	handleHistoryKeys(redo, w, controls)
+	if controls.Force.Radius != grown || !controls.ForceTool {
+		t.Errorf("radius %v with the tool on %v after redo, want %v and on", controls.Force.Radius, controls.ForceTool, grown)
+	}
+}
@@ -0,0 +1,67 @@
+package simulation
+
+import (
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/world"
+)
+
+const (
+	launchSpeed   = 3   // Launch speed in pixels per second for each pixel dragged back
+	minLaunchDrag = 3   // Shorter drags place the particle at rest
+	previewSteps  = 300 // Steps of the predicted trajectory, two and a half seconds
+)
+
+// launch is a particle being placed by dragging back from where it goes, like a slingshot.
+type launch struct {
+	x, y     float64 // Where the particle is placed
+	toX, toY float64 // Where the mouse has been dragged to
+}
+
+// particle returns the particle the launch would place now, with the given properties.
+func (l *launch) particle(props particle.Properties) *particle.Particle {
+	p := props.New(l.x, l.y)
+	if math.Hypot(l.x-l.toX, l.y-l.toY) >= minLaunchDrag {
+		p.Vx = (l.x - l.toX) * launchSpeed
+		p.Vy = (l.y - l.toY) * launchSpeed
+	}
+	return p
+}
+
+// handleLaunch follows the mouse while the left button is held and places the particle when
+// it is let go.
+func handleLaunch(input renderer.Input, particles *[]*particle.Particle, controls *Controls) {
+	l := controls.launch
+	if l == nil {
+		return
+	}
+	if input.ButtonDown(renderer.MouseLeft) {
+		l.toX, l.toY = input.MouseX, input.MouseY
+		return
+	}
+	// Placed where the mouse was last held, in case it was let go over a panel
+	p := l.particle(controls.Editor.Placing)
+	*particles = append(*particles, p)
+	controls.History.Added(p)
+	controls.launch = nil
+}
+
+// predictTrajectory returns the path p would follow through w over the next steps, alone:
+// it feels the fields, gravity, drag and walls of w but not the other particles.
+func predictTrajectory(w *world.World, p *particle.Particle, steps int) []renderer.Point {
+	ghost := *p
+	preview := world.New([]*particle.Particle{&ghost}, w.Width, w.Height)
+	preview.Fields = w.Fields
+	preview.AirDrag = w.AirDrag
+	preview.GroundFriction = w.GroundFriction
+	preview.Time = w.Time
+
+	path := make([]renderer.Point, 0, steps+1)
+	path = append(path, renderer.Point{X: ghost.X, Y: ghost.Y})
+	for i := 0; i < steps; i++ {
+		preview.Step(TimeStep)
+		path = append(path, renderer.Point{X: ghost.X, Y: ghost.Y})
+	}
+	return path
+}
@@ -0,0 +1,147 @@
+package simulation
+
+import (
+	"particle-physics-simulator/internal/playback"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/trajectory"
+	"time"
+)
+
+// RunPlayback shows a recorded trajectory instead of simulating, with controls to play,
+// pause, change speed, step through frames and scrub along a timeline.
+func RunPlayback(r renderer.Renderer, src trajectory.Source) error {
+	player := playback.NewPlayer(src)
+	overlays := renderer.Overlays{}
+	trails := renderer.NewTrails(renderer.DefaultTrailLength)
+	camera := renderer.NewCamera()
+	view := renderer.NewView(r, camera)
+	fieldLines := renderer.NewFieldLineCache()
+	lastIndex := -1
+	lastFrame := time.Now()
+
+	for !r.ShouldClose() {
+		currentTime := time.Now()
+		width, height := r.Size()
+
+		handleCameraInput(r.Input(), camera)
+		HandlePlaybackInput(r.Input(), player, renderer.TimelineBounds(width, height), &overlays, trails)
+		player.Advance(currentTime.Sub(lastFrame).Seconds())
+		lastFrame = currentTime
+
+		frame, err := player.Current()
+		if err != nil {
+			return err
+		}
+		particles := frame.Particles
+		updateFollow(camera, particles, 0, width, height)
+
+		if index := player.Index(); index != lastIndex {
+			if err := recordTrails(trails, player.Source, lastIndex, index); err != nil {
+				return err
+			}
+			lastIndex = index
+		}
+
+		r.BeginFrame(renderer.Background)
+
+		// External fields aren't recorded, so the overlays show the particles' own fields
+		boxWidth, boxHeight := frameBox(frame, width, height)
+		renderer.DrawScene(view, renderer.Scene{
+			Particles:  particles,
+			Width:      boxWidth,
+			Height:     boxHeight,
+			Overlays:   overlays,
+			Trails:     trails,
+			FieldLines: fieldLines,
+			Step:       frame.Step,
+			Version:    player.Index(), // Every frame is a new set of charges
+		})
+
+		renderer.DrawPlaybackUI(r, player, frame)
+		renderer.DrawTimeline(r, player.Progress())
+		renderer.DrawCameraInfo(r, camera)
+		renderer.DrawWindowButtons(r)
+		renderer.DrawParticleInfo(view, particles)
+
+		r.EndFrame()
+	}
+	return nil
+}
+
+// frameBox returns the walled area of a recorded frame, or the window's size for files that
+// don't carry one.
+func frameBox(frame trajectory.Frame, width, height int) (float64, float64) {
+	if frame.Width <= 0 || frame.Height <= 0 {
+		return float64(width), float64(height)
+	}
+	return frame.Width, frame.Height
+}
+
+// HandlePlaybackInput handles user interactions while playing back a trajectory, with the
+// timeline drawn at bar.
+func HandlePlaybackInput(input renderer.Input, player *playback.Player, bar renderer.Rectangle, overlays *renderer.Overlays, trails *renderer.Trails) {
+	if input.KeyPressed(renderer.KeySpace) {
+		player.TogglePlaying()
+	}
+
+	// Step through frames with the arrow keys, ten at a time with shift
+	step := 1
+	if input.Shift() {
+		step = 10
+	}
+	if input.KeyPressedOrRepeated(renderer.KeyRight) {
+		player.StepFrames(step)
+	}
+	if input.KeyPressedOrRepeated(renderer.KeyLeft) {
+		player.StepFrames(-step)
+	}
+
+	// Double or halve the playback speed
+	if input.KeyPressed(renderer.KeyUp) {
+		player.SetSpeed(player.Speed * 2)
+	}
+	if input.KeyPressed(renderer.KeyDown) {
+		player.SetSpeed(player.Speed / 2)
+	}
+
+	if input.KeyPressed(renderer.KeyHome) {
+		player.SeekFrame(0)
+	}
+	if input.KeyPressed(renderer.KeyEnd) {
+		player.SeekFrame(player.Source.NumFrames() - 1)
+	}
+
+	// Click or drag on the timeline to scrub
+	if input.ButtonDown(renderer.MouseLeft) && timelineHit(bar).Contains(input.MouseX, input.MouseY) {
+		player.SeekProgress((input.MouseX - bar.X) / bar.Width)
+	}
+
+	handleOverlayKeys(input, overlays)
+	handleTrailKeys(input, trails, nil)
+}
+
+// timelineHit returns the area where the mouse scrubs the timeline drawn at bar, allowing
+// some slack above and below the thin bar.
+func timelineHit(bar renderer.Rectangle) renderer.Rectangle {
+	hit := bar
+	hit.Y -= bar.Height
+	hit.Height *= 3
+	return hit
+}
+
+// recordTrails adds the frames after from up to to to the trails, including frames skipped
+// at high speed. Trails restart after jumping back or too far ahead to fill in.
+func recordTrails(trails *renderer.Trails, src trajectory.Source, from, to int) error {
+	if to < from || to-from > trails.Length {
+		trails.Clear()
+		from = to - 1
+	}
+	for i := from + 1; i <= to; i++ {
+		frame, err := src.Frame(i)
+		if err != nil {
+			return err
+		}
+		trails.Record(frame.Particles)
+	}
+	return nil
+}
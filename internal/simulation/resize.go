@@ -0,0 +1,62 @@
+package simulation
+
+import (
+	"fmt"
+	"particle-physics-simulator/internal/world"
+	"strings"
+)
+
+// Resize chooses what happens to the simulated area when the window changes size.
+type Resize int
+
+const (
+	ResizeWorld Resize = iota // The walls move with the edges of the window
+	ResizeScale               // The world keeps its size and the view zooms to fit it
+)
+
+var resizeNames = []string{"world", "scale"}
+
+func (r Resize) String() string {
+	if r < 0 || int(r) >= len(resizeNames) {
+		return fmt.Sprintf("Resize(%d)", int(r))
+	}
+	return resizeNames[r]
+}
+
+// ParseResize parses "world" or "scale".
+func ParseResize(s string) (Resize, error) {
+	for i, name := range resizeNames {
+		if strings.EqualFold(s, name) {
+			return Resize(i), nil
+		}
+	}
+	return 0, fmt.Errorf("simulation: unknown resize mode %q (want %s)", s, strings.Join(resizeNames, ", "))
+}
+
+// handleResize fits the world to a window of the given size when it has changed since the
+// last frame. On the first frame it only fits the view, so a world of another size than the
+// window is shown whole in scale mode and keeps its size in world mode.
+func handleResize(w *world.World, controls *Controls, width, height int) {
+	oldWidth, oldHeight := controls.width, controls.height
+	if width == oldWidth && height == oldHeight || width <= 0 || height <= 0 {
+		return
+	}
+	controls.width, controls.height = width, height
+	first := oldWidth == 0
+
+	switch controls.Resize {
+	case ResizeWorld:
+		if !first {
+			w.Width, w.Height = width, height
+		}
+	case ResizeScale:
+		camera := controls.Camera
+		atHome := camera.AtHome()
+		camera.Fit(float64(w.Width), float64(w.Height), float64(width), float64(height))
+		if atHome {
+			camera.Reset()
+		} else if !first {
+			camera.Resize(float64(oldWidth), float64(oldHeight), float64(width), float64(height))
+		}
+	}
+}
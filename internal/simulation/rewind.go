@@ -0,0 +1,87 @@
+package simulation
+
+import (
+	"math"
+	"particle-physics-simulator/internal/analytics"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/world"
+)
+
+// handleRewind takes the world back through the steps held in controls.Rewind. R pauses and
+// starts rewinding; comma and period then step back and forward, ten at a time with shift,
+// and clicking or dragging the timeline at bar scrubs. R or Space resumes from the step
+// shown, forgetting the later ones. The mouse position is in screen coordinates.
+func handleRewind(input renderer.Input, w *world.World, controls *Controls, bar renderer.Rectangle) {
+	buffer := controls.Rewind
+	if buffer == nil || buffer.Len() == 0 {
+		controls.rewinding = false
+		return
+	}
+	if !controls.rewinding {
+		if input.KeyPressed(renderer.KeyR) {
+			controls.rewinding = true
+			controls.rewindIndex = buffer.Len() - 1
+			controls.Paused = true
+		}
+		return
+	}
+
+	// Space has already unpaused
+	if input.KeyPressed(renderer.KeyR) || !controls.Paused {
+		if controls.rewindIndex < buffer.Len()-1 {
+			buffer.Truncate(controls.rewindIndex)
+			controls.History.Clear()
+		}
+		controls.rewinding = false
+		controls.Paused = false
+		return
+	}
+
+	index := controls.rewindIndex
+	step := 1
+	if input.Shift() {
+		step = 10
+	}
+	if input.KeyPressedOrRepeated(renderer.KeyComma) {
+		index -= step
+	}
+	if input.KeyPressedOrRepeated(renderer.KeyPeriod) {
+		index += step
+	}
+	if input.ButtonDown(renderer.MouseLeft) && timelineHit(bar).Contains(input.MouseX, input.MouseY) {
+		progress := (input.MouseX - bar.X) / bar.Width
+		index = int(math.Round(progress * float64(buffer.Len()-1)))
+	}
+	index = max(0, min(index, buffer.Len()-1))
+	if index != controls.rewindIndex {
+		rewindTo(w, controls, index)
+	}
+}
+
+// rewindTo restores the world to the held step index. Trails restart, and so does the energy
+// account, since the steps it added up no longer happened. The edit history is forgotten: its
+// particles may have been added or removed since, and undoing would bring back duplicates.
+func rewindTo(w *world.World, controls *Controls, index int) {
+	controls.Rewind.Restore(index, w)
+	controls.rewindIndex = index
+	controls.Trails.Clear()
+	controls.History.Clear()
+	if w.Ledger != nil {
+		w.Ledger = analytics.NewLedger(w.Ledger.MaxEntries)
+	}
+}
+
+// drawRewind shows where the world is among the held steps while rewinding.
+func drawRewind(r renderer.Renderer, controls *Controls) {
+	buffer := controls.Rewind
+	if !controls.rewinding || buffer == nil || buffer.Len() == 0 {
+		return
+	}
+	last := buffer.Len() - 1
+	progress := 1.0
+	if last > 0 {
+		progress = float64(controls.rewindIndex) / float64(last)
+	}
+	latest := buffer.Time(last)
+	renderer.DrawRewind(r, progress, latest-buffer.Time(controls.rewindIndex), latest-buffer.Time(0))
+}
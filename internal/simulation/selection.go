@@ -0,0 +1,265 @@
+package simulation
+
+import (
+	"maps"
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/renderer"
+	"slices"
+)
+
+// impulsePerPixel is the impulse given to each selected particle with I, for every pixel
+// from the selection's centre to the mouse.
+const impulsePerPixel = 10
+
+// Selection is the set of selected particles by ID. The one selected last is the primary,
+// which the camera follows and the editor shows.
+type Selection struct {
+	ids     map[int]bool
+	primary int
+}
+
+// NewSelection returns an empty selection.
+func NewSelection() *Selection {
+	return &Selection{ids: map[int]bool{}}
+}
+
+// Len returns the number of particles selected.
+func (s *Selection) Len() int {
+	return len(s.ids)
+}
+
+// Contains reports whether the particle with the given ID is selected.
+func (s *Selection) Contains(id int) bool {
+	return s.ids[id]
+}
+
+// Primary returns the ID of the primary particle, or 0 if nothing is selected.
+func (s *Selection) Primary() int {
+	return s.primary
+}
+
+// IDs returns the IDs of the selected particles in increasing order.
+func (s *Selection) IDs() []int {
+	return slices.Sorted(maps.Keys(s.ids))
+}
+
+// Set selects only the given particles, the last one as the primary.
+func (s *Selection) Set(ids ...int) {
+	s.Clear()
+	s.Add(ids...)
+}
+
+// Add adds particles to the selection, the last one as the primary.
+func (s *Selection) Add(ids ...int) {
+	for _, id := range ids {
+		s.ids[id] = true
+		s.primary = id
+	}
+}
+
+// Toggle adds a particle to the selection as the primary, or removes it if already selected.
+func (s *Selection) Toggle(id int) {
+	if s.ids[id] {
+		s.remove(id)
+	} else {
+		s.Add(id)
+	}
+}
+
+// remove takes a particle out of the selection. If it was the primary, the newest particle
+// left takes its place.
+func (s *Selection) remove(id int) {
+	delete(s.ids, id)
+	if s.primary == id {
+		s.primary = 0
+		for other := range s.ids {
+			s.primary = max(s.primary, other)
+		}
+	}
+}
+
+// Clear empties the selection.
+func (s *Selection) Clear() {
+	clear(s.ids)
+	s.primary = 0
+}
+
+// Particles returns the selected particles, the primary first and the others in the order
+// they appear in particles. Selected particles that are gone are dropped from the selection.
+func (s *Selection) Particles(particles []*particle.Particle) []*particle.Particle {
+	var selected []*particle.Particle
+	found := map[int]bool{}
+	for _, p := range particles {
+		if !s.ids[p.ID] {
+			continue
+		}
+		found[p.ID] = true
+		if p.ID == s.primary {
+			selected = slices.Insert(selected, 0, p)
+		} else {
+			selected = append(selected, p)
+		}
+	}
+	for _, id := range s.IDs() {
+		if !found[id] {
+			s.remove(id)
+		}
+	}
+	return selected
+}
+
+// box is a selection rectangle being dragged out from (x0, y0), in world coordinates.
+type box struct {
+	x0, y0, x1, y1 float64
+}
+
+// Rectangle returns the area of the box, whichever way it was dragged.
+func (b *box) Rectangle() renderer.Rectangle {
+	return renderer.Rectangle{
+		X:      math.Min(b.x0, b.x1),
+		Y:      math.Min(b.y0, b.y1),
+		Width:  math.Abs(b.x1 - b.x0),
+		Height: math.Abs(b.y1 - b.y0),
+	}
+}
+
+// handleBox follows the mouse while the left button is held and adds the particles inside
+// the box to the selection when it is let go.
+func handleBox(input renderer.Input, particles []*particle.Particle, controls *Controls) {
+	b := controls.box
+	if b == nil {
+		return
+	}
+	if input.ButtonDown(renderer.MouseLeft) {
+		b.x1, b.y1 = input.MouseX, input.MouseY
+		return
+	}
+	area := b.Rectangle()
+	particle.AssignIDs(particles)
+	for _, p := range particles {
+		if area.Contains(p.X, p.Y) {
+			controls.Selection.Add(p.ID)
+		}
+	}
+	controls.box = nil
+}
+
+// handleSelectionKeys applies the group operations to the selected particles: select all
+// with Ctrl+A, delete, pin or unpin with M, stop with Z, kick towards the mouse with I, and
+// copy and paste at the mouse with Ctrl+C and Ctrl+V.
+func handleSelectionKeys(input renderer.Input, particles *[]*particle.Particle, controls *Controls) {
+	selection := controls.Selection
+
+	if input.Control() && input.KeyPressed(renderer.KeyA) {
+		particle.AssignIDs(*particles)
+		for _, p := range *particles {
+			selection.Add(p.ID)
+		}
+	}
+
+	// Paste even with nothing selected
+	if input.Control() && input.KeyPressed(renderer.KeyV) && len(controls.clipboard) > 0 {
+		pasted := paste(controls.clipboard, input.MouseX, input.MouseY)
+		*particles = append(*particles, pasted...)
+		controls.History.Added(pasted...)
+		selection.Clear()
+		for _, p := range pasted {
+			selection.Add(p.ID)
+		}
+	}
+
+	selected := selection.Particles(*particles)
+	if len(selected) == 0 {
+		return
+	}
+
+	// Copy in the order of the scene, which pasting keeps
+	if input.Control() && input.KeyPressed(renderer.KeyC) {
+		controls.clipboard = controls.clipboard[:0]
+		for _, p := range *particles {
+			if selection.Contains(p.ID) {
+				controls.clipboard = append(controls.clipboard, *p)
+			}
+		}
+	}
+
+	if input.KeyPressed(renderer.KeyDelete) || input.KeyPressed(renderer.KeyBackspace) {
+		var removed []*particle.Particle
+		var indices []int
+		for i, p := range *particles {
+			if selection.Contains(p.ID) {
+				removed = append(removed, p)
+				indices = append(indices, i)
+			}
+		}
+		*particles = slices.DeleteFunc(*particles, func(p *particle.Particle) bool {
+			return selection.Contains(p.ID)
+		})
+		controls.History.Removed(removed, indices)
+		selection.Clear()
+		return
+	}
+
+	// The other operations change the selected particles, undone together
+	before := snapshot(selected)
+	defer func() {
+		if after := snapshot(selected); !slices.Equal(before, after) {
+			controls.History.Changed(before, after)
+		}
+	}()
+
+	// Pin them all if any can move, otherwise let them all move
+	if input.KeyPressed(renderer.KeyM) {
+		movable := slices.ContainsFunc(selected, func(p *particle.Particle) bool { return p.Movable })
+		for _, p := range selected {
+			p.Movable = !movable
+		}
+	}
+
+	// Ctrl+Z is undo
+	if input.KeyPressed(renderer.KeyZ) && !input.Control() {
+		for _, p := range selected {
+			p.Vx, p.Vy, p.Vz = 0, 0, 0
+		}
+	}
+
+	// The same impulse for each, so lighter particles are kicked harder
+	if input.KeyPressed(renderer.KeyI) {
+		cx, cy := centre(selected)
+		jx, jy := (input.MouseX-cx)*impulsePerPixel, (input.MouseY-cy)*impulsePerPixel
+		for _, p := range selected {
+			if p.Mass > 0 {
+				p.Vx += jx / p.Mass
+				p.Vy += jy / p.Mass
+			}
+		}
+	}
+}
+
+// paste returns new copies of the particles, moved together so their centre is at (x, y).
+func paste(clipboard []particle.Particle, x, y float64) []*particle.Particle {
+	pasted := make([]*particle.Particle, len(clipboard))
+	for i := range clipboard {
+		p := clipboard[i]
+		p.ID = particle.NextID()
+		pasted[i] = &p
+	}
+	cx, cy := centre(pasted)
+	for _, p := range pasted {
+		p.X += x - cx
+		p.Y += y - cy
+	}
+	return pasted
+}
+
+// centre returns the mean position of the particles.
+func centre(particles []*particle.Particle) (float64, float64) {
+	x, y := 0.0, 0.0
+	for _, p := range particles {
+		x += p.X
+		y += p.Y
+	}
+	n := float64(len(particles))
+	return x / n, y / n
+}
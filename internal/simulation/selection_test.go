@@ -0,0 +1,115 @@
+package simulation
+
+import (
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/renderer"
+	"slices"
+	"testing"
+)
+
+// withKeys returns in with the keys held down as well.
+func withKeys(in renderer.Input, keys ...renderer.Key) renderer.Input {
+	in.Down = append(slices.Clone(in.Down), keys...)
+	return in
+}
+
+func threeParticles() []*particle.Particle {
+	props := particle.SpeciesList()[0].Properties
+	return []*particle.Particle{props.New(100, 100), props.New(200, 100), props.New(500, 500)}
+}
+
+func TestSelection(t *testing.T) {
+	particles := threeParticles()
+	a, b, c := particles[0].ID, particles[1].ID, particles[2].ID
+
+	s := NewSelection()
+	s.Set(a, c)
+	s.Toggle(b)
+	if s.Len() != 3 || s.Primary() != b {
+		t.Errorf("selected %v with primary %d, want all with %d", s.IDs(), s.Primary(), b)
+	}
+	s.Toggle(b)
+	if s.Contains(b) || s.Primary() != c {
+		t.Errorf("after toggling off %d: %v with primary %d", b, s.IDs(), s.Primary())
+	}
+
+	s.Add(a)
+	got := s.Particles(particles[1:])
+	if len(got) != 1 || got[0] != particles[2] || s.Contains(a) {
+		t.Errorf("particles gone weren't dropped: %v", s.IDs())
+	}
+}
+
+func TestBoxSelect(t *testing.T) {
+	particles := threeParticles()
+	controls := NewControls()
+	frames := []renderer.Input{
+		withKeys(renderer.Click(50, 50, renderer.MouseLeft), renderer.KeyLeftShift),
+		withKeys(renderer.Drag(250, 150, renderer.MouseLeft), renderer.KeyLeftShift),
+		renderer.Release(250, 150, renderer.MouseLeft),
+	}
+	for _, in := range frames {
+		HandleUserInput(in, &particles, controls)
+	}
+
+	if len(particles) != 3 {
+		t.Errorf("box select added particles: %d", len(particles))
+	}
+	if want := []int{particles[0].ID, particles[1].ID}; !slices.Equal(controls.Selection.IDs(), want) {
+		t.Errorf("selected %v, want %v", controls.Selection.IDs(), want)
+	}
+}
+
+func TestSelectionOperations(t *testing.T) {
+	ctrl := func(k renderer.Key) renderer.Input {
+		return withKeys(renderer.Press(k), renderer.KeyLeftControl)
+	}
+
+	particles := threeParticles()
+	particles[1].Mass = 20
+	controls := NewControls()
+	controls.Selection.Set(particles[0].ID, particles[1].ID)
+
+	// Kick towards the mouse, 100 pixels right of the selection's centre
+	kick := renderer.Press(renderer.KeyI)
+	kick.MouseX, kick.MouseY = 250, 100
+	HandleUserInput(kick, &particles, controls)
+	if particles[0].Vx != 100 || particles[1].Vx != 50 || particles[2].Vx != 0 {
+		t.Errorf("kicked to %v, %v, %v", particles[0].Vx, particles[1].Vx, particles[2].Vx)
+	}
+
+	HandleUserInput(renderer.Press(renderer.KeyM), &particles, controls)
+	if particles[0].Movable || particles[1].Movable || !particles[2].Movable {
+		t.Error("M didn't pin only the selected particles")
+	}
+
+	HandleUserInput(renderer.Press(renderer.KeyZ), &particles, controls)
+	if particles[0].Vx != 0 || particles[1].Vx != 0 {
+		t.Error("Z didn't stop the selected particles")
+	}
+
+	HandleUserInput(ctrl(renderer.KeyC), &particles, controls)
+	paste := ctrl(renderer.KeyV)
+	paste.MouseX, paste.MouseY = 400, 300
+	HandleUserInput(paste, &particles, controls)
+	if len(particles) != 5 {
+		t.Fatalf("%d particles after pasting, want 5", len(particles))
+	}
+	pasted := particles[3:]
+	if pasted[0].X != 350 || pasted[1].X != 450 || pasted[0].Y != 300 || pasted[0].ID == particles[0].ID {
+		t.Errorf("pasted at (%v, %v) and (%v, %v)", pasted[0].X, pasted[0].Y, pasted[1].X, pasted[1].Y)
+	}
+	if want := []int{pasted[0].ID, pasted[1].ID}; !slices.Equal(controls.Selection.IDs(), want) {
+		t.Errorf("selected %v after pasting, want the pasted %v", controls.Selection.IDs(), want)
+	}
+
+	HandleUserInput(renderer.Press(renderer.KeyDelete), &particles, controls)
+	if len(particles) != 3 || controls.Selection.Len() != 0 {
+		t.Errorf("%d particles and %d selected after deleting", len(particles), controls.Selection.Len())
+	}
+
+	HandleUserInput(ctrl(renderer.KeyA), &particles, controls)
+	if controls.Selection.Len() != 3 {
+		t.Errorf("Ctrl+A selected %d", controls.Selection.Len())
+	}
+}
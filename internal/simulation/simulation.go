@@ -1,89 +1,140 @@
 package simulation
 
 import (
-	"particle-physics-simulator/internal/collisions"
-	// "particle-physics-simulator/internal/force"
+	"particle-physics-simulator/internal/analytics"
+	"particle-physics-simulator/internal/force"
 	"particle-physics-simulator/internal/particle"
-	"particle-physics-simulator/internal/physics"
 	"particle-physics-simulator/internal/renderer"
-	"sync"
-	"time"
-
-	"github.com/gen2brain/raylib-go/raylib"
+	"particle-physics-simulator/internal/world"
 )
 
 const (
-	TimeStep       = 1.0 / 120.0 // Target simulation time step (120 FPS)
-	MagneticFieldX = 0.1      
-	MagneticFieldY = 0.0     
+	TimeStep = 1.0 / 120.0 // Target simulation time step (120 FPS)
 )
 
-func RunSimulation(particles []*particle.Particle) {
-	renderer.InitWindow()
-	defer renderer.CloseWindow()
-
-	paused := false
-	var wg sync.WaitGroup
-
-	for !rl.WindowShouldClose() {
-		currentTime := time.Now()
-
-		// Handle user input (pause/unpause, add/remove particles)
-		HandleUserInput(&particles, &paused)
-
-		if !paused {
-			// Apply global forces (electrostatic and magnetic) to all particles
-			// physics.ApplyElectrostaticForces(particles)
-			// magneticField := force.MagneticField{
-			// 	Strength:  1.0, 
-			// 	Direction: 1,  
-			// }
-			// physics.ApplyMagneticForces(particles, magneticField)
-
-			// Parallelize particle updates
-			wg.Add(len(particles))
-			for _, p := range particles {
-				go func(p *particle.Particle) {
-					defer wg.Done()
-					physics.UpdateVelocity(p, TimeStep)
-					physics.UpdatePosition(p, TimeStep)
-				}(p)
-			}
-			wg.Wait()
-
-			// Check and handle collisions between particles in parallel
-			wg.Add(len(particles) * (len(particles) - 1) / 2) // Maximum number of collisions
-			for i := 0; i < len(particles); i++ {
-				for j := i + 1; j < len(particles); j++ {
-					go func(i, j int) {
-						defer wg.Done()
-						if collisions.WillCollide(particles[i], particles[j], TimeStep) {
-							collisions.HandleCollision(particles[i], particles[j])
-						}
-					}(i, j)
-				}
-			}
-			wg.Wait()
+// RunSimulation runs the interactive simulation without external fields.
+func RunSimulation(r renderer.Renderer, particles []*particle.Particle) {
+	RunSimulationWithFields(r, particles, force.ExternalFields{})
+}
+
+// RunSimulationWithFields runs the interactive simulation with externally applied electric and magnetic fields.
+func RunSimulationWithFields(r renderer.Renderer, particles []*particle.Particle, fields force.ExternalFields) {
+	Run(r, NewWorld(r, particles, fields))
+}
+
+// NewWorld creates the world of the interactive simulation, filling the renderer and keeping
+// the energy account of the latest step.
+func NewWorld(r renderer.Renderer, particles []*particle.Particle, fields force.ExternalFields) *world.World {
+	width, height := r.Size()
+	w := world.New(particles, width, height)
+	w.Fields = fields
+	w.Ledger = analytics.NewLedger(1) // Only the latest entry is shown
+	return w
+}
+
+// Run steps and draws the world once per frame until the renderer asks to close. The
+// backend sets the frame rate: the window waits for the next frame while the others run
+// as fast as they can.
+func Run(r renderer.Renderer, w *world.World) {
+	RunWithControls(r, w, NewControls())
+}
+
+// RunWithControls runs the simulation like Run, starting from the given controls.
+func RunWithControls(r renderer.Renderer, w *world.World, controls *Controls) {
+	view := renderer.NewView(r, controls.Camera)
+	fieldLines := renderer.NewFieldLineCache()
+	// The force tool acts through the fields like any other force
+	defer addForceTool(&w.Fields, controls.Force)()
+	if controls.Rewind != nil && controls.Rewind.Len() == 0 {
+		controls.Rewind.Record(w)
+	}
+
+	for !r.ShouldClose() {
+		screenWidth, screenHeight := r.Size()
+		handleResize(w, controls, screenWidth, screenHeight)
+
+		// The mouse is for the editor, or the timeline while rewinding, rather than the world
+		// while over them
+		screenInput, input := r.Input(), view.Input()
+		timeline := renderer.TimelineBounds(screenWidth, screenHeight)
+		overTimeline := controls.rewinding && timelineHit(timeline).Contains(screenInput.MouseX, screenInput.MouseY)
+		if controls.Editor.Covers(r, screenInput.MouseX, screenInput.MouseY) || overTimeline {
+			screenInput, input = screenInput.WithoutMouse(), input.WithoutMouse()
 		}
+		handleCameraInput(screenInput, controls.Camera)
 
-		// Render the simulation
-		rl.BeginDrawing()
-		rl.ClearBackground(rl.Black)
+		// Handle user input (pause/unpause, add/remove particles) with the mouse in world coordinates
+		HandleUserInput(input, &w.Particles, controls)
+		handleHistoryKeys(input, w, controls)
+		handleRewind(r.Input(), w, controls, timeline)
+		particles := w.Particles
 
-		for _, p := range particles {
-			renderer.DrawParticle(p)
+		// Save the current state as an SVG figure with S
+		if input.KeyPressed(renderer.KeyS) {
+			saveSnapshot(w, controls)
 		}
 
-		renderer.DrawUI(particles, paused)
-        renderer.DrawWindowButtons()
-		renderer.DrawParticleInfo(particles)
+		if !controls.Paused {
+			w.Step(TimeStep)
+			controls.Trails.Record(w.Particles)
+			if controls.Rewind != nil {
+				controls.Rewind.Record(w)
+			}
+		}
+		updateFollow(controls.Camera, w.Particles, controls.Selection.Primary(), screenWidth, screenHeight)
 
-		rl.EndDrawing()
+		// Render the simulation
+		r.BeginFrame(renderer.Background)
 
-		// Sleep to maintain a consistent frame rate (120 FPS)
-		sleepDuration := time.Second/120 - time.Since(currentTime)
-		if sleepDuration > 0 {
-			time.Sleep(sleepDuration)
+		renderer.DrawScene(view, renderer.Scene{
+			Particles: particles,
+			Width:     float64(w.Width),
+			Height:    float64(w.Height),
+			Overlays:  controls.Overlays,
+			External: func(x, y float64) (float64, float64) {
+				return w.Fields.ElectricFieldAt(x, y, w.Time)
+			},
+			Trails:     controls.Trails,
+			FieldLines: fieldLines,
+			Step:       w.Steps,
+			Version:    controls.History.Edits(),
+		})
+		selected := controls.Selection.Particles(particles)
+		for _, p := range selected {
+			renderer.DrawSelection(view, p)
+		}
+		if b := controls.box; b != nil {
+			renderer.DrawSelectionBox(view, b.Rectangle())
+		}
+		if controls.ForceTool {
+			renderer.DrawForceTool(view, controls.Force)
+		}
+		if l := controls.launch; l != nil {
+			p := l.particle(controls.Editor.Placing)
+			renderer.DrawLaunch(view, p, l.toX, l.toY, predictTrajectory(w, p, previewSteps))
 		}
+
+		renderer.DrawUI(r, particles, controls.Paused)
+		renderer.DrawCameraInfo(r, controls.Camera)
+		if w.Ledger != nil {
+			if entry, ok := w.Ledger.Last(); ok {
+				renderer.DrawEnergyInfo(r, entry)
+			}
+		}
+		renderer.DrawWindowButtons(r)
+		renderer.DrawParticleInfo(view, particles)
+		renderer.DrawSelectionInfo(r, selected)
+		drawRewind(r, controls)
+		// Edits made in the editor take effect from the next step. Dragging a slider is undone
+		// as one edit.
+		before := snapshot(selected)
+		if renderer.DrawEditor(r, controls.Editor, selected) && len(selected) > 0 {
+			controls.History.changedContinuously(before, snapshot(selected))
+		}
+		if !r.Input().ButtonDown(renderer.MouseLeft) {
+			controls.History.seal()
+		}
+
+		r.EndFrame()
 	}
 }
@@ -0,0 +1,333 @@
+package simulation
+
+import (
+	"math"
+	"particle-physics-simulator/internal/analytics"
+	"particle-physics-simulator/internal/force"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/trajectory"
+	"particle-physics-simulator/internal/world"
+	"slices"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	r := renderer.NewNull(800, 600)
+	r.Frames = []renderer.Input{
+		renderer.Click(100, 100, renderer.MouseLeft),
+		{},
+		renderer.Press(renderer.KeySpace, renderer.KeyF),
+		{},
+	}
+
+	charge := &particle.Particle{X: 400, Y: 300, Mass: 1, Radius: 5, Charge: 1, Movable: false}
+	w := world.New([]*particle.Particle{charge}, 800, 600)
+	w.Ledger = analytics.NewLedger(1)
+	Run(r, w)
+
+	if r.Frame() != 4 {
+		t.Fatalf("ran %d frames, want 4", r.Frame())
+	}
+	if len(w.Particles) != 2 {
+		t.Fatalf("%d particles after clicking, want 2", len(w.Particles))
+	}
+	// Two steps before the pause
+	if w.Steps != 2 {
+		t.Errorf("stepped %d times, want 2", w.Steps)
+	}
+
+	last := r.Last
+	if !slices.Contains(last.Texts, "Status: Paused") {
+		t.Errorf("last frame texts %q don't show the pause", last.Texts)
+	}
+	// The field overlay is drawn as arrows
+	if last.Lines == 0 {
+		t.Errorf("last frame drew %d lines, want the field overlay", last.Lines)
+	}
+	if last.Circles != 2 {
+		t.Errorf("last frame drew %d circles, want 2", last.Circles)
+	}
+}
+
+func TestRunCloseButton(t *testing.T) {
+	r := renderer.NewNull(800, 600)
+	r.MaxFrames = 100
+	// The close button is the first of the three in the top right corner
+	r.Frames = []renderer.Input{{}, renderer.Click(float64(r.Width)-50, 10, renderer.MouseLeft)}
+
+	RunSimulation(r, []*particle.Particle{particle.NewParticle(100, 100, 0, 0, 0, 0, 1, 5, particle.Color{A: 1}, true)})
+	if r.Frame() != 2 {
+		t.Errorf("closed after %d frames, want 2", r.Frame())
+	}
+}
+
+func TestSelectAndTrail(t *testing.T) {
+	p := particle.NewParticle(200, 200, 0, 0, 0, 0, 1, 10, particle.Color{A: 1}, false)
+	shiftT := renderer.Press(renderer.KeyT)
+	shiftT.Down = append(shiftT.Down, renderer.KeyLeftShift)
+
+	frames := []renderer.Input{renderer.Click(205, 200, renderer.MouseLeft), shiftT, {}}
+	w := world.New([]*particle.Particle{p}, 800, 600)
+
+	controls := NewControls()
+	for _, in := range frames {
+		HandleUserInput(in, &w.Particles, controls)
+	}
+	if len(w.Particles) != 1 {
+		t.Errorf("clicking a particle added one: %d particles", len(w.Particles))
+	}
+	if controls.Selection.Primary() != p.ID || controls.Selection.Len() != 1 {
+		t.Errorf("selected %v, want only %d", controls.Selection.IDs(), p.ID)
+	}
+	if controls.Trails.All || !controls.Trails.IsShown(p.ID) {
+		t.Error("shift+T didn't show only the selected particle's trail")
+	}
+}
+
+func TestRunZoomedClick(t *testing.T) {
+	zoom := renderer.Input{MouseX: 0, MouseY: 0, Wheel: 1}
+	r := renderer.NewNull(800, 600)
+	r.Frames = []renderer.Input{zoom, renderer.Click(220, 110, renderer.MouseLeft), renderer.Release(220, 110, renderer.MouseLeft)}
+
+	w := world.New(nil, 800, 600)
+	Run(r, w)
+	if len(w.Particles) != 1 {
+		t.Fatalf("%d particles, want 1", len(w.Particles))
+	}
+	// Zoomed in by zoomStep around the origin, so (220, 110) on screen is (200, 100) in the
+	// world, less the little the particle has moved since
+	p := w.Particles[0]
+	if math.Abs(p.X-200) > 1 || math.Abs(p.Y-100) > 1 {
+		t.Errorf("particle added at (%v, %v), want about (200, 100)", p.X, p.Y)
+	}
+}
+
+func TestRunResize(t *testing.T) {
+	maximize := renderer.Click(800-2*20+5, 5, renderer.MouseLeft)
+	for _, test := range []struct {
+		resize        Resize
+		width, height int
+		zoom          float64
+	}{
+		{ResizeWorld, 1600, 1000, 1},
+		{ResizeScale, 800, 600, 1000.0 / 600},
+	} {
+		r := renderer.NewNull(800, 600)
+		r.ScreenWidth, r.ScreenHeight = 1600, 1000
+		r.Frames = []renderer.Input{maximize, {}}
+		w := world.New(nil, 800, 600)
+		controls := NewControls()
+		controls.Resize = test.resize
+
+		RunWithControls(r, w, controls)
+		if w.Width != test.width || w.Height != test.height {
+			t.Errorf("%v: world is %dx%d, want %dx%d", test.resize, w.Width, w.Height, test.width, test.height)
+		}
+		if controls.Camera.Zoom != test.zoom {
+			t.Errorf("%v: zoom %v, want %v", test.resize, controls.Camera.Zoom, test.zoom)
+		}
+	}
+}
+
+func TestParseResize(t *testing.T) {
+	for _, mode := range []Resize{ResizeWorld, ResizeScale} {
+		if got, err := ParseResize(mode.String()); got != mode || err != nil {
+			t.Errorf("ParseResize(%q) = %v, %v", mode, got, err)
+		}
+	}
+	if _, err := ParseResize("stretch"); err == nil {
+		t.Error("ParseResize accepted an unknown mode")
+	}
+}
+
+func TestRunEditor(t *testing.T) {
+	controls := NewControls()
+	panel := controls.Editor.Bounds(800, 600)
+	// The species button is the second row of the panel
+	species := renderer.Click(panel.X+panel.Width/2, panel.Y+10+28+12, renderer.MouseLeft)
+
+	r := renderer.NewNull(800, 600)
+	r.Frames = []renderer.Input{
+		renderer.Press(renderer.KeyE), species,
+		renderer.Click(100, 300, renderer.MouseLeft), renderer.Release(100, 300, renderer.MouseLeft),
+	}
+	w := world.New(nil, 800, 600)
+	RunWithControls(r, w, controls)
+
+	if len(w.Particles) != 1 {
+		t.Fatalf("%d particles, want only the one placed outside the editor", len(w.Particles))
+	}
+	if got, want := w.Particles[0].Properties(), particle.SpeciesList()[1].Properties; got != want {
+		t.Errorf("placed %+v, want the second species %+v", got, want)
+	}
+}
+
+func TestRunLaunch(t *testing.T) {
+	r := renderer.NewNull(800, 600)
+	r.Frames = []renderer.Input{
+		renderer.Click(400, 300, renderer.MouseLeft),
+		renderer.Drag(380, 310, renderer.MouseLeft),
+		// Let go over nothing in particular; the particle goes where the mouse was held
+		{},
+	}
+	w := world.New(nil, 800, 600)
+	controls := NewControls()
+	controls.Paused = true // Keep the particle where it was launched from
+	RunWithControls(r, w, controls)
+
+	if len(w.Particles) != 1 {
+		t.Fatalf("%d particles, want 1", len(w.Particles))
+	}
+	// Dragged back 20 left and 10 down, so launched right and up
+	p := w.Particles[0]
+	if p.Vx != 20*launchSpeed || p.Vy != -10*launchSpeed || p.X != 400 || p.Y != 300 {
+		t.Errorf("launched from (%v, %v) at (%v, %v)", p.X, p.Y, p.Vx, p.Vy)
+	}
+	if controls.launch != nil {
+		t.Error("launch still in progress after letting go")
+	}
+}
+
+func TestPredictTrajectory(t *testing.T) {
+	w := world.New(nil, 800, 600)
+	// Pushes a positive charge right
+	w.Fields.Electric = []force.ElectricFieldSource{force.UniformElectricField{Ex: 1000}}
+	p := particle.SpeciesList()[1].New(100, 100)
+	p.Vy = -50
+
+	path := predictTrajectory(w, p, 120)
+	if len(path) != 121 || path[0] != (renderer.Point{X: 100, Y: 100}) {
+		t.Fatalf("path of %d points starting at %v", len(path), path[0])
+	}
+	if end := path[len(path)-1]; end.X <= 100 {
+		t.Errorf("path ends at %v, want pushed right by the field", end)
+	}
+	if p.X != 100 || p.Y != 100 || p.Vy != -50 || len(w.Particles) != 0 {
+		t.Error("prediction changed the particle or the world")
+	}
+}
+
+func TestRunForceTool(t *testing.T) {
+	p := particle.NewParticle(400, 300, 0, 0, 0, 0, 1, 5, particle.Color{A: 1}, true)
+	hold := renderer.Drag(500, 300, renderer.MouseLeft)
+	r := renderer.NewNull(800, 600)
+	r.Frames = []renderer.Input{renderer.Press(renderer.KeyG), renderer.Click(500, 300, renderer.MouseLeft), hold, hold}
+
+	w := world.New([]*particle.Particle{p}, 800, 600)
+	controls := NewControls()
+	RunWithControls(r, w, controls)
+
+	if len(w.Particles) != 1 {
+		t.Errorf("clicking with the force tool added particles: %d", len(w.Particles))
+	}
+	if p.Vx <= 0 {
+		t.Errorf("particle moves at %v along x, want pulled right towards the mouse", p.Vx)
+	}
+	if !controls.ForceTool || controls.Force.Mode != force.Attract {
+		t.Errorf("tool on %v in mode %v, want attracting", controls.ForceTool, controls.Force.Mode)
+	}
+	if len(w.Fields.Forces) != 0 {
+		t.Error("force tool left in the world's forces after the run")
+	}
+
+	// G cycles on through repel and spring, then off
+	for _, want := range []force.PointForceMode{force.Repel, force.Spring} {
+		handleForceTool(renderer.Press(renderer.KeyG), controls)
+		if controls.Force.Mode != want {
+			t.Errorf("mode %v, want %v", controls.Force.Mode, want)
+		}
+	}
+	handleForceTool(renderer.Press(renderer.KeyG), controls)
+	if controls.ForceTool || controls.Force.Active {
+		t.Error("force tool still on after cycling through the modes")
+	}
+}
+
+func TestRunRewind(t *testing.T) {
+	p := particle.NewParticle(400, 100, 30, 0, 0, 0, 1, 5, particle.Color{A: 1}, true)
+	w := world.New([]*particle.Particle{p}, 800, 600)
+	controls := NewControls()
+	run := func(frames ...renderer.Input) {
+		r := renderer.NewNull(800, 600)
+		r.Frames = frames
+		RunWithControls(r, w, controls)
+	}
+
+	run(make([]renderer.Input, 20)...)
+	if w.Steps != 20 || controls.Rewind.Len() != 21 {
+		t.Fatalf("%d steps taken and %d held, want 20 and 21 with the start", w.Steps, controls.Rewind.Len())
+	}
+	at := func(steps int) particle.Particle {
+		state := world.New(nil, 800, 600)
+		controls.Rewind.Restore(steps, state)
+		return *state.Particles[0]
+	}
+
+	// R pauses, Shift+Comma goes back ten steps
+	run(renderer.Press(renderer.KeyR), withKeys(renderer.Press(renderer.KeyComma), renderer.KeyLeftShift))
+	if !controls.Paused || w.Steps != 10 || *w.Particles[0] != at(10) {
+		t.Errorf("rewound to step %d, paused %v, want paused at step 10", w.Steps, controls.Paused)
+	}
+
+	// Clicking the start of the timeline scrubs to the oldest step without adding a particle
+	bar := renderer.TimelineBounds(800, 600)
+	run(renderer.Click(bar.X, bar.Y+bar.Height/2, renderer.MouseLeft), renderer.Release(bar.X, bar.Y+bar.Height/2, renderer.MouseLeft))
+	if w.Steps != 0 || len(w.Particles) != 1 || w.Particles[0].X != 400 {
+		t.Errorf("scrubbed to step %d with %d particles at x = %v, want the start", w.Steps, len(w.Particles), w.Particles[0].X)
+	}
+
+	// Space resumes from there, forgetting the later steps
+	run(renderer.Press(renderer.KeySpace))
+	if controls.Paused || controls.rewinding || w.Steps != 1 || controls.Rewind.Len() != 2 {
+		t.Errorf("resumed to step %d with %d held, want running at step 1 with 2 held", w.Steps, controls.Rewind.Len())
+	}
+	if w.Particles[0] == p || w.Particles[0].ID != p.ID {
+		t.Error("restored particle should be a copy with the same ID")
+	}
+}
+
+func TestRunRewindForgetsEdits(t *testing.T) {
+	a := particle.NewParticle(200, 100, 0, 0, 0, 0, 1, 5, particle.Color{A: 1}, true)
+	b := particle.NewParticle(600, 300, 0, 0, 0, 0, 1, 5, particle.Color{A: 1}, false)
+	w := world.New([]*particle.Particle{a, b}, 800, 600)
+	controls := NewControls()
+	frames := append(make([]renderer.Input, 5), renderer.Click(600, 300, renderer.MouseRight))
+	frames = append(frames, make([]renderer.Input, 5)...)
+	r := renderer.NewNull(800, 600)
+	r.Frames = frames
+	RunWithControls(r, w, controls)
+	if len(w.Particles) != 1 || !controls.History.CanUndo() {
+		t.Fatalf("%d particles after removing one, want 1 with the removal undoable", len(w.Particles))
+	}
+
+	// Going back to before the removal brings b back, so undoing the removal must not
+	r = renderer.NewNull(800, 600)
+	r.Frames = []renderer.Input{
+		renderer.Press(renderer.KeyR),
+		withKeys(renderer.Press(renderer.KeyComma), renderer.KeyLeftShift),
+		withKeys(renderer.Press(renderer.KeyZ), renderer.KeyLeftControl),
+	}
+	RunWithControls(r, w, controls)
+	if got := ids(w.Particles); !slices.Equal(got, []int{a.ID, b.ID}) {
+		t.Errorf("particles %v after rewinding and undoing, want %v", got, []int{a.ID, b.ID})
+	}
+	if controls.History.CanUndo() || controls.History.CanRedo() {
+		t.Error("edits from before rewinding can still be undone or redone")
+	}
+}
+
+func TestFrameBox(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		frame         trajectory.Frame
+		width, height float64
+	}{
+		{"recorded box", trajectory.Frame{Width: 1800, Height: 950}, 1800, 950},
+		{"no box", trajectory.Frame{}, 800, 600},
+	} {
+		if w, h := frameBox(tc.frame, 800, 600); w != tc.width || h != tc.height {
+			t.Errorf("%s: box %vx%v in an 800x600 window, want %vx%v", tc.name, w, h, tc.width, tc.height)
+		}
+	}
+}
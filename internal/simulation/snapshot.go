@@ -0,0 +1,22 @@
+package simulation
+
+import (
+	"fmt"
+	"log"
+	"particle-physics-simulator/internal/svg"
+	"particle-physics-simulator/internal/world"
+)
+
+// saveSnapshot writes the world to an SVG file in the working directory named after the
+// step, with field lines and trails when they are shown.
+func saveSnapshot(w *world.World, controls *Controls) {
+	opts := svg.DefaultOptions()
+	opts.FieldLines = controls.Overlays.FieldLines
+	opts.Trails = controls.Trails
+	path := fmt.Sprintf("snapshot_%06d.svg", w.Steps)
+	if err := svg.SaveSnapshot(path, w, opts); err != nil {
+		log.Printf("saving snapshot: %v", err)
+		return
+	}
+	log.Printf("saved snapshot %s", path)
+}
@@ -0,0 +1,384 @@
+package svg
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"particle-physics-simulator/internal/colormap"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/world"
+	"strings"
+)
+
+// DefaultVelocityScale is the number of seconds of motion a velocity arrow spans.
+const DefaultVelocityScale = 0.25
+
+const (
+	legendTextSize = 14
+	legendPadding  = 10
+	legendSwatch   = 24 // Width of the sample drawn before each legend label
+	colorBarWidth  = 160
+	colorBarHeight = 10
+	maxLegendItems = 8 // Particle colours listed before the rest are summarised
+)
+
+var (
+	arrowColor    = color.RGBA{R: 255, G: 255, B: 255, A: 220}
+	trailColor    = color.RGBA{R: 255, G: 255, B: 255, A: 110}
+	legendFill    = color.RGBA{R: 20, G: 20, B: 20, A: 200}
+	legendText    = color.RGBA{R: 235, G: 235, B: 235, A: 255}
+	fieldLineMark = color.RGBA{R: 200, G: 220, B: 255, A: 150}
+)
+
+// ColorBy selects how a snapshot colours the particles.
+type ColorBy int
+
+const (
+	ColorByParticle      ColorBy = iota // Each particle's own Color
+	ColorBySpeed                        // Sequential colormap of the speed
+	ColorByCharge                       // Diverging colormap of the charge
+	ColorByMass                         // Sequential colormap of the mass
+	ColorByKineticEnergy                // Sequential colormap of the kinetic energy
+)
+
+var colorByNames = []string{"color", "speed", "charge", "mass", "energy"}
+
+func (c ColorBy) String() string {
+	if c < 0 || int(c) >= len(colorByNames) {
+		return fmt.Sprintf("ColorBy(%d)", int(c))
+	}
+	return colorByNames[c]
+}
+
+// ParseColorBy parses "color", "speed", "charge", "mass" or "energy".
+func ParseColorBy(s string) (ColorBy, error) {
+	for i, name := range colorByNames {
+		if strings.EqualFold(s, name) {
+			return ColorBy(i), nil
+		}
+	}
+	return 0, fmt.Errorf("svg: unknown particle colouring %q (want %s)", s, strings.Join(colorByNames, ", "))
+}
+
+// quantity returns the value coloured, its label for the legend and whether it is signed.
+func (c ColorBy) quantity() (value func(p *particle.Particle) float64, label string, signed bool) {
+	switch c {
+	case ColorBySpeed:
+		return func(p *particle.Particle) float64 {
+			return math.Sqrt(p.Vx*p.Vx + p.Vy*p.Vy + p.Vz*p.Vz)
+		}, "Speed (px/s)", false
+	case ColorByCharge:
+		return func(p *particle.Particle) float64 { return p.Charge }, "Charge (C)", true
+	case ColorByMass:
+		return func(p *particle.Particle) float64 { return p.Mass }, "Mass (kg)", false
+	case ColorByKineticEnergy:
+		return (*particle.Particle).KineticEnergy, "Kinetic energy (kg*px^2/s^2)", false
+	}
+	return nil, "", false
+}
+
+// Options selects what a snapshot shows besides the particles and walls.
+type Options struct {
+	ColorBy       ColorBy
+	Velocities    bool             // Draw velocity arrows
+	VelocityScale float64          // Seconds of motion each arrow spans; zero means DefaultVelocityScale
+	FieldLines    bool             // Trace electric field lines, including any external field
+	Trails        *renderer.Trails // Trails drawn under the particles, or nil
+	Legend        bool
+	ScaleBar      bool
+}
+
+// DefaultOptions returns the options for a figure: particles in their own colours with a
+// legend and scale bar.
+func DefaultOptions() Options {
+	return Options{Legend: true, ScaleBar: true}
+}
+
+// SaveSnapshot writes the world as an SVG file.
+func SaveSnapshot(path string, w *world.World, opts Options) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteSnapshot(f, w, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteSnapshot writes the world as an SVG document.
+func WriteSnapshot(out io.Writer, w *world.World, opts Options) error {
+	r := NewRenderer(w.Width, w.Height)
+	r.BeginFrame(renderer.Background)
+	DrawSnapshot(r, w, opts)
+	r.EndFrame()
+	_, err := out.Write(r.Bytes())
+	return err
+}
+
+// DrawSnapshot draws the figure of the world with any backend.
+func DrawSnapshot(r renderer.Renderer, w *world.World, opts Options) {
+	s := renderer.Scene{
+		Particles: w.Particles,
+		Width:     float64(w.Width),
+		Height:    float64(w.Height),
+		Overlays:  renderer.Overlays{FieldLines: opts.FieldLines},
+		External: func(x, y float64) (float64, float64) {
+			return w.Fields.ElectricFieldAt(x, y, w.Time)
+		},
+		Walls:  true,
+		Trails: opts.Trails,
+	}
+	scale := newColorScale(w.Particles, opts.ColorBy)
+	if scale != nil {
+		s.ParticleColor = scale.color
+	}
+	renderer.DrawScene(r, s)
+
+	velocityScale := opts.VelocityScale
+	if velocityScale == 0 {
+		velocityScale = DefaultVelocityScale
+	}
+	if opts.Velocities {
+		for _, p := range w.Particles {
+			drawArrow(r, p.X, p.Y, p.X+p.Vx*velocityScale, p.Y+p.Vy*velocityScale, arrowColor)
+		}
+	}
+
+	if opts.ScaleBar {
+		drawScaleBar(r, float64(w.Width), float64(w.Height))
+	}
+	if opts.Legend {
+		drawLegend(r, w, opts, scale, velocityScale)
+	}
+}
+
+// colorScale maps a particle quantity onto a colormap over the range found in the world.
+type colorScale struct {
+	value    func(p *particle.Particle) float64
+	label    string
+	signed   bool
+	min, max float64
+}
+
+func newColorScale(particles []*particle.Particle, by ColorBy) *colorScale {
+	value, label, signed := by.quantity()
+	if value == nil {
+		return nil
+	}
+	cs := &colorScale{value: value, label: label, signed: signed, min: math.Inf(1), max: math.Inf(-1)}
+	for _, p := range particles {
+		v := value(p)
+		cs.min, cs.max = math.Min(cs.min, v), math.Max(cs.max, v)
+	}
+	if len(particles) == 0 {
+		cs.min, cs.max = 0, 0
+	}
+	// Signed quantities get a symmetric range so zero is the neutral colour
+	if signed {
+		cs.max = math.Max(math.Abs(cs.min), math.Abs(cs.max))
+		cs.min = -cs.max
+	}
+	return cs
+}
+
+// at returns the colour for a position in [0, 1] along the range.
+func (cs *colorScale) at(t float64) color.RGBA {
+	if cs.signed {
+		return colormap.Diverging(2*t - 1)
+	}
+	return colormap.Sequential(t)
+}
+
+func (cs *colorScale) color(p *particle.Particle) color.RGBA {
+	if cs.max == cs.min {
+		return cs.at(0.5)
+	}
+	return cs.at((cs.value(p) - cs.min) / (cs.max - cs.min))
+}
+
+// drawArrow draws an arrow from (x0, y0) to (x1, y1) with a filled head.
+func drawArrow(r renderer.Renderer, x0, y0, x1, y1 float64, c color.RGBA) {
+	dx, dy := x1-x0, y1-y0
+	length := math.Hypot(dx, dy)
+	if length < 1 {
+		return
+	}
+	ux, uy := dx/length, dy/length
+	head := math.Min(8, 0.4*length)
+
+	baseX, baseY := x1-ux*head, y1-uy*head
+	r.Line(x0, y0, baseX, baseY, 1.5, c)
+	r.Polygon([]renderer.Point{
+		{X: x1, Y: y1},
+		{X: baseX - uy*head/2, Y: baseY + ux*head/2},
+		{X: baseX + uy*head/2, Y: baseY - ux*head/2},
+	}, c)
+}
+
+// niceLength returns the largest 1, 2 or 5 times a power of ten no longer than limit.
+func niceLength(limit float64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	power := math.Pow(10, math.Floor(math.Log10(limit)))
+	for _, m := range []float64{5, 2, 1} {
+		if m*power <= limit {
+			return m * power
+		}
+	}
+	return power
+}
+
+// drawScaleBar draws a bar of a round length in the bottom left corner.
+func drawScaleBar(r renderer.Renderer, width, height float64) {
+	length := niceLength(width / 8)
+	x, y := 20.0, height-20
+	r.Line(x, y, x+length, y, 2, legendText)
+	r.Line(x, y-5, x, y+5, 2, legendText)
+	r.Line(x+length, y-5, x+length, y+5, 2, legendText)
+
+	label := fmt.Sprintf("%g px", length)
+	r.Text(label, x+(length-r.TextWidth(label, legendTextSize))/2, y-8-legendTextSize, legendTextSize, legendText)
+}
+
+// legendItem is one row of the legend: a sample drawn by mark and a label.
+type legendItem struct {
+	label string
+	mark  func(x, y float64) // Draws the sample with its left edge at x, centred on y
+}
+
+// drawLegend draws the time, the particle colours or colormap and the line styles in a
+// box in the top right corner.
+func drawLegend(r renderer.Renderer, w *world.World, opts Options, scale *colorScale, velocityScale float64) {
+	lines := []string{
+		fmt.Sprintf("t = %.3f s", w.Time),
+		fmt.Sprintf("%d particles", len(w.Particles)),
+	}
+
+	var items []legendItem
+	if scale == nil {
+		items = append(items, particleColorItems(r, w.Particles)...)
+	}
+	if opts.Velocities {
+		items = append(items, legendItem{fmt.Sprintf("velocity x %g s", velocityScale), func(x, y float64) {
+			drawArrow(r, x, y, x+legendSwatch-4, y, arrowColor)
+		}})
+	}
+	if opts.FieldLines {
+		items = append(items, legendItem{"field line", func(x, y float64) {
+			r.Line(x, y, x+legendSwatch-4, y, 1, fieldLineMark)
+		}})
+	}
+	if opts.Trails != nil && len(opts.Trails.Visible()) > 0 {
+		items = append(items, legendItem{"trail", func(x, y float64) {
+			r.Line(x, y, x+legendSwatch-4, y, 1, trailColor)
+		}})
+	}
+
+	// Size the box to its contents
+	rowHeight := legendTextSize + 6.0
+	contentWidth := 0.0
+	for _, line := range lines {
+		contentWidth = math.Max(contentWidth, r.TextWidth(line, legendTextSize))
+	}
+	for _, item := range items {
+		contentWidth = math.Max(contentWidth, legendSwatch+r.TextWidth(item.label, legendTextSize))
+	}
+	contentHeight := rowHeight * float64(len(lines)+len(items))
+	if scale != nil {
+		contentWidth = math.Max(contentWidth, math.Max(colorBarWidth, r.TextWidth(scale.label, legendTextSize)))
+		contentHeight += 2*rowHeight + colorBarHeight
+	}
+
+	boxWidth := contentWidth + 2*legendPadding
+	x := float64(w.Width) - boxWidth - 20
+	y := 30.0 // Below the window buttons
+	r.Rect(x, y, boxWidth, contentHeight+2*legendPadding, legendFill)
+	x += legendPadding
+	y += legendPadding
+
+	for _, line := range lines {
+		r.Text(line, x, y, legendTextSize, legendText)
+		y += rowHeight
+	}
+	if scale != nil {
+		drawColorBar(r, scale, x, y)
+		y += 2*rowHeight + colorBarHeight
+	}
+	for _, item := range items {
+		item.mark(x, y+legendTextSize/2)
+		r.Text(item.label, x+legendSwatch, y, legendTextSize, legendText)
+		y += rowHeight
+	}
+}
+
+// drawColorBar draws the colormap's label, gradient and end values from (x, y).
+func drawColorBar(r renderer.Renderer, scale *colorScale, x, y float64) {
+	r.Text(scale.label, x, y, legendTextSize, legendText)
+	y += legendTextSize + 6
+
+	const steps = 32
+	stepWidth := float64(colorBarWidth) / steps
+	for i := 0; i < steps; i++ {
+		r.Rect(x+float64(i)*stepWidth, y, stepWidth+0.5, colorBarHeight, scale.at((float64(i)+0.5)/steps))
+	}
+	y += colorBarHeight + 4
+
+	low, high := fmt.Sprintf("%.3g", scale.min), fmt.Sprintf("%.3g", scale.max)
+	r.Text(low, x, y, legendTextSize, legendText)
+	r.Text(high, x+colorBarWidth-r.TextWidth(high, legendTextSize), y, legendTextSize, legendText)
+}
+
+// particleColorItems lists each distinct particle colour, named by the charge of the
+// particles drawn in it.
+func particleColorItems(r renderer.Renderer, particles []*particle.Particle) []legendItem {
+	type group struct {
+		color              color.RGBA
+		count              int
+		positive, negative int
+	}
+	var groups []*group
+	index := map[color.RGBA]*group{}
+	for _, p := range particles {
+		c := p.Color.RGBA8()
+		g, ok := index[c]
+		if !ok {
+			g = &group{color: c}
+			index[c] = g
+			groups = append(groups, g)
+		}
+		g.count++
+		if p.Charge > 0 {
+			g.positive++
+		} else if p.Charge < 0 {
+			g.negative++
+		}
+	}
+
+	var items []legendItem
+	for i, g := range groups {
+		if i == maxLegendItems {
+			items = append(items, legendItem{fmt.Sprintf("%d more colours", len(groups)-i), func(x, y float64) {}})
+			break
+		}
+		kind := "mixed"
+		switch {
+		case g.positive == g.count:
+			kind = "positive"
+		case g.negative == g.count:
+			kind = "negative"
+		case g.positive == 0 && g.negative == 0:
+			kind = "neutral"
+		}
+		c := g.color
+		items = append(items, legendItem{fmt.Sprintf("%s (%d)", kind, g.count), func(x, y float64) {
+			r.Circle(x+legendSwatch/2-2, y, 6, c)
+		}})
+	}
+	return items
+}
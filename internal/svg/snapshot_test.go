@@ -0,0 +1,104 @@
+package svg
+
+import (
+	"bytes"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/renderer"
+	"particle-physics-simulator/internal/world"
+	"strings"
+	"testing"
+)
+
+func TestParseColorBy(t *testing.T) {
+	for _, by := range []ColorBy{ColorByParticle, ColorBySpeed, ColorByCharge, ColorByMass, ColorByKineticEnergy} {
+		got, err := ParseColorBy(by.String())
+		if err != nil || got != by {
+			t.Errorf("ParseColorBy(%q) = %v, %v", by.String(), got, err)
+		}
+	}
+	if _, err := ParseColorBy("rainbow"); err == nil {
+		t.Error("ParseColorBy accepted an unknown name")
+	}
+}
+
+func TestNiceLength(t *testing.T) {
+	tests := []struct{ limit, want float64 }{
+		{225, 200},
+		{99, 50},
+		{1, 1},
+		{0.3, 0.2},
+		{7000, 5000},
+	}
+	for _, tt := range tests {
+		if got := niceLength(tt.limit); got != tt.want {
+			t.Errorf("niceLength(%v) = %v, want %v", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func snapshotWorld() *world.World {
+	w := world.New([]*particle.Particle{
+		{X: 100, Y: 100, Vx: 40, Radius: 10, Mass: 1, Charge: 1e-6, Color: particle.Color{R: 1, A: 1}},
+		{X: 300, Y: 200, Vy: -20, Radius: 10, Mass: 2, Charge: -1e-6, Color: particle.Color{B: 1, A: 1}},
+	}, 800, 400)
+	w.Time = 1.5
+	return w
+}
+
+func TestWriteSnapshot(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Velocities = true
+	opts.FieldLines = true
+	w := snapshotWorld()
+	opts.Trails = renderer.NewTrails(10)
+	opts.Trails.All = true
+	for i := 0; i < 3; i++ {
+		opts.Trails.Record(w.Particles)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, w, opts); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.String()
+	for _, want := range []string{
+		`<circle cx="100" cy="100" r="10" fill="#ff0000"/>`,
+		`>t = 1.500 s</text>`,
+		`>positive (1)</text>`,
+		`>negative (1)</text>`,
+		`>velocity x 0.25 s</text>`,
+		`>field line</text>`,
+		`>trail</text>`,
+		`>100 px</text>`,
+		`<polygon`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("snapshot is missing %s", want)
+		}
+	}
+}
+
+func TestWriteSnapshotColormap(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ColorBy = ColorByCharge
+
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, snapshotWorld(), opts); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.String()
+	// The charges are symmetric, so they take the two ends of the diverging colormap
+	for _, want := range []string{
+		`<circle cx="100" cy="100" r="10" fill="#b40426"/>`,
+		`<circle cx="300" cy="200" r="10" fill="#3b4cc0"/>`,
+		`>Charge (C)</text>`,
+		`>-1e-06</text>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("snapshot is missing %s", want)
+		}
+	}
+	if strings.Contains(doc, "positive (1)") {
+		t.Error("colormap snapshot lists particle colours")
+	}
+}
@@ -0,0 +1,149 @@
+// Package svg draws the simulation as SVG documents, which scale cleanly for figures.
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"particle-physics-simulator/internal/renderer"
+	"strconv"
+	"strings"
+)
+
+// Renderer is the SVG backend: each frame is a document handed to OnFrame when it ends.
+// It has no input and never asks to close on its own.
+type Renderer struct {
+	OnFrame func(doc []byte) error // Called with the finished document, or nil
+
+	width, height int
+	buf           bytes.Buffer
+	last          []byte
+	frames        int
+	err           error
+}
+
+// NewRenderer creates an SVG backend for a width x height area.
+func NewRenderer(width, height int) *Renderer {
+	return &Renderer{width: width, height: height}
+}
+
+// Bytes returns the document of the last completed frame.
+func (r *Renderer) Bytes() []byte {
+	return r.last
+}
+
+// Frames returns the number of completed frames.
+func (r *Renderer) Frames() int {
+	return r.frames
+}
+
+// Err returns the first error returned by OnFrame.
+func (r *Renderer) Err() error {
+	return r.err
+}
+
+func (r *Renderer) BeginFrame(background color.RGBA) {
+	r.buf.Reset()
+	r.buf.WriteString(xml.Header)
+	fmt.Fprintf(&r.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		r.width, r.height, r.width, r.height)
+	fmt.Fprintf(&r.buf, `<rect width="100%%" height="100%%"%s/>`+"\n", fill(background))
+}
+
+func (r *Renderer) EndFrame() {
+	r.buf.WriteString("</svg>\n")
+	r.last = append(r.last[:0], r.buf.Bytes()...)
+	r.frames++
+	if r.OnFrame != nil && r.err == nil {
+		r.err = r.OnFrame(r.last)
+	}
+}
+
+func (r *Renderer) Size() (int, int) {
+	return r.width, r.height
+}
+
+func (r *Renderer) Circle(x, y, radius float64, c color.RGBA) {
+	fmt.Fprintf(&r.buf, `<circle cx="%s" cy="%s" r="%s"%s/>`+"\n", num(x), num(y), num(radius), fill(c))
+}
+
+func (r *Renderer) Line(x0, y0, x1, y1, width float64, c color.RGBA) {
+	fmt.Fprintf(&r.buf, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke-width="%s" stroke-linecap="round"%s/>`+"\n",
+		num(x0), num(y0), num(x1), num(y1), num(width), stroke(c))
+}
+
+func (r *Renderer) Polygon(points []renderer.Point, c color.RGBA) {
+	r.buf.WriteString(`<polygon points="`)
+	for i, p := range points {
+		if i > 0 {
+			r.buf.WriteByte(' ')
+		}
+		r.buf.WriteString(num(p.X) + "," + num(p.Y))
+	}
+	fmt.Fprintf(&r.buf, `"%s/>`+"\n", fill(c))
+}
+
+func (r *Renderer) Rect(x, y, width, height float64, c color.RGBA) {
+	fmt.Fprintf(&r.buf, `<rect x="%s" y="%s" width="%s" height="%s"%s/>`+"\n", num(x), num(y), num(width), num(height), fill(c))
+}
+
+// Text is set in the viewer's monospace font with its baseline placed so the text fills
+// the size below y, as it does in the window.
+func (r *Renderer) Text(s string, x, y, size float64, c color.RGBA) {
+	fmt.Fprintf(&r.buf, `<text x="%s" y="%s" font-family="monospace" font-size="%s"%s>`,
+		num(x), num(y+0.8*size), num(size), fill(c))
+	xml.EscapeText(&r.buf, []byte(s))
+	r.buf.WriteString("</text>\n")
+}
+
+// TextWidth assumes a monospace font with characters 0.6 times as wide as they are tall.
+func (r *Renderer) TextWidth(s string, size float64) float64 {
+	return 0.6 * size * float64(len([]rune(s)))
+}
+
+func (r *Renderer) Input() renderer.Input {
+	return renderer.Input{}
+}
+
+func (r *Renderer) FPS() int {
+	return 0
+}
+
+// ShouldClose reports true once OnFrame has failed.
+func (r *Renderer) ShouldClose() bool {
+	return r.err != nil
+}
+
+// Close returns the first error returned by OnFrame.
+func (r *Renderer) Close() error {
+	return r.err
+}
+
+// num formats a coordinate to two decimal places without trailing zeros.
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// fill returns the fill attributes for c, with the opacity only when it isn't opaque.
+func fill(c color.RGBA) string {
+	return paint("fill", c)
+}
+
+// stroke returns the stroke attributes for c.
+func stroke(c color.RGBA) string {
+	return paint("stroke", c)
+}
+
+func paint(attr string, c color.RGBA) string {
+	s := fmt.Sprintf(` %s="#%02x%02x%02x"`, attr, c.R, c.G, c.B)
+	if c.A != 255 {
+		s += fmt.Sprintf(` %s-opacity="%s"`, attr, num(float64(c.A)/255))
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package svg
+
+import (
+	"encoding/xml"
+	"image/color"
+	"io"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/renderer"
+	"strings"
+	"testing"
+)
+
+func TestNum(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0, "0"},
+		{100, "100"},
+		{1.5, "1.5"},
+		{2.345678, "2.35"},
+		{-0.001, "0"},
+		{-12.1, "-12.1"},
+	}
+	for _, tt := range tests {
+		if got := num(tt.in); got != tt.want {
+			t.Errorf("num(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderer(t *testing.T) {
+	r := NewRenderer(200, 100)
+	var docs []string
+	r.OnFrame = func(doc []byte) error {
+		docs = append(docs, string(doc))
+		return nil
+	}
+
+	r.BeginFrame(color.RGBA{A: 255})
+	renderer.DrawScene(r, renderer.Scene{
+		Particles: []*particle.Particle{{X: 50, Y: 40, Radius: 5, Color: particle.Color{R: 1, A: 1}}},
+		Width:     200,
+		Height:    100,
+		Walls:     true,
+	})
+	r.Polygon([]renderer.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 5, Y: 8}}, color.RGBA{G: 255, A: 128})
+	r.Text("a < b & c", 10, 10, 20, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	r.EndFrame()
+
+	if len(docs) != 1 {
+		t.Fatalf("OnFrame called %d times, want 1", len(docs))
+	}
+	doc := docs[0]
+	for _, want := range []string{
+		`width="200" height="100" viewBox="0 0 200 100"`,
+		`<circle cx="50" cy="40" r="5" fill="#ff0000"/>`,
+		`<polygon points="0,0 10,0 5,8" fill="#00ff00" fill-opacity="0.5"/>`,
+		`a &lt; b &amp; c</text>`,
+		`stroke-linecap="round"`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document is missing %s:\n%s", want, doc)
+		}
+	}
+
+	// The document is well formed
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Errorf("parsing the document: %v", err)
+			}
+			break
+		}
+	}
+	if string(r.Bytes()) != doc {
+		t.Error("Bytes() differs from the document passed to OnFrame")
+	}
+}
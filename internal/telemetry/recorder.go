@@ -0,0 +1,301 @@
+package telemetry
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"particle-physics-simulator/internal/world"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Format selects the output encoding of a Recorder.
+type Format int
+
+const (
+	CSV       Format = iota // Comma-separated values with a commented header
+	JSONLines               // One JSON object per line, the first describing the run
+)
+
+// ParseFormat parses "csv" or "jsonl".
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "csv":
+		return CSV, nil
+	case "jsonl", "json":
+		return JSONLines, nil
+	}
+	return 0, fmt.Errorf("telemetry: unknown format %q", s)
+}
+
+// Metadata describes a run in the header of the output.
+type Metadata struct {
+	Name     string
+	Started  time.Time
+	TimeStep float64 // Seconds per step
+	Width    int     // Size of the walled area in pixels
+	Height   int
+	Extra    map[string]string
+}
+
+// Options configures a Recorder.
+type Options struct {
+	Format   Format
+	Every    int     // Sample every this many steps; values below 1 sample every step
+	Probes   []Probe // Defaults to DefaultProbes()
+	Metadata Metadata
+
+	// Buffer is the number of samples that can wait to be written before Observe blocks.
+	// Defaults to 1024.
+	Buffer int
+}
+
+type sample struct {
+	step   int
+	time   float64
+	values []float64
+}
+
+// Recorder samples probes from a world and streams them to a writer. Probes are evaluated
+// on the calling goroutine, so they see a consistent world, while encoding and writing
+// happen on a background goroutine so slow disks don't stall the step loop. Observe, Record
+// and Close must all be called from the goroutine stepping the world.
+type Recorder struct {
+	opts    Options
+	samples chan sample
+	done    chan error
+	closed  bool
+}
+
+// NewRecorder starts a recorder writing to out. The caller remains responsible for closing out
+// after the recorder is closed.
+func NewRecorder(out io.Writer, opts Options) (*Recorder, error) {
+	if opts.Every < 1 {
+		opts.Every = 1
+	}
+	if opts.Probes == nil {
+		opts.Probes = DefaultProbes()
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = 1024
+	}
+
+	seen := map[string]bool{"step": true, "time": true}
+	for _, p := range opts.Probes {
+		if p.Name == "" || p.Sample == nil {
+			return nil, errors.New("telemetry: probe needs a name and a sample function")
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("telemetry: duplicate column %q", p.Name)
+		}
+		seen[p.Name] = true
+	}
+
+	r := &Recorder{
+		opts:    opts,
+		samples: make(chan sample, opts.Buffer),
+		done:    make(chan error, 1),
+	}
+	go r.write(out)
+	return r, nil
+}
+
+// Observe samples the world if its step count is a multiple of the sampling interval.
+// Call it after every step.
+func (r *Recorder) Observe(w *world.World) {
+	if w.Steps%r.opts.Every == 0 {
+		r.Record(w)
+	}
+}
+
+// Record samples the world unconditionally, e.g. to capture the initial state.
+func (r *Recorder) Record(w *world.World) {
+	if r.closed {
+		return
+	}
+	values := make([]float64, len(r.opts.Probes))
+	for i, p := range r.opts.Probes {
+		values[i] = p.Sample(w)
+	}
+	r.samples <- sample{step: w.Steps, time: w.Time, values: values}
+}
+
+// Close writes any pending samples and returns the first write error.
+func (r *Recorder) Close() error {
+	if r.closed {
+		return errors.New("telemetry: recorder already closed")
+	}
+	r.closed = true
+	close(r.samples)
+	return <-r.done
+}
+
+func (r *Recorder) write(out io.Writer) {
+	bw := bufio.NewWriter(out)
+	var enc encoder
+	if r.opts.Format == JSONLines {
+		enc = &jsonEncoder{w: bw, probes: r.opts.Probes}
+	} else {
+		enc = &csvEncoder{w: bw, csv: csv.NewWriter(bw), probes: r.opts.Probes}
+	}
+
+	err := enc.header(r.opts.Metadata)
+	for s := range r.samples {
+		// After an error keep draining so Record never blocks
+		if err == nil {
+			err = enc.sample(s)
+		}
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	r.done <- err
+}
+
+type encoder interface {
+	header(meta Metadata) error
+	sample(s sample) error
+}
+
+type csvEncoder struct {
+	w      *bufio.Writer
+	csv    *csv.Writer
+	probes []Probe
+}
+
+func (e *csvEncoder) header(meta Metadata) error {
+	fmt.Fprintln(e.w, "# particle-physics-simulator telemetry")
+	if meta.Name != "" {
+		fmt.Fprintf(e.w, "# run: %s\n", meta.Name)
+	}
+	if !meta.Started.IsZero() {
+		fmt.Fprintf(e.w, "# started: %s\n", meta.Started.Format(time.RFC3339))
+	}
+	if meta.TimeStep > 0 {
+		fmt.Fprintf(e.w, "# time_step: %s s\n", formatFloat(meta.TimeStep))
+	}
+	if meta.Width > 0 && meta.Height > 0 {
+		fmt.Fprintf(e.w, "# area: %dx%d px\n", meta.Width, meta.Height)
+	}
+	for _, key := range sortedKeys(meta.Extra) {
+		fmt.Fprintf(e.w, "# %s: %s\n", key, meta.Extra[key])
+	}
+
+	fmt.Fprint(e.w, "# units: step=1, time=s")
+	for _, p := range e.probes {
+		fmt.Fprintf(e.w, ", %s=%s", p.Name, p.Unit)
+	}
+	fmt.Fprintln(e.w)
+
+	row := []string{"step", "time"}
+	for _, p := range e.probes {
+		row = append(row, p.Name)
+	}
+	return e.writeRow(row)
+}
+
+func (e *csvEncoder) sample(s sample) error {
+	row := make([]string, 0, len(s.values)+2)
+	row = append(row, strconv.Itoa(s.step), formatFloat(s.time))
+	for _, v := range s.values {
+		row = append(row, formatFloat(v))
+	}
+	return e.writeRow(row)
+}
+
+func (e *csvEncoder) writeRow(row []string) error {
+	if err := e.csv.Write(row); err != nil {
+		return err
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+type jsonEncoder struct {
+	w      *bufio.Writer
+	probes []Probe
+}
+
+type jsonColumn struct {
+	Name string `json:"name"`
+	Unit string `json:"unit"`
+}
+
+type jsonHeader struct {
+	Type     string            `json:"type"`
+	Run      string            `json:"run,omitempty"`
+	Started  string            `json:"started,omitempty"`
+	TimeStep float64           `json:"time_step,omitempty"`
+	Width    int               `json:"width,omitempty"`
+	Height   int               `json:"height,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Columns  []jsonColumn      `json:"columns"`
+}
+
+func (e *jsonEncoder) header(meta Metadata) error {
+	h := jsonHeader{
+		Type:     "header",
+		Run:      meta.Name,
+		TimeStep: meta.TimeStep,
+		Width:    meta.Width,
+		Height:   meta.Height,
+		Metadata: meta.Extra,
+		Columns:  []jsonColumn{{"step", "1"}, {"time", "s"}},
+	}
+	if !meta.Started.IsZero() {
+		h.Started = meta.Started.Format(time.RFC3339)
+	}
+	for _, p := range e.probes {
+		h.Columns = append(h.Columns, jsonColumn{p.Name, p.Unit})
+	}
+
+	line, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	e.w.Write(line)
+	return e.w.WriteByte('\n')
+}
+
+// sample writes the line by hand to keep the column order and to write NaN and infinities,
+// which encoding/json rejects, as null.
+func (e *jsonEncoder) sample(s sample) error {
+	e.w.WriteString(`{"step":`)
+	e.w.WriteString(strconv.Itoa(s.step))
+	e.w.WriteString(`,"time":`)
+	e.w.WriteString(jsonNumber(s.time))
+	for i, p := range e.probes {
+		name, _ := json.Marshal(p.Name)
+		e.w.WriteByte(',')
+		e.w.Write(name)
+		e.w.WriteByte(':')
+		e.w.WriteString(jsonNumber(s.values[i]))
+	}
+	_, err := e.w.WriteString("}\n")
+	return err
+}
+
+func jsonNumber(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "null"
+	}
+	return formatFloat(v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,256 @@
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"math"
+	"particle-physics-simulator/internal/analytics"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/world"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testWorld() *world.World {
+	particles := []*particle.Particle{
+		particle.NewParticle(100, 100, 30, 0, 0, 0, 2, 5, particle.Color{}, true),
+		particle.NewParticle(200, 100, -30, 0, 0, 0, 2, 5, particle.Color{}, true),
+	}
+	return world.New(particles, 400, 300)
+}
+
+func TestRecorderCSV(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf, Options{
+		Format: CSV,
+		Every:  5,
+		Probes: []Probe{ParticleCount, KineticEnergy},
+		Metadata: Metadata{
+			Name:     "test",
+			Started:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			TimeStep: 0.01,
+			Width:    400,
+			Height:   300,
+			Extra:    map[string]string{"seed": "42"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := testWorld()
+	rec.Record(w)
+	for i := 0; i < 20; i++ {
+		w.Step(0.01)
+		rec.Observe(w)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var comments, data []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "#") {
+			comments = append(comments, scanner.Text())
+		} else {
+			data = append(data, scanner.Text())
+		}
+	}
+
+	header := strings.Join(comments, "\n")
+	for _, want := range []string{
+		"# run: test",
+		"# started: 2024-01-02T03:04:05Z",
+		"# time_step: 0.01 s",
+		"# area: 400x300 px",
+		"# seed: 42",
+		"# units: step=1, time=s, particles=1, kinetic_energy=" + EnergyUnit,
+	} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header is missing %q:\n%s", want, header)
+		}
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(strings.Join(data, "\n"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(rows[0], ","); got != "step,time,particles,kinetic_energy" {
+		t.Errorf("column row = %q", got)
+	}
+	// The initial state plus steps 5, 10, 15 and 20
+	if len(rows) != 6 {
+		t.Fatalf("got %d rows, want 6", len(rows))
+	}
+	wantSteps := []string{"0", "5", "10", "15", "20"}
+	for i, row := range rows[1:] {
+		if row[0] != wantSteps[i] {
+			t.Errorf("row %d step = %s, want %s", i, row[0], wantSteps[i])
+		}
+		if row[2] != "2" {
+			t.Errorf("row %d particles = %s, want 2", i, row[2])
+		}
+	}
+	if rows[1][3] != "1800" {
+		t.Errorf("initial kinetic energy = %s, want 1800", rows[1][3])
+	}
+}
+
+func TestRecorderJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	custom := Probe{Name: "first_x", Unit: "px", Sample: func(w *world.World) float64 {
+		return w.Particles[0].X
+	}}
+	rec, err := NewRecorder(&buf, Options{
+		Format:   JSONLines,
+		Probes:   []Probe{custom, EnergyDrift},
+		Metadata: Metadata{Name: "jsonl", TimeStep: 0.01},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := testWorld()
+	for i := 0; i < 3; i++ {
+		w.Step(0.01)
+		rec.Observe(w)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4", len(lines))
+	}
+
+	var header jsonHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Type != "header" || header.Run != "jsonl" || len(header.Columns) != 4 {
+		t.Errorf("unexpected header %+v", header)
+	}
+	if header.Columns[2] != (jsonColumn{"first_x", "px"}) {
+		t.Errorf("column 2 = %+v", header.Columns[2])
+	}
+
+	for i, line := range lines[1:] {
+		var row map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			t.Fatalf("line %d: %v", i+1, err)
+		}
+		if row["step"] != float64(i+1) {
+			t.Errorf("line %d step = %v", i+1, row["step"])
+		}
+		// The world has no ledger, so the drift is NaN and written as null
+		if v, ok := row["energy_drift"]; !ok || v != nil {
+			t.Errorf("line %d energy_drift = %v, want null", i+1, v)
+		}
+	}
+	if !strings.HasPrefix(lines[1], `{"step":1,"time":0.01,"first_x":`) {
+		t.Errorf("columns out of order: %s", lines[1])
+	}
+}
+
+func TestNewRecorderRejectsBadProbes(t *testing.T) {
+	tests := []struct {
+		name   string
+		probes []Probe
+	}{
+		{"duplicate", []Probe{KineticEnergy, KineticEnergy}},
+		{"reserved", []Probe{{Name: "time", Sample: KineticEnergy.Sample}}},
+		{"no sample function", []Probe{{Name: "x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRecorder(&bytes.Buffer{}, Options{Probes: tt.probes}); err == nil {
+				t.Error("NewRecorder() succeeded, want an error")
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRecorderReportsWriteErrors(t *testing.T) {
+	rec, err := NewRecorder(failingWriter{}, Options{Buffer: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// More samples than the buffer holds must not block after the writer fails
+	w := testWorld()
+	for i := 0; i < 10000; i++ {
+		rec.Record(w)
+	}
+	if err := rec.Close(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Close() = %v, want disk full", err)
+	}
+}
+
+func TestLookupProbes(t *testing.T) {
+	probes, err := LookupProbes([]string{"temperature", "particles"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(probes) != 2 || probes[0].Name != "temperature" || probes[1].Name != "particles" {
+		t.Errorf("LookupProbes() = %v", probes)
+	}
+	if _, err := LookupProbes([]string{"entropy"}); err == nil {
+		t.Error("LookupProbes() found an unknown probe")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("jsonl"); err != nil || f != JSONLines {
+		t.Errorf("ParseFormat(jsonl) = %v, %v", f, err)
+	}
+	if f, err := ParseFormat("csv"); err != nil || f != CSV {
+		t.Errorf("ParseFormat(csv) = %v, %v", f, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) succeeded")
+	}
+}
+
+func TestProbesOnEmptyWorld(t *testing.T) {
+	w := world.New(nil, 100, 100)
+	for _, p := range DefaultProbes() {
+		v := p.Sample(w)
+		if p.Name == "energy_drift" {
+			continue
+		}
+		if v != 0 || math.IsNaN(v) {
+			t.Errorf("%s on an empty world = %v, want 0", p.Name, v)
+		}
+	}
+}
+
+func TestEnergyProbesReuseLedger(t *testing.T) {
+	w := testWorld()
+	if got, want := TotalEnergy.Sample(w), w.Energy().Total(); got != want {
+		t.Errorf("total energy without a ledger = %v, want %v", got, want)
+	}
+
+	w.Ledger = analytics.NewLedger(1)
+	w.Step(0.01)
+	entry, _ := w.Ledger.Last()
+	// Changing the world between the step and the sample shows which energy was read
+	w.Particles[0].Vx = 1000
+	if got := TotalEnergy.Sample(w); got != entry.Energy.Total() {
+		t.Errorf("total energy = %v, want %v from the ledger", got, entry.Energy.Total())
+	}
+	if got := PotentialEnergy.Sample(w); got != entry.Energy.Potential() {
+		t.Errorf("potential energy = %v, want %v from the ledger", got, entry.Energy.Potential())
+	}
+}
@@ -0,0 +1,642 @@
+package trajectory
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"particle-physics-simulator/internal/particle"
+	"sort"
+)
+
+// The binary trajectory format stores frames in independently compressed chunks:
+//
+//	header  "PPSTRAJ1", position resolution, velocity resolution (float64)
+//	chunks  flate-compressed frames; the first frame of a chunk is a keyframe, the
+//	        others hold only the changes from the frame before
+//	index   for every chunk its offset, length and frame count, then the step and
+//	        time of every frame
+//	footer  index offset (uint64), "PPSTIDX1"
+//
+// Positions and velocities are quantised to fixed resolutions, so a frame decodes to
+// the same values whichever chunk boundary it follows. Keyframes carry the ID, mass,
+// radius, charge, color and movability of every particle; delta frames are only used
+// while those stay the same. All fixed-size numbers are little-endian, the rest varints.
+//
+// Finding a frame takes one lookup in the index and decoding at most one chunk, so
+// seeking costs the same anywhere in the file.
+
+var (
+	binaryMagic = []byte("PPSTRAJ1")
+	indexMagic  = []byte("PPSTIDX1")
+)
+
+const (
+	binaryHeaderSize = 24
+	binaryFooterSize = 16
+
+	frameKey   = 0
+	frameDelta = 1
+
+	channels = 6 // x, y, z, vx, vy, vz
+)
+
+// BinaryOptions configures a BinaryWriter.
+type BinaryOptions struct {
+	ChunkFrames        int     // Frames per chunk; defaults to 64
+	PositionResolution float64 // Pixels per quantisation step; defaults to 1e-3
+	VelocityResolution float64 // Pixels per second per quantisation step; defaults to 1e-3
+	CompressionLevel   int     // A compress/flate level; 0 means flate.DefaultCompression
+}
+
+func (o *BinaryOptions) setDefaults() {
+	if o.ChunkFrames <= 0 {
+		o.ChunkFrames = 64
+	}
+	if o.PositionResolution <= 0 {
+		o.PositionResolution = 1e-3
+	}
+	if o.VelocityResolution <= 0 {
+		o.VelocityResolution = 1e-3
+	}
+	if o.CompressionLevel == 0 {
+		o.CompressionLevel = flate.DefaultCompression
+	}
+}
+
+// chunkEntry locates a chunk in the file.
+type chunkEntry struct {
+	offset, length int64
+	frames         int
+}
+
+// frameEntry is the index entry of a frame.
+type frameEntry struct {
+	step int
+	time float64
+}
+
+// quantFrame is a frame as stored: the particle properties and the quantised motion.
+type quantFrame struct {
+	props  []particleProps
+	values [channels][]int64
+}
+
+type particleProps struct {
+	id                   int
+	mass, radius, charge float64
+	color                particle.Color
+	movable              bool
+}
+
+func propsOf(p *particle.Particle) particleProps {
+	return particleProps{p.ID, p.Mass, p.Radius, p.Charge, p.Color, p.Movable}
+}
+
+func (q *quantFrame) sameProps(props []particleProps) bool {
+	if q == nil || len(q.props) != len(props) {
+		return false
+	}
+	for i := range props {
+		if q.props[i] != props[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// BinaryWriter writes frames in the compact binary trajectory format. Close must be called
+// to write the index; files without one can't be read.
+type BinaryWriter struct {
+	w      *bufio.Writer
+	opts   BinaryOptions
+	offset int64
+
+	chunk       bytes.Buffer // Uncompressed frames of the current chunk
+	chunkFrames int
+	prev        *quantFrame
+
+	chunks []chunkEntry
+	frames []frameEntry
+	closed bool
+}
+
+// NewBinaryWriter creates a binary trajectory writer and writes the file header.
+func NewBinaryWriter(w io.Writer, opts BinaryOptions) (*BinaryWriter, error) {
+	opts.setDefaults()
+	if opts.CompressionLevel < flate.HuffmanOnly || opts.CompressionLevel > flate.BestCompression {
+		return nil, fmt.Errorf("trajectory: invalid compression level %d", opts.CompressionLevel)
+	}
+
+	bw := &BinaryWriter{w: bufio.NewWriter(w), opts: opts}
+	header := append([]byte{}, binaryMagic...)
+	header = binary.LittleEndian.AppendUint64(header, math.Float64bits(opts.PositionResolution))
+	header = binary.LittleEndian.AppendUint64(header, math.Float64bits(opts.VelocityResolution))
+	if _, err := bw.w.Write(header); err != nil {
+		return nil, err
+	}
+	bw.offset = binaryHeaderSize
+	return bw, nil
+}
+
+// WriteFrame adds a frame, compressing and writing the chunk once it is full.
+func (bw *BinaryWriter) WriteFrame(f Frame) error {
+	if bw.closed {
+		return errors.New("trajectory: write to closed binary writer")
+	}
+
+	q := &quantFrame{props: make([]particleProps, len(f.Particles))}
+	for c := range q.values {
+		q.values[c] = make([]int64, len(f.Particles))
+	}
+	pos, vel := bw.opts.PositionResolution, bw.opts.VelocityResolution
+	for i, p := range f.Particles {
+		q.props[i] = propsOf(p)
+		q.values[0][i] = quantise(p.X, pos)
+		q.values[1][i] = quantise(p.Y, pos)
+		q.values[2][i] = quantise(p.Z, pos)
+		q.values[3][i] = quantise(p.Vx, vel)
+		q.values[4][i] = quantise(p.Vy, vel)
+		q.values[5][i] = quantise(p.Vz, vel)
+	}
+
+	buf := bw.chunk.AvailableBuffer()
+	if bw.chunkFrames > 0 && bw.prev.sameProps(q.props) {
+		buf = append(buf, frameDelta)
+		buf = appendFrameHeader(buf, f)
+		for c := range q.values {
+			for i, v := range q.values[c] {
+				buf = binary.AppendVarint(buf, v-bw.prev.values[c][i])
+			}
+		}
+	} else {
+		buf = append(buf, frameKey)
+		buf = appendFrameHeader(buf, f)
+		for _, p := range q.props {
+			buf = binary.AppendUvarint(buf, uint64(p.id))
+			buf = appendFloat64(buf, p.mass)
+			buf = appendFloat64(buf, p.radius)
+			buf = appendFloat64(buf, p.charge)
+			for _, v := range [4]float32{p.color.R, p.color.G, p.color.B, p.color.A} {
+				buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(v))
+			}
+			if p.movable {
+				buf = append(buf, 1)
+			} else {
+				buf = append(buf, 0)
+			}
+		}
+		for c := range q.values {
+			for _, v := range q.values[c] {
+				buf = binary.AppendVarint(buf, v)
+			}
+		}
+	}
+	bw.chunk.Write(buf)
+
+	bw.prev = q
+	bw.chunkFrames++
+	bw.frames = append(bw.frames, frameEntry{f.Step, f.Time})
+	if bw.chunkFrames >= bw.opts.ChunkFrames {
+		return bw.endChunk()
+	}
+	return nil
+}
+
+// appendFrameHeader appends the fields shared by key and delta frames.
+func appendFrameHeader(buf []byte, f Frame) []byte {
+	buf = binary.AppendVarint(buf, int64(f.Step))
+	buf = appendFloat64(buf, f.Time)
+	buf = appendFloat64(buf, f.Width)
+	buf = appendFloat64(buf, f.Height)
+	return binary.AppendUvarint(buf, uint64(len(f.Particles)))
+}
+
+// endChunk compresses and writes the current chunk.
+func (bw *BinaryWriter) endChunk() error {
+	if bw.chunkFrames == 0 {
+		return nil
+	}
+
+	var compressed bytes.Buffer
+	zw, err := flate.NewWriter(&compressed, bw.opts.CompressionLevel)
+	if err != nil {
+		return err
+	}
+	if _, err := zw.Write(bw.chunk.Bytes()); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if _, err := bw.w.Write(compressed.Bytes()); err != nil {
+		return err
+	}
+
+	bw.chunks = append(bw.chunks, chunkEntry{bw.offset, int64(compressed.Len()), bw.chunkFrames})
+	bw.offset += int64(compressed.Len())
+	bw.chunk.Reset()
+	bw.chunkFrames = 0
+	bw.prev = nil
+	return nil
+}
+
+// Flush ends the current chunk early and writes buffered data to the underlying writer.
+func (bw *BinaryWriter) Flush() error {
+	if err := bw.endChunk(); err != nil {
+		return err
+	}
+	return bw.w.Flush()
+}
+
+// Close writes the last chunk and the index. It does not close the underlying writer.
+func (bw *BinaryWriter) Close() error {
+	if bw.closed {
+		return nil
+	}
+	if err := bw.endChunk(); err != nil {
+		return err
+	}
+	bw.closed = true
+
+	index := binary.AppendUvarint(nil, uint64(len(bw.chunks)))
+	for _, c := range bw.chunks {
+		index = binary.AppendUvarint(index, uint64(c.offset))
+		index = binary.AppendUvarint(index, uint64(c.length))
+		index = binary.AppendUvarint(index, uint64(c.frames))
+	}
+	index = binary.AppendUvarint(index, uint64(len(bw.frames)))
+	for _, f := range bw.frames {
+		index = binary.AppendVarint(index, int64(f.step))
+		index = appendFloat64(index, f.time)
+	}
+	index = binary.LittleEndian.AppendUint64(index, uint64(bw.offset))
+	index = append(index, indexMagic...)
+
+	if _, err := bw.w.Write(index); err != nil {
+		return err
+	}
+	return bw.w.Flush()
+}
+
+// BinaryReader gives random access to the frames of a binary trajectory.
+type BinaryReader struct {
+	r        io.ReaderAt
+	closer   io.Closer
+	pos, vel float64
+
+	chunks     []chunkEntry
+	frames     []frameEntry
+	frameChunk []int32 // Chunk of every frame
+	chunkStart []int   // Number of the first frame of every chunk
+
+	// The most recently decoded chunk, since frames are mostly read in order. Its frames are
+	// kept quantised, and particles are only made for the frame asked for.
+	cachedChunk  int
+	cachedFrames []chunkFrame
+}
+
+// chunkFrame is a decoded frame of a chunk, without its particles.
+type chunkFrame struct {
+	frame Frame
+	q     *quantFrame
+}
+
+// OpenBinary opens a binary trajectory file.
+func OpenBinary(path string) (*BinaryReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r, err := NewBinaryReader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
+
+// NewBinaryReader reads the header and index of a binary trajectory of the given size.
+func NewBinaryReader(r io.ReaderAt, size int64) (*BinaryReader, error) {
+	if size < binaryHeaderSize+binaryFooterSize {
+		return nil, errors.New("trajectory: binary file too short")
+	}
+	header := make([]byte, binaryHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:8], binaryMagic) {
+		return nil, errors.New("trajectory: not a binary trajectory")
+	}
+
+	footer := make([]byte, binaryFooterSize)
+	if _, err := r.ReadAt(footer, size-binaryFooterSize); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(footer[8:], indexMagic) {
+		return nil, errors.New("trajectory: binary trajectory has no index; was the writer closed?")
+	}
+	indexOffset := int64(binary.LittleEndian.Uint64(footer))
+	if indexOffset < binaryHeaderSize || indexOffset > size-binaryFooterSize {
+		return nil, errors.New("trajectory: corrupt binary trajectory index offset")
+	}
+	index := make([]byte, size-binaryFooterSize-indexOffset)
+	if _, err := r.ReadAt(index, indexOffset); err != nil {
+		return nil, err
+	}
+
+	br := &BinaryReader{
+		r:           r,
+		pos:         readFloat64(header[8:]),
+		vel:         readFloat64(header[16:]),
+		cachedChunk: -1,
+	}
+	d := decoder{buf: index}
+	nChunks := d.count(len(index))
+	for i := 0; i < nChunks && d.err == nil; i++ {
+		c := chunkEntry{offset: int64(d.uvarint()), length: int64(d.uvarint()), frames: d.count(len(index))}
+		if c.offset < binaryHeaderSize || c.offset+c.length > indexOffset {
+			return nil, errors.New("trajectory: corrupt binary trajectory index")
+		}
+		br.chunkStart = append(br.chunkStart, len(br.frameChunk))
+		for f := 0; f < c.frames; f++ {
+			br.frameChunk = append(br.frameChunk, int32(i))
+		}
+		br.chunks = append(br.chunks, c)
+	}
+	nFrames := d.count(len(index))
+	for i := 0; i < nFrames && d.err == nil; i++ {
+		br.frames = append(br.frames, frameEntry{int(d.varint()), d.float64()})
+	}
+	if d.err != nil || len(br.frames) != len(br.frameChunk) {
+		return nil, errors.New("trajectory: corrupt binary trajectory index")
+	}
+	return br, nil
+}
+
+// Close closes the file opened by OpenBinary.
+func (br *BinaryReader) Close() error {
+	if br.closer != nil {
+		return br.closer.Close()
+	}
+	return nil
+}
+
+// NumFrames returns the number of frames.
+func (br *BinaryReader) NumFrames() int {
+	return len(br.frames)
+}
+
+// FrameInfo returns the step and time of frame i without decoding it.
+func (br *BinaryReader) FrameInfo(i int) (step int, time float64) {
+	return br.frames[i].step, br.frames[i].time
+}
+
+// FindStep returns the number of the last frame at or before the given step, or -1 if
+// every frame is later. Frames are assumed to be in step order.
+func (br *BinaryReader) FindStep(step int) int {
+	return sort.Search(len(br.frames), func(i int) bool { return br.frames[i].step > step }) - 1
+}
+
+// Frame decodes frame i. The returned particles are fresh copies the caller may modify.
+func (br *BinaryReader) Frame(i int) (Frame, error) {
+	if i < 0 || i >= len(br.frames) {
+		return Frame{}, fmt.Errorf("trajectory: frame %d out of range [0, %d)", i, len(br.frames))
+	}
+	c := int(br.frameChunk[i])
+	if c != br.cachedChunk {
+		frames, err := br.decodeChunk(c)
+		if err != nil {
+			return Frame{}, err
+		}
+		br.cachedChunk, br.cachedFrames = c, frames
+	}
+
+	cf := br.cachedFrames[i-br.chunkStart[c]]
+	f := cf.frame
+	f.Particles = br.particles(cf.q)
+	return f, nil
+}
+
+// particles makes the particles of a quantised frame, with the IDs given in decodeChunk.
+func (br *BinaryReader) particles(q *quantFrame) []*particle.Particle {
+	particles := make([]*particle.Particle, len(q.props))
+	for i, p := range q.props {
+		particles[i] = &particle.Particle{
+			ID:      p.id,
+			X:       float64(q.values[0][i]) * br.pos,
+			Y:       float64(q.values[1][i]) * br.pos,
+			Z:       float64(q.values[2][i]) * br.pos,
+			Vx:      float64(q.values[3][i]) * br.vel,
+			Vy:      float64(q.values[4][i]) * br.vel,
+			Vz:      float64(q.values[5][i]) * br.vel,
+			Mass:    p.mass,
+			Radius:  p.radius,
+			Charge:  p.charge,
+			Color:   p.color,
+			Movable: p.movable,
+		}
+	}
+	return particles
+}
+
+// ReadAll decodes every frame.
+func (br *BinaryReader) ReadAll() ([]Frame, error) {
+	frames := make([]Frame, 0, len(br.frames))
+	for i := range br.frames {
+		f, err := br.Frame(i)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// decodeChunk decodes the frames of chunk c as far as their quantised values.
+func (br *BinaryReader) decodeChunk(c int) ([]chunkFrame, error) {
+	entry := br.chunks[c]
+	compressed := make([]byte, entry.length)
+	if _, err := br.r.ReadAt(compressed, entry.offset); err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		return nil, fmt.Errorf("trajectory: chunk %d: %w", c, err)
+	}
+
+	d := decoder{buf: raw}
+	frames := make([]chunkFrame, 0, entry.frames)
+	var prev *quantFrame
+	for len(frames) < entry.frames {
+		kind := d.byte()
+		f := Frame{
+			Step:   int(d.varint()),
+			Time:   d.float64(),
+			Width:  d.float64(),
+			Height: d.float64(),
+		}
+		n := d.count(len(raw))
+		if d.err != nil {
+			break
+		}
+
+		q := &quantFrame{}
+		switch kind {
+		case frameKey:
+			q.props = make([]particleProps, n)
+			for i := range q.props {
+				p := &q.props[i]
+				p.id = int(d.uvarint())
+				p.mass, p.radius, p.charge = d.float64(), d.float64(), d.float64()
+				p.color = particle.Color{R: d.float32(), G: d.float32(), B: d.float32(), A: d.float32()}
+				p.movable = d.byte() != 0
+				particle.ReserveID(p.id)
+			}
+			// Particles without an ID keep the one given here for the rest of the chunk
+			for i := range q.props {
+				if q.props[i].id == 0 {
+					q.props[i].id = particle.NextID()
+				}
+			}
+			for c := range q.values {
+				q.values[c] = make([]int64, n)
+				for i := range q.values[c] {
+					q.values[c][i] = d.varint()
+				}
+			}
+		case frameDelta:
+			if prev == nil || len(prev.props) != n {
+				d.err = errors.New("delta frame without a matching previous frame")
+				break
+			}
+			q.props = prev.props
+			for c := range q.values {
+				q.values[c] = make([]int64, n)
+				for i := range q.values[c] {
+					q.values[c][i] = prev.values[c][i] + d.varint()
+				}
+			}
+		default:
+			d.err = fmt.Errorf("unknown frame kind %d", kind)
+		}
+		if d.err != nil {
+			break
+		}
+		frames = append(frames, chunkFrame{f, q})
+		prev = q
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("trajectory: chunk %d: %v", c, d.err)
+	}
+	return frames, nil
+}
+
+// quantise rounds v to a multiple of resolution, saturating far outside the int64 range.
+func quantise(v, resolution float64) int64 {
+	q := math.Round(v / resolution)
+	switch {
+	case math.IsNaN(q):
+		return 0
+	case q >= math.MaxInt64:
+		return math.MaxInt64
+	case q <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(q)
+}
+
+func appendFloat64(buf []byte, v float64) []byte {
+	return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+}
+
+func readFloat64(buf []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(buf))
+}
+
+// decoder reads values from a buffer, remembering the first error.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = io.ErrUnexpectedEOF
+	}
+	d.buf = nil
+}
+
+func (d *decoder) byte() byte {
+	if len(d.buf) < 1 {
+		d.fail()
+		return 0
+	}
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// count reads a length, rejecting values above max so corrupt input can't cause huge
+// allocations.
+func (d *decoder) count(max int) int {
+	v := d.uvarint()
+	if v > uint64(max) {
+		if d.err == nil {
+			d.err = errors.New("count out of range")
+		}
+		return 0
+	}
+	return int(v)
+}
+
+func (d *decoder) float64() float64 {
+	if len(d.buf) < 8 {
+		d.fail()
+		return 0
+	}
+	v := readFloat64(d.buf)
+	d.buf = d.buf[8:]
+	return v
+}
+
+func (d *decoder) float32() float32 {
+	if len(d.buf) < 4 {
+		d.fail()
+		return 0
+	}
+	v := math.Float32frombits(binary.LittleEndian.Uint32(d.buf))
+	d.buf = d.buf[4:]
+	return v
+}
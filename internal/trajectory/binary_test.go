@@ -0,0 +1,209 @@
+package trajectory
+
+import (
+	"bytes"
+	"math"
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+// movingFrames returns frames of n particles moving on circles, with a particle added
+// part way through so both key and delta frames are written.
+func movingFrames(count, n int) []Frame {
+	frames := make([]Frame, count)
+	for f := range frames {
+		m := n
+		if f >= count/2 {
+			m = n + 1
+		}
+		particles := make([]*particle.Particle, m)
+		for i := range particles {
+			angle := float64(f)*0.05 + float64(i)
+			particles[i] = &particle.Particle{
+				ID: i + 1,
+				X:  500 + 100*math.Cos(angle), Y: 400 + 100*math.Sin(angle),
+				Vx: -5 * math.Sin(angle), Vy: 5 * math.Cos(angle),
+				Mass: 2, Radius: 5, Charge: float64(i%3 - 1), Movable: true,
+				Color: particle.Color{R: 1, G: 0.25, B: 0, A: 1},
+			}
+		}
+		frames[f] = Frame{Step: f * 10, Time: float64(f) / 12, Width: 1000, Height: 800, Particles: particles}
+	}
+	return frames
+}
+
+func writeBinary(t *testing.T, frames []Frame, opts BinaryOptions) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewBinaryWriter(&buf, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range frames {
+		if err := w.WriteFrame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	frames := movingFrames(50, 20)
+	opts := BinaryOptions{ChunkFrames: 8, PositionResolution: 1e-3, VelocityResolution: 1e-2}
+	data := writeBinary(t, frames, opts)
+
+	r, err := NewBinaryReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.NumFrames() != len(frames) {
+		t.Fatalf("NumFrames() = %d, want %d", r.NumFrames(), len(frames))
+	}
+
+	// Read out of order to exercise seeking across chunks
+	for _, i := range []int{49, 0, 17, 16, 24, 25, 3} {
+		got, err := r.Frame(i)
+		if err != nil {
+			t.Fatalf("Frame(%d): %v", i, err)
+		}
+		want := frames[i]
+		if got.Step != want.Step || got.Time != want.Time || got.Width != want.Width || got.Height != want.Height {
+			t.Errorf("frame %d: step %d time %v box %vx%v", i, got.Step, got.Time, got.Width, got.Height)
+		}
+		if len(got.Particles) != len(want.Particles) {
+			t.Fatalf("frame %d: %d particles, want %d", i, len(got.Particles), len(want.Particles))
+		}
+		for j, p := range want.Particles {
+			g := got.Particles[j]
+			if math.Abs(g.X-p.X) > opts.PositionResolution/2 || math.Abs(g.Y-p.Y) > opts.PositionResolution/2 ||
+				math.Abs(g.Vx-p.Vx) > opts.VelocityResolution/2 || math.Abs(g.Vy-p.Vy) > opts.VelocityResolution/2 {
+				t.Errorf("frame %d particle %d: got %+v, want %+v", i, j, *g, *p)
+			}
+			if g.ID != p.ID || g.Mass != p.Mass || g.Radius != p.Radius || g.Charge != p.Charge ||
+				g.Color != p.Color || g.Movable != p.Movable {
+				t.Errorf("frame %d particle %d properties: got %+v, want %+v", i, j, *g, *p)
+			}
+		}
+	}
+
+	if step, time := r.FrameInfo(3); step != 30 || time != 3.0/12 {
+		t.Errorf("FrameInfo(3) = %d, %v", step, time)
+	}
+	if i := r.FindStep(255); i != 25 {
+		t.Errorf("FindStep(255) = %d, want 25", i)
+	}
+	if i := r.FindStep(-1); i != -1 {
+		t.Errorf("FindStep(-1) = %d, want -1", i)
+	}
+	if _, err := r.Frame(50); err == nil {
+		t.Error("Frame(50) succeeded past the end")
+	}
+}
+
+func TestBinaryFramesAreCopies(t *testing.T) {
+	data := writeBinary(t, movingFrames(4, 3), BinaryOptions{})
+	r, err := NewBinaryReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := r.Frame(1)
+	f.Particles[0].X = -1
+	again, _ := r.Frame(1)
+	if again.Particles[0].X == -1 {
+		t.Error("changing a returned particle changed the cached frame")
+	}
+}
+
+func TestBinaryGivesIDsOnce(t *testing.T) {
+	frames := movingFrames(4, 3)
+	for _, f := range frames {
+		f.Particles[0].ID = 0
+	}
+	data := writeBinary(t, frames, BinaryOptions{})
+	r, err := NewBinaryReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := r.Frame(0)
+	again, _ := r.Frame(1)
+	if id := first.Particles[0].ID; id == 0 || again.Particles[0].ID != id {
+		t.Errorf("particle without an ID read as %d then %d, want the same new ID", id, again.Particles[0].ID)
+	}
+}
+
+func TestBinaryIsSmallerThanText(t *testing.T) {
+	frames := movingFrames(100, 200)
+	data := writeBinary(t, frames, BinaryOptions{})
+
+	var text bytes.Buffer
+	w := NewXYZWriter(&text)
+	for _, f := range frames {
+		w.WriteFrame(f)
+	}
+	w.Close()
+
+	if len(data)*4 > text.Len() {
+		t.Errorf("binary trajectory is %d bytes, extended XYZ %d; want at least 4x smaller", len(data), text.Len())
+	}
+}
+
+func TestBinaryFlushStartsNewChunk(t *testing.T) {
+	frames := movingFrames(10, 5)
+	var buf bytes.Buffer
+	w, err := NewBinaryWriter(&buf, BinaryOptions{ChunkFrames: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range frames {
+		w.WriteFrame(f)
+		if i == 3 {
+			w.Flush()
+		}
+	}
+	w.Close()
+
+	r, err := NewBinaryReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.chunks) != 2 || r.chunks[0].frames != 4 {
+		t.Errorf("chunks = %+v, want 4 frames then 6", r.chunks)
+	}
+	if f, err := r.Frame(9); err != nil || f.Step != 90 {
+		t.Errorf("Frame(9) = step %d, %v", f.Step, err)
+	}
+}
+
+func TestBinaryCorruptInput(t *testing.T) {
+	data := writeBinary(t, movingFrames(10, 5), BinaryOptions{ChunkFrames: 4})
+
+	tests := map[string][]byte{
+		"empty":      nil,
+		"wrong type": []byte("ITEM: TIMESTEP\n0\nITEM: NUMBER OF ATOMS\n0\n"),
+		"no index":   data[:len(data)-binaryFooterSize],
+	}
+	for name, input := range tests {
+		if _, err := NewBinaryReader(bytes.NewReader(input), int64(len(input))); err == nil {
+			t.Errorf("%s: NewBinaryReader() succeeded", name)
+		}
+	}
+
+	// A damaged chunk is reported when it is decoded
+	damaged := append([]byte{}, data...)
+	for i := binaryHeaderSize; i < binaryHeaderSize+20; i++ {
+		damaged[i] ^= 0xff
+	}
+	r, err := NewBinaryReader(bytes.NewReader(damaged), int64(len(damaged)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Frame(0); err == nil {
+		t.Error("Frame(0) of a damaged chunk succeeded")
+	}
+	if _, err := r.Frame(9); err != nil {
+		t.Errorf("Frame(9) in an intact chunk: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package trajectory
+
+import (
+	"fmt"
+	"particle-physics-simulator/internal/particle"
+	"strconv"
+)
+
+// columnSetter stores one column of a particle line in the particle.
+type columnSetter func(p *particle.Particle, field string) error
+
+// columnSet holds a setter for every column of a particle line; nil setters skip the column.
+type columnSet []columnSetter
+
+// parse creates a particle from the fields of one line.
+func (c columnSet) parse(fields []string) (*particle.Particle, error) {
+	if len(fields) < len(c) {
+		return nil, fmt.Errorf("got %d columns, want %d", len(fields), len(c))
+	}
+	p := newLoadedParticle()
+	for i, set := range c {
+		if set == nil {
+			continue
+		}
+		if err := set(p, fields[i]); err != nil {
+			return nil, fmt.Errorf("column %d: %v", i+1, err)
+		}
+	}
+	return p, nil
+}
+
+func floatSetter(field func(p *particle.Particle) *float64) columnSetter {
+	return func(p *particle.Particle, s string) error {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		*field(p) = v
+		return nil
+	}
+}
+
+func colorSetter(field func(p *particle.Particle) *float32) columnSetter {
+	return func(p *particle.Particle, s string) error {
+		v, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		*field(p) = float32(v)
+		return nil
+	}
+}
+
+var (
+	setX      = floatSetter(func(p *particle.Particle) *float64 { return &p.X })
+	setY      = floatSetter(func(p *particle.Particle) *float64 { return &p.Y })
+	setZ      = floatSetter(func(p *particle.Particle) *float64 { return &p.Z })
+	setVx     = floatSetter(func(p *particle.Particle) *float64 { return &p.Vx })
+	setVy     = floatSetter(func(p *particle.Particle) *float64 { return &p.Vy })
+	setVz     = floatSetter(func(p *particle.Particle) *float64 { return &p.Vz })
+	setCharge = floatSetter(func(p *particle.Particle) *float64 { return &p.Charge })
+	setMass   = floatSetter(func(p *particle.Particle) *float64 { return &p.Mass })
+	setRadius = floatSetter(func(p *particle.Particle) *float64 { return &p.Radius })
+	setColorR = colorSetter(func(p *particle.Particle) *float32 { return &p.Color.R })
+	setColorG = colorSetter(func(p *particle.Particle) *float32 { return &p.Color.G })
+	setColorB = colorSetter(func(p *particle.Particle) *float32 { return &p.Color.B })
+	setColorA = colorSetter(func(p *particle.Particle) *float32 { return &p.Color.A })
+)
+
+func setID(p *particle.Particle, s string) error {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 0 {
+		return fmt.Errorf("invalid id %q", s)
+	}
+	p.ID = id
+	return nil
+}
+
+func setMovable(p *particle.Particle, s string) error {
+	v, err := parseBool(s)
+	if err != nil {
+		return err
+	}
+	p.Movable = v
+	return nil
+}
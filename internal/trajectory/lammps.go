@@ -0,0 +1,244 @@
+package trajectory
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"particle-physics-simulator/internal/particle"
+	"strconv"
+	"strings"
+)
+
+// lammpsColumns are the per-particle columns written by LAMMPSWriter.
+const lammpsColumns = "id type x y z vx vy vz q mass radius movable color_r color_g color_b color_a"
+
+// LAMMPSWriter writes frames as a LAMMPS text dump with custom columns. Particle types are
+// 1 for positive, 2 for negative and 3 for neutral particles, and the box spans the walled
+// area with a nominal unit depth.
+type LAMMPSWriter struct {
+	w *bufio.Writer
+}
+
+// NewLAMMPSWriter creates a LAMMPS dump writer.
+func NewLAMMPSWriter(w io.Writer) *LAMMPSWriter {
+	return &LAMMPSWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteFrame writes one frame.
+func (l *LAMMPSWriter) WriteFrame(f Frame) error {
+	fmt.Fprintf(l.w, "ITEM: TIMESTEP\n%d\n", f.Step)
+	fmt.Fprintf(l.w, "ITEM: TIME\n%s\n", formatFloat(f.Time))
+	fmt.Fprintf(l.w, "ITEM: NUMBER OF ATOMS\n%d\n", len(f.Particles))
+	fmt.Fprintf(l.w, "ITEM: BOX BOUNDS ff ff ff\n0 %s\n0 %s\n-0.5 0.5\n", formatFloat(f.Width), formatFloat(f.Height))
+	fmt.Fprintf(l.w, "ITEM: ATOMS %s\n", lammpsColumns)
+
+	fields := make([]string, 0, 16)
+	for _, p := range f.Particles {
+		_, typ := species(p)
+		movable := "0"
+		if p.Movable {
+			movable = "1"
+		}
+		fields = append(fields[:0],
+			strconv.Itoa(p.ID), strconv.Itoa(typ),
+			formatFloat(p.X), formatFloat(p.Y), formatFloat(p.Z),
+			formatFloat(p.Vx), formatFloat(p.Vy), formatFloat(p.Vz),
+			formatFloat(p.Charge), formatFloat(p.Mass), formatFloat(p.Radius),
+			movable,
+			formatColor(p.Color.R), formatColor(p.Color.G), formatColor(p.Color.B), formatColor(p.Color.A),
+		)
+		l.w.WriteString(strings.Join(fields, " "))
+		if err := l.w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (l *LAMMPSWriter) Flush() error {
+	return l.w.Flush()
+}
+
+// Close flushes the writer. It does not close the underlying writer.
+func (l *LAMMPSWriter) Close() error {
+	return l.Flush()
+}
+
+// ReadLAMMPS reads all frames of a LAMMPS text dump. Columns are found from the ITEM: ATOMS
+// header, so dumps written by LAMMPS itself load as long as they have unscaled positions
+// (x y or xu yu). Positions are made relative to the lower box bounds.
+func ReadLAMMPS(r io.Reader) ([]Frame, error) {
+	lr := newLineReader(r)
+	var frames []Frame
+	var frame *Frame
+	count := -1
+	var xlo, ylo float64
+
+	for {
+		line, ok := lr.next()
+		if !ok {
+			if err := lr.scanner.Err(); err != nil {
+				return nil, err
+			}
+			if frame != nil {
+				return nil, fmt.Errorf("trajectory: frame at step %d has no ITEM: ATOMS section", frame.Step)
+			}
+			return frames, nil
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "ITEM:") {
+			return nil, lr.errorf("unexpected %q", line)
+		}
+		item := strings.TrimSpace(strings.TrimPrefix(line, "ITEM:"))
+
+		switch {
+		case item == "TIMESTEP":
+			if frame != nil {
+				return nil, lr.errorf("frame at step %d has no ITEM: ATOMS section", frame.Step)
+			}
+			value, err := lr.expect("timestep")
+			if err != nil {
+				return nil, err
+			}
+			step, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, lr.errorf("invalid timestep %q", value)
+			}
+			frame = &Frame{Step: step}
+			count = -1
+
+		case !isFrameItem(item):
+			// Skip items we don't use, such as ITEM: UNITS, up to the next ITEM line
+			for {
+				value, ok := lr.next()
+				if !ok {
+					break
+				}
+				if strings.HasPrefix(strings.TrimSpace(value), "ITEM:") {
+					lr.back(value)
+					break
+				}
+			}
+
+		case frame == nil:
+			return nil, lr.errorf("ITEM: %s before ITEM: TIMESTEP", item)
+
+		case item == "TIME":
+			value, err := lr.expect("time")
+			if err != nil {
+				return nil, err
+			}
+			if frame.Time, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+				return nil, lr.errorf("invalid time %q", value)
+			}
+
+		case item == "NUMBER OF ATOMS":
+			value, err := lr.expect("number of atoms")
+			if err != nil {
+				return nil, err
+			}
+			if count, err = strconv.Atoi(strings.TrimSpace(value)); err != nil || count < 0 {
+				return nil, lr.errorf("invalid number of atoms %q", value)
+			}
+
+		case strings.HasPrefix(item, "BOX BOUNDS"):
+			var bounds [3][2]float64
+			for i := range bounds {
+				value, err := lr.expect("box bounds")
+				if err != nil {
+					return nil, err
+				}
+				v := strings.Fields(value)
+				if len(v) < 2 {
+					return nil, lr.errorf("invalid box bounds %q", value)
+				}
+				lo, err1 := strconv.ParseFloat(v[0], 64)
+				hi, err2 := strconv.ParseFloat(v[1], 64)
+				if err1 != nil || err2 != nil {
+					return nil, lr.errorf("invalid box bounds %q", value)
+				}
+				bounds[i] = [2]float64{lo, hi}
+			}
+			xlo, ylo = bounds[0][0], bounds[1][0]
+			frame.Width = bounds[0][1] - xlo
+			frame.Height = bounds[1][1] - ylo
+
+		case strings.HasPrefix(item, "ATOMS"):
+			if count < 0 {
+				return nil, lr.errorf("ITEM: ATOMS before ITEM: NUMBER OF ATOMS")
+			}
+			columns, err := lammpsColumnSet(strings.Fields(strings.TrimPrefix(item, "ATOMS")))
+			if err != nil {
+				return nil, lr.errorf("%v", err)
+			}
+			frame.Particles = make([]*particle.Particle, 0, count)
+			for i := 0; i < count; i++ {
+				value, err := lr.expect("atom line")
+				if err != nil {
+					return nil, err
+				}
+				p, err := columns.parse(strings.Fields(value))
+				if err != nil {
+					return nil, lr.errorf("%v", err)
+				}
+				p.X -= xlo
+				p.Y -= ylo
+				frame.Particles = append(frame.Particles, p)
+			}
+			finishLoaded(frame.Particles)
+			frames = append(frames, *frame)
+			frame = nil
+			xlo, ylo = 0, 0
+		}
+	}
+}
+
+// isFrameItem reports whether the reader uses an item that follows ITEM: TIMESTEP.
+func isFrameItem(item string) bool {
+	return item == "TIME" || item == "NUMBER OF ATOMS" ||
+		strings.HasPrefix(item, "BOX BOUNDS") || strings.HasPrefix(item, "ATOMS")
+}
+
+// lammpsColumnSet maps the column names of an ITEM: ATOMS header to setters.
+func lammpsColumnSet(names []string) (columnSet, error) {
+	columns := make(columnSet, len(names))
+	hasX, hasY := false, false
+	for i, name := range names {
+		columns[i] = lammpsSetters[name]
+		switch name {
+		case "x", "xu":
+			hasX = true
+		case "y", "yu":
+			hasY = true
+		}
+	}
+	if !hasX || !hasY {
+		return nil, fmt.Errorf("atoms have no unscaled positions")
+	}
+	return columns, nil
+}
+
+var lammpsSetters = map[string]columnSetter{
+	"id":      setID,
+	"x":       setX,
+	"y":       setY,
+	"z":       setZ,
+	"xu":      setX,
+	"yu":      setY,
+	"zu":      setZ,
+	"vx":      setVx,
+	"vy":      setVy,
+	"vz":      setVz,
+	"q":       setCharge,
+	"mass":    setMass,
+	"radius":  setRadius,
+	"movable": setMovable,
+	"color_r": setColorR,
+	"color_g": setColorG,
+	"color_b": setColorB,
+	"color_a": setColorA,
+}
@@ -0,0 +1,312 @@
+// Package trajectory writes and reads particle trajectories in formats understood by
+// external tools such as OVITO and VMD, and loads them back as initial conditions.
+package trajectory
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/world"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Frame is the state of all particles at one step.
+type Frame struct {
+	Step          int
+	Time          float64
+	Width, Height float64 // Size of the walled area in pixels
+	Particles     []*particle.Particle
+}
+
+// FrameOf returns the current frame of a world. The particles are shared, not copied, so
+// the frame must be written before the world steps again.
+func FrameOf(w *world.World) Frame {
+	return Frame{
+		Step:      w.Steps,
+		Time:      w.Time,
+		Width:     float64(w.Width),
+		Height:    float64(w.Height),
+		Particles: w.Particles,
+	}
+}
+
+// World creates a world from the frame, e.g. to use a saved frame as initial conditions.
+func (f Frame) World() *world.World {
+	w := world.New(f.Particles, int(f.Width), int(f.Height))
+	w.Steps = f.Step
+	w.Time = f.Time
+	return w
+}
+
+// Format is a trajectory file format.
+type Format int
+
+const (
+	ExtendedXYZ Format = iota
+	LAMMPSDump         // LAMMPS text dump with custom columns
+	Binary             // Compact chunked binary format, see BinaryWriter
+)
+
+// FormatFromPath picks the format from a file extension: .xyz or .extxyz for extended XYZ,
+// .dump, .lammpstrj or .lammps for LAMMPS dumps and .ptrj for the binary format.
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xyz", ".extxyz":
+		return ExtendedXYZ, nil
+	case ".dump", ".lammpstrj", ".lammps":
+		return LAMMPSDump, nil
+	case ".ptrj":
+		return Binary, nil
+	}
+	return 0, fmt.Errorf("trajectory: unknown format for %q", path)
+}
+
+// FrameWriter writes frames one after another to a trajectory file.
+type FrameWriter interface {
+	WriteFrame(f Frame) error
+	// Flush writes any buffered data to the underlying writer.
+	Flush() error
+	// Close finishes the trajectory. It does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter creates a frame writer for the format, with default options for the binary format.
+func NewWriter(format Format, w io.Writer) (FrameWriter, error) {
+	switch format {
+	case LAMMPSDump:
+		return NewLAMMPSWriter(w), nil
+	case Binary:
+		return NewBinaryWriter(w, BinaryOptions{})
+	}
+	return NewXYZWriter(w), nil
+}
+
+// Read reads all frames in the given format. Binary trajectories are read into memory
+// first; use NewBinaryReader or OpenBinary to seek in them instead.
+func Read(format Format, r io.Reader) ([]Frame, error) {
+	switch format {
+	case LAMMPSDump:
+		return ReadLAMMPS(r)
+	case Binary:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		br, err := NewBinaryReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		return br.ReadAll()
+	}
+	return ReadXYZ(r)
+}
+
+// ReadFile reads all frames of a trajectory file, picking the format from its extension.
+func ReadFile(path string) ([]Frame, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == Binary {
+		br, err := OpenBinary(path)
+		if err != nil {
+			return nil, err
+		}
+		defer br.Close()
+		return br.ReadAll()
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(format, f)
+}
+
+// Recorder writes every Stride-th step of a world to a frame writer.
+type Recorder struct {
+	Writer FrameWriter
+	Stride int // Values below 1 write every step
+}
+
+// NewRecorder creates a recorder writing every stride steps.
+func NewRecorder(w FrameWriter, stride int) *Recorder {
+	return &Recorder{Writer: w, Stride: stride}
+}
+
+// Observe writes the world if its step count is a multiple of the stride. Call it after every step.
+func (r *Recorder) Observe(w *world.World) error {
+	if r.Stride > 1 && w.Steps%r.Stride != 0 {
+		return nil
+	}
+	return r.Record(w)
+}
+
+// Record writes the world unconditionally, giving IDs to particles that have none.
+func (r *Recorder) Record(w *world.World) error {
+	particle.AssignIDs(w.Particles)
+	return r.Writer.WriteFrame(FrameOf(w))
+}
+
+// species names and LAMMPS atom types, by the sign of the charge
+func species(p *particle.Particle) (string, int) {
+	switch {
+	case p.Charge > 0:
+		return "pos", 1
+	case p.Charge < 0:
+		return "neg", 2
+	}
+	return "neutral", 3
+}
+
+// newLoadedParticle returns a particle with the defaults used for columns a file doesn't have.
+func newLoadedParticle() *particle.Particle {
+	return &particle.Particle{
+		Mass:    constants.DefaultMass,
+		Radius:  constants.DefaultRadius,
+		Color:   particle.Color{R: 1, G: 1, B: 1, A: 1},
+		Movable: true,
+	}
+}
+
+// finishLoaded gives IDs to loaded particles that have none and keeps new IDs clear of the
+// loaded ones.
+func finishLoaded(particles []*particle.Particle) {
+	for _, p := range particles {
+		particle.ReserveID(p.ID)
+	}
+	particle.AssignIDs(particles)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func formatColor(v float32) string {
+	return strconv.FormatFloat(float64(v), 'g', -1, 32)
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "t", "true", "1":
+		return true, nil
+	case "f", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid logical value %q", s)
+}
+
+// lineReader reads lines and counts them for error messages.
+type lineReader struct {
+	scanner *bufio.Scanner
+	line    int
+	pending *string // Line returned by back
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &lineReader{scanner: scanner}
+}
+
+// next returns the next line, or false at the end of the input.
+func (l *lineReader) next() (string, bool) {
+	if l.pending != nil {
+		line := *l.pending
+		l.pending = nil
+		l.line++
+		return line, true
+	}
+	if !l.scanner.Scan() {
+		return "", false
+	}
+	l.line++
+	return l.scanner.Text(), true
+}
+
+// back makes next return line again.
+func (l *lineReader) back(line string) {
+	l.pending = &line
+	l.line--
+}
+
+func (l *lineReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("trajectory: line %d: %s", l.line, fmt.Sprintf(format, args...))
+}
+
+// expect returns the next line or an error naming what was expected.
+func (l *lineReader) expect(what string) (string, error) {
+	line, ok := l.next()
+	if !ok {
+		if err := l.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("trajectory: unexpected end of file, want %s", what)
+	}
+	return line, nil
+}
+
+// Source gives random access to the frames of a recorded trajectory.
+type Source interface {
+	NumFrames() int
+	// FrameInfo returns the step and time of frame i without decoding it.
+	FrameInfo(i int) (step int, time float64)
+	// Frame returns frame i with particles the caller may modify.
+	Frame(i int) (Frame, error)
+	Close() error
+}
+
+// FrameList is a Source of frames held in memory.
+type FrameList []Frame
+
+// NumFrames returns the number of frames.
+func (l FrameList) NumFrames() int {
+	return len(l)
+}
+
+// FrameInfo returns the step and time of frame i.
+func (l FrameList) FrameInfo(i int) (int, float64) {
+	return l[i].Step, l[i].Time
+}
+
+// Frame returns a copy of frame i.
+func (l FrameList) Frame(i int) (Frame, error) {
+	if i < 0 || i >= len(l) {
+		return Frame{}, fmt.Errorf("trajectory: frame %d out of range [0, %d)", i, len(l))
+	}
+	f := l[i]
+	f.Particles = make([]*particle.Particle, len(l[i].Particles))
+	for j, p := range l[i].Particles {
+		copied := *p
+		f.Particles[j] = &copied
+	}
+	return f, nil
+}
+
+// Close does nothing; it lets FrameList satisfy Source.
+func (l FrameList) Close() error {
+	return nil
+}
+
+// Open opens a trajectory file for random access. Binary trajectories are decoded a chunk
+// at a time as frames are requested; text formats are read into memory.
+func Open(path string) (Source, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == Binary {
+		return OpenBinary(path)
+	}
+	frames, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FrameList(frames), nil
+}
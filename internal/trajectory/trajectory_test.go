@@ -0,0 +1,230 @@
+package trajectory
+
+import (
+	"bytes"
+	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/world"
+	"strings"
+	"testing"
+)
+
+func testParticles() []*particle.Particle {
+	return []*particle.Particle{
+		{ID: 3, X: 100.5, Y: 200.25, Vx: 1.0 / 3, Vy: -2, Vz: 0.5, Charge: 0.1, Mass: 5, Radius: 5,
+			Color: particle.Color{R: 1, G: 0.5, B: 0, A: 1}, Movable: true},
+		{ID: 9, X: 300, Y: 300, Mass: 100, Radius: 50, Color: particle.Color{R: 0.5, G: 0.5, B: 0.5, A: 1}},
+		{ID: 12, X: 1, Y: 2, Z: -1e-3, Vx: 1e6, Charge: -2.5, Mass: 1, Radius: 10, Movable: true},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{ExtendedXYZ, LAMMPSDump} {
+		var buf bytes.Buffer
+		w, err := NewWriter(format, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames := []Frame{
+			{Step: 0, Time: 0, Width: 1800, Height: 950, Particles: testParticles()},
+			{Step: 10, Time: 1.0 / 12, Width: 1800, Height: 950, Particles: testParticles()[:2]},
+		}
+		for _, f := range frames {
+			if err := w.WriteFrame(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := Read(format, &buf)
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		if len(got) != len(frames) {
+			t.Fatalf("format %d: read %d frames, want %d", format, len(got), len(frames))
+		}
+		for i, want := range frames {
+			g := got[i]
+			if g.Step != want.Step || g.Time != want.Time || g.Width != want.Width || g.Height != want.Height {
+				t.Errorf("format %d frame %d: got step %d time %v box %vx%v", format, i, g.Step, g.Time, g.Width, g.Height)
+			}
+			if len(g.Particles) != len(want.Particles) {
+				t.Fatalf("format %d frame %d: got %d particles, want %d", format, i, len(g.Particles), len(want.Particles))
+			}
+			for j, p := range want.Particles {
+				if *g.Particles[j] != *p {
+					t.Errorf("format %d frame %d particle %d:\n got %+v\nwant %+v", format, i, j, *g.Particles[j], *p)
+				}
+			}
+		}
+	}
+}
+
+func TestReadXYZFromOtherTools(t *testing.T) {
+	input := `2
+Properties=species:S:1:pos:R:3:forces:R:3:Masses:R:1 Time=2.5 comment="a b c"
+Ar 1 2 0 9 9 9 40
+Ne 3 4 0 9 9 9 20
+2
+plain xyz
+H 5 6 0
+H 7 8 0
+`
+	frames, err := ReadXYZ(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("read %d frames, want 2", len(frames))
+	}
+	if frames[0].Time != 2.5 {
+		t.Errorf("time = %v, want 2.5", frames[0].Time)
+	}
+
+	p := frames[0].Particles[1]
+	if p.X != 3 || p.Y != 4 || p.Mass != 20 || p.Vx != 0 {
+		t.Errorf("particle = %+v", *p)
+	}
+	// Missing properties get the simulation defaults and fresh IDs
+	p = frames[1].Particles[0]
+	if p.X != 5 || p.Mass != constants.DefaultMass || p.Radius != constants.DefaultRadius || !p.Movable || p.ID == 0 {
+		t.Errorf("particle = %+v", *p)
+	}
+}
+
+func TestReadLAMMPSFromOtherTools(t *testing.T) {
+	input := `ITEM: UNITS
+lj
+ITEM: TIMESTEP
+100
+ITEM: NUMBER OF ATOMS
+2
+ITEM: BOX BOUNDS pp pp pp
+-10 90
+5 55
+-1 1
+ITEM: ATOMS id type xu yu zu fx
+7 1 0 15 0 1
+8 1 40 5 0 1
+`
+	frames, err := ReadLAMMPS(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 1 {
+		t.Fatalf("read %d frames, want 1", len(frames))
+	}
+	f := frames[0]
+	if f.Step != 100 || f.Width != 100 || f.Height != 50 {
+		t.Errorf("frame step %d box %vx%v", f.Step, f.Width, f.Height)
+	}
+	// Positions are shifted so the box starts at the origin
+	if p := f.Particles[0]; p.ID != 7 || p.X != 10 || p.Y != 10 {
+		t.Errorf("particle = %+v", *p)
+	}
+	if next := particle.NextID(); next <= 8 {
+		t.Errorf("NextID() = %d, want above the loaded IDs", next)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+	}{
+		{"xyz bad count", ExtendedXYZ, "two\n\n"},
+		{"xyz truncated", ExtendedXYZ, "2\n\nH 0 0 0\n"},
+		{"xyz no positions", ExtendedXYZ, "1\nProperties=species:S:1\nH\n"},
+		{"xyz bad number", ExtendedXYZ, "1\n\nH 0 zero 0\n"},
+		{"lammps no timestep", LAMMPSDump, "ITEM: NUMBER OF ATOMS\n1\n"},
+		{"lammps scaled positions", LAMMPSDump, "ITEM: TIMESTEP\n0\nITEM: NUMBER OF ATOMS\n1\nITEM: ATOMS id xs ys\n1 0.5 0.5\n"},
+		{"lammps missing atoms", LAMMPSDump, "ITEM: TIMESTEP\n0\nITEM: NUMBER OF ATOMS\n1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read(tt.format, strings.NewReader(tt.input)); err == nil {
+				t.Error("Read() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestRecorderStride(t *testing.T) {
+	var buf bytes.Buffer
+	w := world.New([]*particle.Particle{{X: 50, Y: 50, Mass: 1, Radius: 5, Movable: true}}, 100, 100)
+	rec := NewRecorder(NewXYZWriter(&buf), 4)
+	if err := rec.Record(w); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		w.Step(0.01)
+		if err := rec.Observe(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec.Writer.Flush()
+
+	frames, err := ReadXYZ(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []int
+	for _, f := range frames {
+		steps = append(steps, f.Step)
+	}
+	if len(steps) != 3 || steps[0] != 0 || steps[1] != 4 || steps[2] != 8 {
+		t.Errorf("recorded steps %v, want [0 4 8]", steps)
+	}
+	// The particle got an ID when it was first written and kept it
+	id := frames[0].Particles[0].ID
+	if id == 0 || frames[2].Particles[0].ID != id {
+		t.Errorf("particle IDs %d and %d, want the same non-zero ID", id, frames[2].Particles[0].ID)
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]Format{"run.xyz": ExtendedXYZ, "run.extxyz": ExtendedXYZ, "run.lammpstrj": LAMMPSDump, "RUN.DUMP": LAMMPSDump, "run.ptrj": Binary}
+	for path, want := range tests {
+		if got, err := FormatFromPath(path); err != nil || got != want {
+			t.Errorf("FormatFromPath(%q) = %v, %v, want %v", path, got, err, want)
+		}
+	}
+	if _, err := FormatFromPath("run.csv"); err == nil {
+		t.Error("FormatFromPath(run.csv) succeeded")
+	}
+}
+
+func TestSources(t *testing.T) {
+	frames := []Frame{
+		{Step: 0, Time: 0, Particles: testParticles()},
+		{Step: 5, Time: 0.5, Particles: testParticles()},
+	}
+	data := writeBinary(t, frames, BinaryOptions{})
+	br, err := NewBinaryReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, src := range map[string]Source{"list": FrameList(frames), "binary": br} {
+		if src.NumFrames() != 2 {
+			t.Errorf("%s: NumFrames() = %d", name, src.NumFrames())
+		}
+		if step, time := src.FrameInfo(1); step != 5 || time != 0.5 {
+			t.Errorf("%s: FrameInfo(1) = %d, %v", name, step, time)
+		}
+		f, err := src.Frame(1)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		f.Particles[0].X = -1
+		if frames[1].Particles[0].X == -1 {
+			t.Errorf("%s: Frame() returned shared particles", name)
+		}
+		if _, err := src.Frame(2); err == nil {
+			t.Errorf("%s: Frame(2) succeeded past the end", name)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package trajectory
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"particle-physics-simulator/internal/particle"
+	"strconv"
+	"strings"
+)
+
+// xyzProperties is the Properties value of the frames written by XYZWriter.
+const xyzProperties = "species:S:1:pos:R:3:velo:R:3:id:I:1:charge:R:1:mass:R:1:radius:R:1:color:R:4:movable:L:1"
+
+// XYZWriter writes frames in the extended XYZ format. The comment line of every frame holds
+// the walled area as the lattice, the time and the step, and the particle lines carry the
+// position, velocity, ID, charge, mass, radius, color and whether the particle is movable.
+type XYZWriter struct {
+	w *bufio.Writer
+}
+
+// NewXYZWriter creates an extended XYZ writer.
+func NewXYZWriter(w io.Writer) *XYZWriter {
+	return &XYZWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteFrame writes one frame.
+func (x *XYZWriter) WriteFrame(f Frame) error {
+	fmt.Fprintf(x.w, "%d\n", len(f.Particles))
+	// The simulation is flat, so the lattice gets a nominal unit depth
+	fmt.Fprintf(x.w, "Lattice=\"%s 0 0 0 %s 0 0 0 1\" Properties=%s Time=%s Step=%d pbc=\"F F F\"\n",
+		formatFloat(f.Width), formatFloat(f.Height), xyzProperties, formatFloat(f.Time), f.Step)
+
+	fields := make([]string, 0, 16)
+	for _, p := range f.Particles {
+		name, _ := species(p)
+		movable := "F"
+		if p.Movable {
+			movable = "T"
+		}
+		fields = append(fields[:0],
+			name,
+			formatFloat(p.X), formatFloat(p.Y), formatFloat(p.Z),
+			formatFloat(p.Vx), formatFloat(p.Vy), formatFloat(p.Vz),
+			strconv.Itoa(p.ID),
+			formatFloat(p.Charge), formatFloat(p.Mass), formatFloat(p.Radius),
+			formatColor(p.Color.R), formatColor(p.Color.G), formatColor(p.Color.B), formatColor(p.Color.A),
+			movable,
+		)
+		x.w.WriteString(strings.Join(fields, " "))
+		if err := x.w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (x *XYZWriter) Flush() error {
+	return x.w.Flush()
+}
+
+// Close flushes the writer. It does not close the underlying writer.
+func (x *XYZWriter) Close() error {
+	return x.Flush()
+}
+
+// ReadXYZ reads all frames of an extended XYZ file. Columns are found from the Properties
+// key of each frame, so files written by other tools load as long as they have positions;
+// plain XYZ files are read as species and positions. Missing properties get the defaults of
+// the simulation, and unknown ones are skipped.
+func ReadXYZ(r io.Reader) ([]Frame, error) {
+	lr := newLineReader(r)
+	var frames []Frame
+	for {
+		line, ok := lr.next()
+		if !ok {
+			return frames, lr.scanner.Err()
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil || n < 0 {
+			return nil, lr.errorf("invalid particle count %q", line)
+		}
+		comment, err := lr.expect("comment line")
+		if err != nil {
+			return nil, err
+		}
+
+		frame, columns, err := parseXYZComment(comment)
+		if err != nil {
+			return nil, lr.errorf("%v", err)
+		}
+
+		frame.Particles = make([]*particle.Particle, 0, n)
+		for i := 0; i < n; i++ {
+			line, err := lr.expect("particle line")
+			if err != nil {
+				return nil, err
+			}
+			p, err := columns.parse(strings.Fields(line))
+			if err != nil {
+				return nil, lr.errorf("%v", err)
+			}
+			frame.Particles = append(frame.Particles, p)
+		}
+		finishLoaded(frame.Particles)
+		frames = append(frames, frame)
+	}
+}
+
+// parseXYZComment reads the frame metadata and column layout from a comment line.
+func parseXYZComment(comment string) (Frame, columnSet, error) {
+	var frame Frame
+	properties := "species:S:1:pos:R:3"
+
+	for key, value := range parseKeyValues(comment) {
+		var err error
+		switch strings.ToLower(key) {
+		case "properties":
+			properties = value
+		case "lattice":
+			v := strings.Fields(value)
+			if len(v) != 9 {
+				return frame, nil, fmt.Errorf("lattice %q needs 9 values", value)
+			}
+			if frame.Width, err = strconv.ParseFloat(v[0], 64); err == nil {
+				frame.Height, err = strconv.ParseFloat(v[4], 64)
+			}
+		case "time":
+			frame.Time, err = strconv.ParseFloat(value, 64)
+		case "step", "timestep":
+			frame.Step, err = strconv.Atoi(value)
+		}
+		if err != nil {
+			return frame, nil, fmt.Errorf("invalid %s %q", key, value)
+		}
+	}
+
+	columns, err := parseXYZProperties(properties)
+	return frame, columns, err
+}
+
+// parseKeyValues splits an extended XYZ comment line into key=value pairs, where values
+// may be double-quoted. Words without a value are ignored.
+func parseKeyValues(s string) map[string]string {
+	values := map[string]string{}
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && s[i] != '=' && s[i] != ' ' && s[i] != '\t' {
+			i++
+		}
+		key := s[start:i]
+		if i >= len(s) || s[i] != '=' {
+			continue
+		}
+		i++
+
+		if i < len(s) && s[i] == '"' {
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				values[key] = s[i+1:]
+				break
+			}
+			values[key] = s[i+1 : i+1+end]
+			i += end + 2
+			continue
+		}
+		start = i
+		for i < len(s) && s[i] != ' ' && s[i] != '\t' {
+			i++
+		}
+		values[key] = s[start:i]
+	}
+	return values
+}
+
+// parseXYZProperties maps a Properties value such as "species:S:1:pos:R:3" to columns.
+func parseXYZProperties(properties string) (columnSet, error) {
+	parts := strings.Split(properties, ":")
+	if len(parts)%3 != 0 {
+		return nil, fmt.Errorf("invalid properties %q", properties)
+	}
+
+	var columns columnSet
+	hasPos := false
+	for i := 0; i < len(parts); i += 3 {
+		name := strings.ToLower(parts[i])
+		count, err := strconv.Atoi(parts[i+2])
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid count for property %q", parts[i])
+		}
+
+		setters := xyzSetters[name]
+		if name == "pos" {
+			hasPos = true
+		}
+		for c := 0; c < count; c++ {
+			if c < len(setters) {
+				columns = append(columns, setters[c])
+			} else {
+				columns = append(columns, nil)
+			}
+		}
+	}
+	if !hasPos {
+		return nil, fmt.Errorf("properties %q have no positions", properties)
+	}
+	return columns, nil
+}
+
+var xyzSetters = map[string][]columnSetter{
+	"pos":        {setX, setY, setZ},
+	"velo":       {setVx, setVy, setVz},
+	"vel":        {setVx, setVy, setVz},
+	"velocities": {setVx, setVy, setVz},
+	"id":         {setID},
+	"charge":     {setCharge},
+	"q":          {setCharge},
+	"mass":       {setMass},
+	"masses":     {setMass},
+	"radius":     {setRadius},
+	"radii":      {setRadius},
+	"color":      {setColorR, setColorG, setColorB, setColorA},
+	"movable":    {setMovable},
+}
@@ -0,0 +1,164 @@
+// Package world holds the state of a simulation and advances it one step at a time,
+// independently of any window, so runs can be driven headless or by the interactive app.
+package world
+
+import (
+	"particle-physics-simulator/internal/analytics"
+	"particle-physics-simulator/internal/collisions"
+	"particle-physics-simulator/internal/constants"
+	"particle-physics-simulator/internal/force"
+	"particle-physics-simulator/internal/particle"
+	"particle-physics-simulator/internal/physics"
+	"sync"
+)
+
+// World is a set of particles in a walled area together with the fields acting on them.
+//
+// Each step applies the external fields, gravity, the optional drag and friction, resolves
+// collisions and bounces particles off the walls. Particle-particle Coulomb and gravitational
+// forces are only applied with PairForces, as the interactive simulation doesn't apply them.
+type World struct {
+	Particles []*particle.Particle
+	Fields    force.ExternalFields
+	Width     int // Size of the walled area in pixels
+	Height    int
+
+	AirDrag        bool // Apply physics.ApplyAirFriction every step
+	GroundFriction bool // Apply physics.ApplyFriction to grounded particles every step
+	PairForces     bool // Apply physics.ApplyPairForces every step
+
+	Time  float64 // Elapsed simulation time in seconds
+	Steps int     // Number of steps taken
+
+	// Ledger, if set, receives an energy account for every step.
+	Ledger *analytics.Ledger
+}
+
+// New creates a world with the given particles in a width x height area.
+func New(particles []*particle.Particle, width, height int) *World {
+	return &World{
+		Particles: particles,
+		Width:     width,
+		Height:    height,
+	}
+}
+
+// Step advances the world by dt seconds.
+func (w *World) Step(dt float64) {
+	var entry analytics.LedgerEntry
+	recording := w.Ledger != nil
+	if recording && !w.Ledger.Started() {
+		w.Ledger.Start(w.Energy())
+	}
+
+	// measure returns the energy that dissipative stages can change: kinetic and uniform gravity
+	measure := func() float64 {
+		return analytics.MechanicalEnergy(w.Particles, constants.Gravity, float64(w.Height))
+	}
+
+	if !w.Fields.IsEmpty() {
+		var before []analytics.Velocity
+		if recording {
+			before = analytics.SaveVelocities(w.Particles)
+		}
+		physics.ApplyExternalFields(w.Particles, w.Fields, w.Time, dt)
+		if recording {
+			entry.ExternalWork, entry.MagneticWork = analytics.ExternalFieldWork(w.Particles, before, w.Fields, w.Time, dt)
+		}
+	}
+
+	// The pair forces are conservative: their work shows up as a change of the pair energy
+	if w.PairForces {
+		physics.ApplyPairForces(w.Particles, dt)
+	}
+
+	// Parallelize particle updates
+	var wg sync.WaitGroup
+	wg.Add(len(w.Particles))
+	for _, p := range w.Particles {
+		go func(p *particle.Particle) {
+			defer wg.Done()
+			physics.UpdateVelocity(p, dt)
+			physics.UpdatePosition(p, dt)
+		}(p)
+	}
+	wg.Wait()
+
+	if w.AirDrag {
+		energyBefore := 0.0
+		if recording {
+			energyBefore = measure()
+		}
+		for _, p := range w.Particles {
+			if p.Movable {
+				physics.ApplyAirFriction(p)
+			}
+		}
+		if recording {
+			entry.Drag = energyBefore - measure()
+		}
+	}
+
+	// Collisions are resolved one pair at a time: pairs share particles, so handling them
+	// concurrently would race, and a fixed order keeps runs reproducible.
+	energyBefore := 0.0
+	if recording {
+		energyBefore = measure()
+	}
+	for i := 0; i < len(w.Particles); i++ {
+		for j := i + 1; j < len(w.Particles); j++ {
+			if collisions.WillCollide(w.Particles[i], w.Particles[j], dt) {
+				collisions.HandleCollision(w.Particles[i], w.Particles[j])
+			}
+		}
+	}
+	if recording {
+		entry.Collisions = energyBefore - measure()
+		energyBefore = measure()
+	}
+
+	for _, p := range w.Particles {
+		physics.ApplyBoundaryConditions(p, w.Width, w.Height)
+	}
+	if recording {
+		entry.Boundaries = energyBefore - measure()
+	}
+
+	if w.GroundFriction {
+		if recording {
+			energyBefore = measure()
+		}
+		for _, p := range w.Particles {
+			if p.IsGrounded {
+				physics.ApplyFriction(p)
+			}
+		}
+		if recording {
+			entry.Friction = energyBefore - measure()
+		}
+	}
+
+	w.Time += dt
+	w.Steps++
+
+	if recording {
+		entry.Step = w.Steps
+		entry.Time = w.Time
+		entry.Energy = w.Energy()
+		w.Ledger.Record(entry)
+	}
+}
+
+// Energy measures the current energy of the particles, with the floor at the bottom wall.
+// The particles' mutual Coulomb and gravitational energy is only counted with PairForces:
+// without the forces it would change while they move with nothing to balance it and show up
+// as drift.
+func (w *World) Energy() analytics.EnergyBreakdown {
+	if w.PairForces {
+		return analytics.MeasureEnergy(w.Particles, constants.Gravity, float64(w.Height))
+	}
+	return analytics.EnergyBreakdown{
+		Kinetic:        analytics.KineticEnergy(w.Particles),
+		GravityUniform: analytics.UniformGravityPotential(w.Particles, constants.Gravity, float64(w.Height)),
+	}
+}
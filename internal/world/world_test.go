@@ -0,0 +1,162 @@
+package world
+
+import (
+	"math"
+	"particle-physics-simulator/internal/analytics"
+	"particle-physics-simulator/internal/force"
+	"particle-physics-simulator/internal/particle"
+	"testing"
+)
+
+const dt = 1.0 / 120.0
+
+func TestStepAdvancesTime(t *testing.T) {
+	p := particle.NewParticle(100, 100, 60, 0, 0, 0, 1, 5, particle.Color{}, true)
+	w := New([]*particle.Particle{p}, 800, 600)
+
+	for i := 0; i < 120; i++ {
+		w.Step(dt)
+	}
+
+	if w.Steps != 120 || math.Abs(w.Time-1) > 1e-9 {
+		t.Errorf("Steps = %d, Time = %v, want 120 and 1", w.Steps, w.Time)
+	}
+	if math.Abs(p.X-160) > 1e-9 {
+		t.Errorf("X = %v, want 160 after one second at 60 px/s", p.X)
+	}
+	if p.Y <= 100 {
+		t.Errorf("Y = %v, want the particle to have fallen", p.Y)
+	}
+}
+
+func TestLedgerFreeFall(t *testing.T) {
+	p := particle.NewParticle(100, 100, 30, 0, 0, 0, 2, 5, particle.Color{}, true)
+	w := New([]*particle.Particle{p}, 800, 10000)
+	w.Ledger = analytics.NewLedger(0)
+	initial := w.Energy().Total()
+
+	for i := 0; i < 240; i++ {
+		w.Step(dt)
+	}
+
+	last, ok := w.Ledger.Last()
+	if !ok || len(w.Ledger.Entries) != 240 {
+		t.Fatalf("ledger has %d entries, want 240", len(w.Ledger.Entries))
+	}
+	if last.Dissipated() != 0 {
+		t.Errorf("Dissipated() = %v, want 0 in free fall", last.Dissipated())
+	}
+	// Symplectic Euler keeps the energy error bounded and small
+	if math.Abs(last.Drift) > 1e-2*initial {
+		t.Errorf("Drift = %v, want within 1%% of %v", last.Drift, initial)
+	}
+}
+
+func TestLedgerDissipation(t *testing.T) {
+	// A ball dropped onto the floor loses energy on every damped bounce
+	p := particle.NewParticle(100, 500, 0, 0, 0, 0, 1, 10, particle.Color{}, true)
+	w := New([]*particle.Particle{p}, 800, 600)
+	w.AirDrag = true
+	w.Ledger = analytics.NewLedger(0)
+
+	drag, boundaries := 0.0, 0.0
+	for i := 0; i < 120; i++ {
+		w.Step(dt)
+		last, _ := w.Ledger.Last()
+		drag += last.Drag
+		boundaries += last.Boundaries
+	}
+
+	if drag <= 0 {
+		t.Errorf("drag dissipation = %v, want positive", drag)
+	}
+	if boundaries <= 0 {
+		t.Errorf("boundary dissipation = %v, want positive", boundaries)
+	}
+	if math.Abs(w.Ledger.TotalDissipated()-(drag+boundaries)) > 1e-9 {
+		t.Errorf("TotalDissipated() = %v, want %v", w.Ledger.TotalDissipated(), drag+boundaries)
+	}
+}
+
+func TestLedgerMagneticWork(t *testing.T) {
+	// A charge circling in a magnetic field: the field does no work, only integration error
+	p := particle.NewCoulombParticle(400, 300, 50, 0, 0, 0, 1, 5, particle.Color{}, 1, true)
+	w := New([]*particle.Particle{p}, 800, 100000)
+	w.Fields = force.ExternalFields{
+		Magnetic: []force.MagneticFieldSource{force.MagneticField{Strength: 2, Direction: 1}},
+	}
+	w.Ledger = analytics.NewLedger(0)
+
+	magnetic, external := 0.0, 0.0
+	for i := 0; i < 60; i++ {
+		w.Step(dt)
+		last, _ := w.Ledger.Last()
+		magnetic += last.MagneticWork
+		external += last.ExternalWork
+	}
+
+	if external != 0 {
+		t.Errorf("external work = %v, want 0 without electric fields", external)
+	}
+	// The Boris rotation keeps the speed, leaving only rounding error
+	if math.Abs(magnetic) > 1e-9*p.KineticEnergy() {
+		t.Errorf("magnetic work = %v, want about zero against the kinetic energy %v", magnetic, p.KineticEnergy())
+	}
+}
+
+func TestLedgerChargesDriftingApart(t *testing.T) {
+	// The world doesn't push charges apart, so their mutual energy isn't part of the account
+	a := particle.NewCoulombParticle(380, 300, -40, 0, 0, 0, 1, 5, particle.Color{}, 1, true)
+	b := particle.NewCoulombParticle(420, 300, 40, 0, 0, 0, 1, 5, particle.Color{}, 1, true)
+	w := New([]*particle.Particle{a, b}, 800, 100000)
+	w.Ledger = analytics.NewLedger(0)
+	initial := w.Energy().Total()
+
+	for i := 0; i < 120; i++ {
+		w.Step(dt)
+	}
+
+	last, _ := w.Ledger.Last()
+	if math.Abs(last.Drift) > 1e-2*initial {
+		t.Errorf("Drift = %v, want about zero against %v", last.Drift, initial)
+	}
+}
+
+func TestLedgerPairForces(t *testing.T) {
+	// Two charges pushed apart turn their mutual energy into kinetic energy
+	newWorld := func(pairForces bool) *World {
+		a := particle.NewCoulombParticle(380, 300, 0, 0, 0, 0, 1, 5, particle.Color{}, 1e-3, true)
+		b := particle.NewCoulombParticle(420, 300, 0, 0, 0, 0, 1, 5, particle.Color{}, 1e-3, true)
+		w := New([]*particle.Particle{a, b}, 800, 100000)
+		w.PairForces = pairForces
+		w.Ledger = analytics.NewLedger(0)
+		return w
+	}
+	w, free := newWorld(true), newWorld(false)
+	initial := w.Energy()
+	if initial.Coulomb <= 0 {
+		t.Fatalf("Coulomb = %v, want the energy of two like charges", initial.Coulomb)
+	}
+	if free.Energy().Coulomb != 0 {
+		t.Errorf("Coulomb = %v without pair forces, want 0", free.Energy().Coulomb)
+	}
+
+	for i := 0; i < 120; i++ {
+		w.Step(dt)
+		free.Step(dt)
+	}
+
+	a, b := w.Particles[0], w.Particles[1]
+	if a.Vx >= 0 || b.Vx <= 0 {
+		t.Errorf("velocities = %v, %v, want the charges moving apart", a.Vx, b.Vx)
+	}
+	last, _ := w.Ledger.Last()
+	if last.Energy.Coulomb >= initial.Coulomb {
+		t.Errorf("Coulomb = %v, want less than the initial %v", last.Energy.Coulomb, initial.Coulomb)
+	}
+	// Both worlds share the integration error of falling in uniform gravity
+	freeLast, _ := free.Ledger.Last()
+	if drift := last.Drift - freeLast.Drift; math.Abs(drift) > 1e-2*initial.Coulomb {
+		t.Errorf("Drift from the pair forces = %v, want about zero against %v", drift, initial.Coulomb)
+	}
+}